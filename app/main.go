@@ -9,6 +9,7 @@ import (
 
 	"github.com/fchastanet/shell-command-bookmarker/app/application"
 	"github.com/fchastanet/shell-command-bookmarker/internal/args"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/conflicts"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services"
 
 	// Import for side effects
@@ -20,6 +21,7 @@ var sqliteSchema string
 
 func main() {
 	appService := services.NewAppService()
+	appService.SyncConflictResolver = conflicts.NewResolver()
 	if err := mainImpl(appService); err != nil {
 		slog.Error("critical error", "error", err)
 		fmt.Printf("Error: %v\n", err)
@@ -41,6 +43,10 @@ func mainImpl(appService services.AppServiceInterface) error {
 		return nil
 	}
 
+	if handled, err := appService.HandleCliCommand(&cli, sqliteSchema); handled {
+		return err
+	}
+
 	if err := appService.Main(&cli, sqliteSchema); err != nil {
 		return err
 	}
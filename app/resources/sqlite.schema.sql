@@ -20,8 +20,17 @@ CREATE TABLE command (
     script TEXT NOT NULL,
     lint_issues TEXT,
     lint_status TEXT NOT NULL CHECK(lint_status IN ('NOT_AVAILABLE', 'OK', 'WARNING', 'ERROR', 'SHELLCHECK_FAILED')),
+    lint_rules TEXT NOT NULL DEFAULT '',
     elapsed INTEGER,
+    shell TEXT NOT NULL DEFAULT 'bash' CHECK(shell IN ('bash', 'zsh', 'sh', 'dash', 'ksh')),
+    placeholder_sources TEXT NOT NULL DEFAULT '',
+    -- name of the alias or function generated in the shell library, empty if none
+    short_name TEXT NOT NULL DEFAULT '',
     status TEXT NOT NULL CHECK(status IN ('IMPORTED', 'SAVED', 'DELETED', 'OBSOLETE')),
+    -- globally unique id, shared by the obsolete copies of the command
+    uuid TEXT NOT NULL DEFAULT '',
+    -- sha256 of the content of the command (title, description, script, shell, placeholder sources, short name)
+    content_hash TEXT NOT NULL DEFAULT '',
     folder_id INTEGER,
     FOREIGN KEY (folder_id) REFERENCES folder(id) ON DELETE CASCADE
 );
@@ -34,14 +43,49 @@ CREATE TABLE command_has_tag (
     FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE
 );
 
+-- Lint results indexed by script hash, shellcheck version and options
+CREATE TABLE lint_cache (
+    cache_key TEXT PRIMARY KEY,
+    lint_issues TEXT NOT NULL,
+    lint_status TEXT NOT NULL,
+    creation_datetime TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- Values recently used to fill the placeholders of the commands
+CREATE TABLE placeholder_value (
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    last_used_datetime TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (name, value)
+);
+
+-- Command runs with their truncated output, output and environment are JSON encoded
+CREATE TABLE execution (
+    id INTEGER PRIMARY KEY,
+    command_id INTEGER NOT NULL,
+    shell TEXT NOT NULL,
+    script TEXT NOT NULL,
+    cwd TEXT NOT NULL DEFAULT '',
+    environment TEXT NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL,
+    exit_code INTEGER NOT NULL DEFAULT -1,
+    error TEXT NOT NULL DEFAULT '',
+    output TEXT NOT NULL DEFAULT '[]',
+    dropped_lines INTEGER NOT NULL DEFAULT 0,
+    start_datetime TEXT NOT NULL,
+    end_datetime TEXT
+);
+
 -- Indexes
 CREATE INDEX idx_folder_parent_id ON folder(parent_id);
 CREATE INDEX idx_command_folder ON command(folder_id);
 CREATE INDEX idx_command_status ON command(status);
 CREATE INDEX idx_command_script ON command(script);
+CREATE INDEX idx_command_uuid ON command(uuid);
 CREATE INDEX idx_command_lint_status ON command(lint_status);
 CREATE INDEX idx_command_creation ON command(creation_datetime);
 CREATE INDEX idx_command_modification ON command(modification_datetime);
+CREATE INDEX idx_execution_command ON execution(command_id);
 
 -- FTS5 virtual table for full-text search
 CREATE VIRTUAL TABLE command_fts USING fts5(
@@ -4,21 +4,196 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/alecthomas/kong"
 )
 
 const maxScreenSize = 80
 
+// Names of the commands that can be selected on the command line
+const (
+	CommandTui          = "tui"
+	CommandRelint       = "relint"
+	CommandExportScript = "export-script"
+	CommandExport       = "export"
+	CommandImport       = "import"
+	CommandLibrary      = "library"
+	CommandExtract      = "extract"
+	CommandImportRc     = "import-rc"
+	CommandSync         = "sync"
+	CommandList         = "list"
+	CommandSearch       = "search"
+	CommandShow         = "show"
+	CommandAdd          = "add"
+	CommandEdit         = "edit"
+	CommandDelete       = "delete"
+	CommandRestore      = "restore"
+	CommandTag          = "tag"
+)
+
 type Cli struct {
-	DBPath       FilePath    `arg:""    name:"db-path"     optional:"" type:"path" help:"Path to the SQLite database file"`                  //nolint:tagalign //avoid reformat annotations
-	Version      VersionFlag `short:"v" name:"version"                             help:"Print version information and quit"`                //nolint:tagalign //avoid reformat annotations
-	OutputFile   string      `short:"o" name:"output-file" optional:""             help:"File to write selected command to"`                 //nolint:tagalign //avoid reformat annotations
-	MaxTasks     int         `short:"t" name:"max-tasks"   default:"1"             help:"Maximum number of tasks to run concurrently"`       //nolint:tagalign //avoid reformat annotations
-	Debug        bool        `short:"d"                                            help:"Set log in debug level"`                            //nolint:tagalign //avoid reformat annotations
-	GenerateZsh  bool        `          name:"zsh"         optional:""             help:"Generate Zsh integration script to stdout"`         //nolint:tagalign //avoid reformat annotations
-	GenerateBash bool        `          name:"bash"        optional:""             help:"Generate Bash integration script to stdout"`        //nolint:tagalign //avoid reformat annotations
-	AutoDetect   bool        `short:"a" name:"auto"        optional:""             help:"Auto-detect shell and generate integration script"` //nolint:tagalign //avoid reformat annotations
+	Tui          TuiCmd          `cmd:""    default:"withargs"                         help:"Launch the interactive interface (default command)"`          //nolint:tagalign //avoid reformat annotations
+	Relint       RelintCmd       `cmd:""                                               help:"Lint again commands, unchanged scripts are not linted twice"` //nolint:tagalign //avoid reformat annotations
+	ExportScript ExportScriptCmd `cmd:""    name:"export-script"                       help:"Export a command as an executable script file"`               //nolint:tagalign //avoid reformat annotations
+	Export       ExportCmd       `cmd:""                                               help:"Export the commands to a JSON or YAML catalog"`               //nolint:tagalign //avoid reformat annotations
+	Import       ImportCmd       `cmd:""                                               help:"Import the commands of a JSON or YAML catalog"`               //nolint:tagalign //avoid reformat annotations
+	Library      LibraryCmd      `cmd:""                                               help:"Print or write the shell library of the bookmarks"`           //nolint:tagalign //avoid reformat annotations
+	Extract      ExtractCmd      `cmd:""                                               help:"Import the commands of markdown files and shell scripts"`     //nolint:tagalign //avoid reformat annotations
+	ImportRc     ImportRcCmd     `cmd:""    name:"import-rc"                           help:"Save the aliases and the functions of shell rc files"`        //nolint:tagalign //avoid reformat annotations
+	Sync         SyncCmd         `cmd:""                                               help:"Sync the bookmarks with a git repository"`                    //nolint:tagalign //avoid reformat annotations
+	List         ListCmd         `cmd:""                                               help:"Print the commands of a category"`                            //nolint:tagalign //avoid reformat annotations
+	Search       SearchCmd       `cmd:""                                               help:"Print the commands matching a query, best matches first"`     //nolint:tagalign //avoid reformat annotations
+	Show         ShowCmd         `cmd:""                                               help:"Print all the fields of a command"`                           //nolint:tagalign //avoid reformat annotations
+	Add          AddCmd          `cmd:""                                               help:"Save a new command"`                                          //nolint:tagalign //avoid reformat annotations
+	Edit         EditCmd         `cmd:""                                               help:"Modify the fields of a command"`                              //nolint:tagalign //avoid reformat annotations
+	Delete       DeleteCmd       `cmd:""                                               help:"Mark commands as deleted"`                                    //nolint:tagalign //avoid reformat annotations
+	Restore      RestoreCmd      `cmd:""                                               help:"Restore deleted commands"`                                    //nolint:tagalign //avoid reformat annotations
+	Tag          TagCmd          `cmd:""                                               help:"Print, add or remove the tags of a command"`                  //nolint:tagalign //avoid reformat annotations
+	DBPath       FilePath        `          name:"db-path"     optional:"" type:"path" help:"Path to the SQLite database file"`                            //nolint:tagalign //avoid reformat annotations
+	ConfigPath   string          `          name:"config"      optional:"" type:"path" help:"Path to the YAML configuration file"`                         //nolint:tagalign //avoid reformat annotations
+	Version      VersionFlag     `short:"v" name:"version"                             help:"Print version information and quit"`                          //nolint:tagalign //avoid reformat annotations
+	OutputFile   string          `short:"o" name:"output-file" optional:""             help:"File to write selected command to"`                           //nolint:tagalign //avoid reformat annotations
+	MaxTasks     int             `short:"t" name:"max-tasks"   default:"1"             help:"Maximum number of tasks to run concurrently"`                 //nolint:tagalign //avoid reformat annotations
+	Debug        bool            `short:"d"                                            help:"Set log in debug level"`                                      //nolint:tagalign //avoid reformat annotations
+	GenerateZsh  bool            `          name:"zsh"         optional:""             help:"Generate Zsh integration script to stdout"`                   //nolint:tagalign //avoid reformat annotations
+	GenerateBash bool            `          name:"bash"        optional:""             help:"Generate Bash integration script to stdout"`                  //nolint:tagalign //avoid reformat annotations
+	AutoDetect   bool            `short:"a" name:"auto"        optional:""             help:"Auto-detect shell and generate integration script"`           //nolint:tagalign //avoid reformat annotations
+	// Command is the name of the selected command (see Command* constants)
+	Command string `kong:"-"`
+}
+
+// TuiCmd launches the interactive interface, the database path can be
+// provided as argument to keep compatibility with previous versions
+type TuiCmd struct {
+	DBPath FilePath `arg:"" name:"db-path" optional:"" type:"path"                            help:"Path to the SQLite database file"`                                     //nolint:tagalign //avoid reformat annotations
+	Format string   `       name:"format"              enum:"plain,json,tsv" default:"plain"  help:"Format of the command written to the output file: plain, json or tsv"` //nolint:tagalign //avoid reformat annotations
+}
+
+// RelintCmd lints again the commands with the given ids, or all the commands
+// of a category if no id is provided
+type RelintCmd struct {
+	IDs      []int  `arg:""    name:"id"       optional:""                                                      help:"IDs of the commands to lint again"`                           //nolint:tagalign //avoid reformat annotations
+	Category string `short:"c" name:"category" enum:"available,saved,new,deleted,all" default:"all"             help:"Category of the commands to lint again if no id is provided"` //nolint:tagalign //avoid reformat annotations
+}
+
+// ExportScriptCmd writes the command with the given id as an executable
+// script, to stdout if neither a path nor --bin is provided
+type ExportScriptCmd struct {
+	ID    int    `arg:""    name:"id"                help:"ID of the command to export"`                                 //nolint:tagalign //avoid reformat annotations
+	Path  string `arg:""    name:"path"  optional:"" help:"Path of the script file to write"`                            //nolint:tagalign //avoid reformat annotations
+	Bin   bool   `          name:"bin"               help:"Write the script in the bin directory of the configuration"`  //nolint:tagalign //avoid reformat annotations
+	Force bool   `short:"f" name:"force"             help:"Overwrite the script file without confirmation if it exists"` //nolint:tagalign //avoid reformat annotations
+}
+
+// ExportCmd writes the commands of a category to a catalog file or to a
+// markdown cheat sheet, or to stdout if no path is provided
+type ExportCmd struct {
+	Path     string   `arg:""    name:"path"     optional:""                                              help:"Path of the catalog file to write, stdout if not provided"`                                  //nolint:tagalign //avoid reformat annotations
+	Format   string   `short:"f" name:"format"   enum:",json,yaml,markdown,navi,pet"     default:""       help:"Format of the catalog (json, yaml, markdown, navi or pet), deduced from the file extension"` //nolint:tagalign //avoid reformat annotations
+	Category string   `short:"c" name:"category" enum:",available,saved,new,deleted,all" default:""       help:"Category of the commands to export, all by default, available for the markdown cheat sheet"` //nolint:tagalign //avoid reformat annotations
+	Folder   string   `          name:"folder"                                                            help:"Export only the commands of the folder and of its sub folders"`                              //nolint:tagalign //avoid reformat annotations
+	Tags     []string `          name:"tag"                                                               help:"Export only the commands having one of the tags"`                                            //nolint:tagalign //avoid reformat annotations
+	GroupBy  string   `short:"g" name:"group-by" enum:"folder,tag"                       default:"folder" help:"Grouping of the commands of the markdown export: folder or tag"`                             //nolint:tagalign //avoid reformat annotations
+	Template string   `          name:"template" type:"path"                                              help:"Go template of the markdown export, replaces the configured template"`                       //nolint:tagalign //avoid reformat annotations
+}
+
+// ImportCmd stores the commands of a catalog file, the commands having the
+// same script as an existing command are merged using the strategy
+type ImportCmd struct {
+	Path     string `arg:""    name:"path"     type:"existingfile"                            help:"Path of the catalog file to read"`                                                 //nolint:tagalign //avoid reformat annotations
+	Format   string `short:"f" name:"format"   enum:",json,yaml,navi,pet"      default:""     help:"Format of the catalog (json, yaml, navi or pet), deduced from the file extension"` //nolint:tagalign //avoid reformat annotations
+	Strategy string `short:"s" name:"strategy" enum:"skip,overwrite,keep-both" default:"skip" help:"Merge of the commands already existing: skip, overwrite or keep-both"`             //nolint:tagalign //avoid reformat annotations
+}
+
+// LibraryCmd prints the shell library of the commands having a short name,
+// or writes the library files of the configuration if no shell is provided
+type LibraryCmd struct {
+	Shell string `arg:"" name:"shell" optional:"" enum:",bash,zsh" default:"" help:"Shell of the library to print (bash or zsh)"` //nolint:tagalign //avoid reformat annotations
+}
+
+// ExtractCmd imports the shell code blocks of markdown files and the
+// statements of shell scripts, the directories are scanned recursively
+type ExtractCmd struct {
+	Paths []string `arg:"" name:"path" type:"existingpath" help:"Markdown files, shell scripts or directories to scan"` //nolint:tagalign //avoid reformat annotations
+}
+
+// ImportRcCmd saves the aliases and the functions defined by shell rc files
+type ImportRcCmd struct {
+	Paths []string `arg:"" name:"path" type:"existingfile" help:"Rc files (eg: ~/.bashrc) or files written by alias -p"` //nolint:tagalign //avoid reformat annotations
+}
+
+// SyncCmd commits the saved and deleted commands to the sync repository,
+// merges the commands of the remote and pushes the result, the flags
+// override the sync section of the configuration
+type SyncCmd struct {
+	Dir      string `          name:"dir"      type:"path"                                     help:"Git repository the commands are synced with"`                  //nolint:tagalign //avoid reformat annotations
+	Remote   string `          name:"remote"                                                   help:"Url of the git remote to pull from and push to"`               //nolint:tagalign //avoid reformat annotations
+	Branch   string `          name:"branch"                                                   help:"Branch of the remote"`                                         //nolint:tagalign //avoid reformat annotations
+	Strategy string `short:"s" name:"strategy" enum:"ask,local,remote,keep-both" default:"ask" help:"Resolution of the conflicts: ask, local, remote or keep-both"` //nolint:tagalign //avoid reformat annotations
+}
+
+// ListCmd prints the commands of a category, one command by line
+type ListCmd struct {
+	Category string   `short:"c" name:"category" enum:"available,saved,new,deleted,all" default:"available" help:"Category of the commands to list"`                            //nolint:tagalign //avoid reformat annotations
+	Folder   string   `          name:"folder"                                                              help:"List only the commands of the folder and of its sub folders"` //nolint:tagalign //avoid reformat annotations
+	Tags     []string `          name:"tag"                                                                 help:"List only the commands having one of the tags"`               //nolint:tagalign //avoid reformat annotations
+	Format   string   `short:"f" name:"format"   enum:"plain,json,tsv"                  default:"plain"     help:"Output format: plain, json or tsv"`                           //nolint:tagalign //avoid reformat annotations
+}
+
+// SearchCmd prints the commands of a category matching the query, using the
+// matching of the filter of the interactive interface
+type SearchCmd struct {
+	Query    []string `arg:""    name:"query"                                                               help:"Text searched in the title, the description and the script"` //nolint:tagalign //avoid reformat annotations
+	Category string   `short:"c" name:"category" enum:"available,saved,new,deleted,all" default:"available" help:"Category of the commands to search"`                         //nolint:tagalign //avoid reformat annotations
+	Format   string   `short:"f" name:"format"   enum:"plain,json,tsv"                  default:"plain"     help:"Output format: plain, json or tsv"`                          //nolint:tagalign //avoid reformat annotations
+}
+
+// ShowCmd prints all the fields of the command with the given id
+type ShowCmd struct {
+	ID     int    `arg:""    name:"id"                                           help:"ID of the command to print"`        //nolint:tagalign //avoid reformat annotations
+	Format string `short:"f" name:"format" enum:"plain,json,tsv" default:"plain" help:"Output format: plain, json or tsv"` //nolint:tagalign //avoid reformat annotations
+}
+
+// AddCmd saves a new command, the script is read from stdin if it is "-"
+type AddCmd struct {
+	Script      string   `arg:"" name:"script"                                              help:"Script of the command, - to read it from stdin"`     //nolint:tagalign //avoid reformat annotations
+	Title       string   `       name:"title"                                               help:"Title of the command"`                               //nolint:tagalign //avoid reformat annotations
+	Description string   `       name:"description"                                         help:"Description of the command"`                         //nolint:tagalign //avoid reformat annotations
+	Shell       string   `       name:"shell"       enum:",bash,zsh,sh,dash,ksh" default:"" help:"Shell of the script, deduced from its shebang"`      //nolint:tagalign //avoid reformat annotations
+	Folder      string   `       name:"folder"                                              help:"Folder of the command (eg: ops/db)"`                 //nolint:tagalign //avoid reformat annotations
+	Tags        []string `       name:"tag"                                                 help:"Tags of the command"`                                //nolint:tagalign //avoid reformat annotations
+	ShortName   string   `       name:"short-name"                                          help:"Name of the alias or function of the shell library"` //nolint:tagalign //avoid reformat annotations
+}
+
+// EditCmd modifies the fields of the command with the given id, the fields
+// that are not provided are kept
+type EditCmd struct {
+	ID          int     `arg:"" name:"id"          help:"ID of the command to modify"`                                   //nolint:tagalign //avoid reformat annotations
+	Script      *string `       name:"script"      help:"New script of the command, - to read it from stdin"`            //nolint:tagalign //avoid reformat annotations
+	Title       *string `       name:"title"       help:"New title of the command"`                                      //nolint:tagalign //avoid reformat annotations
+	Description *string `       name:"description" help:"New description of the command"`                                //nolint:tagalign //avoid reformat annotations
+	Shell       *string `       name:"shell"       help:"New shell of the script (bash, zsh, sh, dash or ksh)"`          //nolint:tagalign //avoid reformat annotations
+	Folder      *string `       name:"folder"      help:"New folder of the command, empty to remove it from its folder"` //nolint:tagalign //avoid reformat annotations
+	ShortName   *string `       name:"short-name"  help:"New name of the alias or function, empty to remove it"`         //nolint:tagalign //avoid reformat annotations
+}
+
+// DeleteCmd marks the commands with the given ids as deleted
+type DeleteCmd struct {
+	IDs []int `arg:"" name:"id" help:"IDs of the commands to delete"` //nolint:tagalign //avoid reformat annotations
+}
+
+// RestoreCmd saves again the deleted commands with the given ids
+type RestoreCmd struct {
+	IDs []int `arg:"" name:"id" help:"IDs of the deleted commands to restore"` //nolint:tagalign //avoid reformat annotations
+}
+
+// TagCmd adds the tags to the command with the given id, or removes them, and
+// prints the tags of the command
+type TagCmd struct {
+	ID     int      `arg:""    name:"id"                 help:"ID of the command"`                         //nolint:tagalign //avoid reformat annotations
+	Tags   []string `arg:""    name:"tag"    optional:"" help:"Tags to add, the tags are printed if none"` //nolint:tagalign //avoid reformat annotations
+	Remove bool     `short:"r" name:"remove"             help:"Remove the tags instead of adding them"`    //nolint:tagalign //avoid reformat annotations
 }
 
 type FilePath string
@@ -61,7 +236,7 @@ func (VersionFlag) BeforeApply(
 
 func ParseArgs(cli *Cli) (err error) {
 	// just need the yaml file, from which all the dependencies will be deduced
-	kong.Parse(cli,
+	ctx := kong.Parse(cli,
 		kong.Name("shell-command-bookmarker"),
 		kong.Description("A command line tool to bookmark shell commands"),
 		kong.UsageOnError(),
@@ -80,6 +255,12 @@ func ParseArgs(cli *Cli) (err error) {
 		},
 	)
 
+	// only keep the command name, eg: "relint <id>" => "relint"
+	cli.Command = strings.Fields(ctx.Command())[0]
+	if cli.DBPath == "" {
+		cli.DBPath = cli.Tui.DBPath
+	}
+
 	if cli.DBPath == "" {
 		cli.DBPath = "db/shell-command-bookmarker.db"
 		if os.Getenv("SHELL_CMD_BOOK_DB") != "" {
@@ -2,6 +2,7 @@ package args
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,8 +10,33 @@ import (
 
 func defaultCli() *Cli {
 	return &Cli{
+		Tui:          TuiCmd{DBPath: "", Format: "plain"},
+		Relint:       RelintCmd{IDs: nil, Category: "all"},
+		ExportScript: ExportScriptCmd{ID: 0, Path: "", Bin: false, Force: false},
+		Export: ExportCmd{
+			Path: "", Format: "", Category: "", Folder: "", Tags: nil, GroupBy: "folder", Template: "",
+		},
+		Import:   ImportCmd{Path: "", Format: "", Strategy: "skip"},
+		Library:  LibraryCmd{Shell: ""},
+		Extract:  ExtractCmd{Paths: nil},
+		ImportRc: ImportRcCmd{Paths: nil},
+		Sync:     SyncCmd{Dir: "", Remote: "", Branch: "", Strategy: "ask"},
+		List:     ListCmd{Category: "available", Folder: "", Tags: nil, Format: "plain"},
+		Search:   SearchCmd{Query: nil, Category: "available", Format: "plain"},
+		Show:     ShowCmd{ID: 0, Format: "plain"},
+		Add: AddCmd{
+			Script: "", Title: "", Description: "", Shell: "", Folder: "", Tags: nil, ShortName: "",
+		},
+		Edit: EditCmd{
+			ID: 0, Script: nil, Title: nil, Description: nil, Shell: nil, Folder: nil, ShortName: nil,
+		},
+		Delete:       DeleteCmd{IDs: nil},
+		Restore:      RestoreCmd{IDs: nil},
+		Tag:          TagCmd{ID: 0, Tags: nil, Remove: false},
+		Command:      CommandTui,
 		MaxTasks:     1,
 		DBPath:       "db/shell-command-bookmarker.db",
+		ConfigPath:   "",
 		Version:      "",
 		Debug:        false,
 		OutputFile:   "",
@@ -54,6 +80,201 @@ func TestArgs(t *testing.T) {
 		assert.Equal(t, expectedCli, cli)
 	})
 
+	t.Run("db path argument", func(t *testing.T) {
+		expectedCli := defaultCli()
+		dbPath := filepath.Join(t.TempDir(), "test.db")
+		expectedCli.DBPath = FilePath(dbPath)
+		expectedCli.Tui.DBPath = FilePath(dbPath)
+		os.Args = []string{"cmd", dbPath}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("relint command", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandRelint
+		expectedCli.Relint.IDs = []int{1, 2}
+		os.Args = []string{"cmd", "relint", "1", "2"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("relint category", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandRelint
+		expectedCli.Relint.Category = "saved"
+		os.Args = []string{"cmd", "relint", "--category", "saved"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("export-script", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandExportScript
+		expectedCli.ExportScript.ID = 3
+		expectedCli.ExportScript.Bin = true
+		expectedCli.ExportScript.Force = true
+		os.Args = []string{"cmd", "export-script", "3", "--bin", "-f"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("markdown export", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandExport
+		expectedCli.Export.Path = "cheatsheet.md"
+		expectedCli.Export.Tags = []string{"db", "k8s"}
+		expectedCli.Export.GroupBy = "tag"
+		os.Args = []string{"cmd", "export", "cheatsheet.md", "--tag", "db", "--tag", "k8s", "-g", "tag"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		expectedCli := defaultCli()
+		catalogPath := filepath.Join(t.TempDir(), "catalog.json")
+		assert.NoError(t, os.WriteFile(catalogPath, []byte("{}"), 0o600))
+		expectedCli.Command = CommandImport
+		expectedCli.Import.Path = catalogPath
+		expectedCli.Import.Strategy = "keep-both"
+		os.Args = []string{"cmd", "import", catalogPath, "--strategy", "keep-both"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("library", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandLibrary
+		expectedCli.Library.Shell = "zsh"
+		os.Args = []string{"cmd", "library", "zsh"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("extract", func(t *testing.T) {
+		expectedCli := defaultCli()
+		dir := t.TempDir()
+		runbookPath := filepath.Join(dir, "runbook.md")
+		assert.NoError(t, os.WriteFile(runbookPath, []byte("# Runbook"), 0o600))
+		expectedCli.Command = CommandExtract
+		expectedCli.Extract.Paths = []string{runbookPath, dir}
+		os.Args = []string{"cmd", "extract", runbookPath, dir}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("import-rc", func(t *testing.T) {
+		expectedCli := defaultCli()
+		rcPath := filepath.Join(t.TempDir(), ".bashrc")
+		assert.NoError(t, os.WriteFile(rcPath, []byte("alias ll='ls -l'"), 0o600))
+		expectedCli.Command = CommandImportRc
+		expectedCli.ImportRc.Paths = []string{rcPath}
+		os.Args = []string{"cmd", "import-rc", rcPath}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandSync
+		expectedCli.Sync = SyncCmd{Dir: "/tmp/bookmarks", Remote: "/srv/bookmarks.git", Branch: "", Strategy: "remote"}
+		os.Args = []string{"cmd", "sync", "--dir", "/tmp/bookmarks", "--remote", "/srv/bookmarks.git", "-s", "remote"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandList
+		expectedCli.List = ListCmd{Category: "saved", Folder: "ops", Tags: []string{"db"}, Format: "json"}
+		os.Args = []string{"cmd", "list", "-c", "saved", "--folder", "ops", "--tag", "db", "-f", "json"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandSearch
+		expectedCli.Search.Query = []string{"docker", "ps"}
+		os.Args = []string{"cmd", "search", "docker", "ps"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandAdd
+		expectedCli.Add = AddCmd{
+			Script: "-", Title: "Backup", Description: "", Shell: "zsh", Folder: "", Tags: []string{"db", "ops"},
+			ShortName: "",
+		}
+		os.Args = []string{"cmd", "add", "-", "--title", "Backup", "--shell", "zsh", "--tag", "db", "--tag", "ops"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		expectedCli := defaultCli()
+		title, folder := "Backup", ""
+		expectedCli.Command = CommandEdit
+		expectedCli.Edit.ID = 4
+		expectedCli.Edit.Title = &title
+		expectedCli.Edit.Folder = &folder
+		os.Args = []string{"cmd", "edit", "4", "--title", "Backup", "--folder", ""}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli, "only the provided fields are set")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandDelete
+		expectedCli.Delete.IDs = []int{1, 3}
+		os.Args = []string{"cmd", "delete", "1", "3"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Command = CommandTag
+		expectedCli.Tag = TagCmd{ID: 2, Tags: []string{"db"}, Remove: true}
+		os.Args = []string{"cmd", "tag", "2", "db", "-r"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
 	t.Run("output-file", func(t *testing.T) {
 		expectedCli := defaultCli()
 		expectedCli.OutputFile = "/tmp/output.txt"
@@ -64,6 +285,17 @@ func TestArgs(t *testing.T) {
 		assert.Equal(t, expectedCli, cli)
 	})
 
+	t.Run("output-file format", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.OutputFile = "/tmp/output.json"
+		expectedCli.Tui.Format = "json"
+		os.Args = []string{"cmd", "-o", "/tmp/output.json", "--format", "json"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
 	t.Run("zsh flag", func(t *testing.T) {
 		expectedCli := defaultCli()
 		expectedCli.GenerateZsh = true
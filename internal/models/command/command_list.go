@@ -3,6 +3,9 @@ package command
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -40,9 +43,10 @@ type ListMaker struct {
 const (
 	idColumnPercentWidth         = 6
 	titleColumnPercentWidth      = 19
-	scriptColumnPercentWidth     = 65
+	scriptColumnPercentWidth     = 55
 	statusColumnPercentWidth     = 7
 	lintStatusColumnPercentWidth = 6
+	riskColumnPercentWidth       = 10
 
 	indexColumnStatus = 3
 
@@ -56,6 +60,7 @@ func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildMode
 	scriptColumn := newColumn(table.ColumnKey(structure.FieldScript), "Script", table.GetDefaultTruncationFunc())
 	statusColumn := newColumn(table.ColumnKey(structure.FieldStatus), "Status", table.GetDefaultTruncationFunc())
 	lintStatusColumn := newColumn(table.ColumnKey(structure.FieldLintStatus), "Lint", table.GetDefaultTruncationFunc())
+	riskColumn := newColumn(table.ColumnKey(structure.FieldRisk), "Risk", table.GetDefaultTruncationFunc())
 	filterScoreColumn := newColumn(table.ColumnKey(structure.FieldFilterScore), "Score", table.NoTruncate)
 
 	// set filter
@@ -63,6 +68,7 @@ func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildMode
 	// Initialize the category tabs component
 	categoryAdapter := tabs.NewCategoryAdapter(
 		mm.App.GetHistoryService(),
+		mm.App.Self().TeamCatalogService,
 		mm.Styles.SortStyles,
 		mm.SortKeyMap,
 	)
@@ -89,6 +95,7 @@ func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildMode
 		scriptColumn:            &scriptColumn,
 		statusColumn:            &statusColumn,
 		lintStatusColumn:        &lintStatusColumn,
+		riskColumn:              &riskColumn,
 		filterScoreColumn:       &filterScoreColumn,
 		categoryTabs:            categoryTabs,
 	}
@@ -177,6 +184,7 @@ func (*ListMaker) renderRow(
 		commandsListModel.scriptColumn.Key:      cmd.Script,
 		commandsListModel.statusColumn.Key:      formatStatus(cmd, commandsListModel.styles.EditorStyle),
 		commandsListModel.lintStatusColumn.Key:  formatLintStatus(cmd, commandsListModel.styles.EditorStyle),
+		commandsListModel.riskColumn.Key:        commandsListModel.formatRisk(cmd),
 		commandsListModel.filterScoreColumn.Key: strconv.Itoa(cmd.FilterScore),
 	}
 }
@@ -185,6 +193,10 @@ func formatStatus(
 	cmd *dbmodels.Command,
 	editorStyle *styles.EditorStyle,
 ) string {
+	// the commands of the team catalogs are all saved, their source is more useful
+	if cmd.Source != "" {
+		return editorStyle.SourceBadge.Render(cmd.Source)
+	}
 	switch cmd.Status {
 	case dbmodels.CommandStatusSaved:
 		return editorStyle.StatusOK.Render(string(cmd.Status))
@@ -219,6 +231,21 @@ func formatLintStatus(
 	}
 }
 
+// formatRisk returns the rules of the dangers detected in the command script
+func (m *commandsList) formatRisk(cmd *dbmodels.Command) string {
+	dangers := m.DangerService.ClassifyCommand(cmd)
+	if len(dangers) == 0 {
+		return ""
+	}
+	rules := make([]string, 0, len(dangers))
+	for _, danger := range dangers {
+		if !slices.Contains(rules, danger.Rule) {
+			rules = append(rules, danger.Rule)
+		}
+	}
+	return m.styles.EditorStyle.StatusError.Render(strings.Join(rules, ", "))
+}
+
 type commandsList struct {
 	editorsCache table.EditorsCacheInterface
 	Model        *table.Model[*dbmodels.Command]
@@ -237,6 +264,7 @@ type commandsList struct {
 	scriptColumn      *table.Column
 	statusColumn      *table.Column
 	lintStatusColumn  *table.Column
+	riskColumn        *table.Column
 	filterScoreColumn *table.Column
 
 	height int
@@ -256,6 +284,7 @@ func (m *commandsList) getColumns() []table.Column {
 		*m.scriptColumn,
 		*m.statusColumn,
 		*m.lintStatusColumn,
+		*m.riskColumn,
 	}
 	if m.categoryTabs.GetActiveFilter() != "" {
 		columns = append(columns, *m.filterScoreColumn)
@@ -264,7 +293,7 @@ func (m *commandsList) getColumns() []table.Column {
 }
 
 func (m *commandsList) computeColumnsWidth(width int) {
-	columnsCount := 5
+	columnsCount := 6
 	spaceForAdditionalColumn := 0
 	if m.categoryTabs.GetActiveFilter() != "" {
 		columnsCount++
@@ -280,6 +309,7 @@ func (m *commandsList) computeColumnsWidth(width int) {
 	m.scriptColumn.Width = (scriptColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	m.statusColumn.Width = (statusColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	m.lintStatusColumn.Width = (lintStatusColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
+	m.riskColumn.Width = (riskColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	if m.categoryTabs.GetActiveFilter() != "" {
 		m.filterScoreColumn.Width = columnsCount
 	} else {
@@ -310,6 +340,9 @@ func (m *commandsList) Update(msg tea.Msg) tea.Cmd {
 		return m.reloadCommandsAfterSort(msg.State, msg.InfoMsg)
 	case table.ReloadMsg[*dbmodels.Command]:
 		return m.loadCommandsForCurrentCategory(msg.RowID)
+	case structure.TasksCompletedMsg:
+		// the background lint has updated the lint status of the commands
+		return m.loadCommandsForCurrentCategory(0)
 	case tea.WindowSizeMsg:
 		return m.handleWindowSize(msg)
 	case tea.BlurMsg:
@@ -326,6 +359,8 @@ func (m *commandsList) Update(msg tea.Msg) tea.Cmd {
 		cmds = append(cmds, cmd)
 	case table.RowDeleteActionMsg[*dbmodels.Command]:
 		return m.handleDeleteRows()
+	case commandComposedMsg:
+		return m.handleCommandComposed(msg)
 	case pkgTabs.CategoryTabChangedMsg[
 		*dbmodels.Command,
 		dbmodels.CommandStatus,
@@ -380,11 +415,23 @@ func (m *commandsList) loadCommandsForCurrentCategory(selectRowID resource.ID) t
 			"category", m.categoryTabs.GetActiveCategory(),
 			"statuses", statuses)
 
-		// Load commands for those statuses
-		rows, err := m.HistoryService.GetCommandsByStatus(statuses...)
-		if err != nil {
-			slog.Error("Error getting commands for category", "error", err)
-			return nil
+		// Load commands for those statuses, or the commands of the team catalogs
+		var rows []*dbmodels.Command
+		var teamErr error
+		if m.categoryTabs.GetActiveCategory() == tabs.TeamCommands {
+			rows, teamErr = m.TeamCatalogService.GetCommands()
+		} else {
+			var err error
+			rows, err = m.HistoryService.GetCommandsByStatus(statuses...)
+			if err != nil {
+				slog.Error("Error getting commands for category", "error", err)
+				return nil
+			}
+		}
+
+		// in shell selection mode, only keep the commands the current shell can run
+		if shell, ok := m.GetCurrentShellDialect(); ok && m.IsShellSelectionMode() {
+			rows = filterCommandsByShell(rows, shell)
 		}
 
 		// filter commands using filter
@@ -416,6 +463,10 @@ func (m *commandsList) loadCommandsForCurrentCategory(selectRowID resource.ID) t
 		if m.categoryTabs.GetActiveFilter() != "" {
 			info += fmt.Sprintf(" (filter: %s)", m.categoryTabs.GetActiveFilter())
 		}
+		// the commands of the readable team catalogs are displayed anyway
+		if teamErr != nil {
+			info += ", " + teamErr.Error()
+		}
 		return table.BulkInsertMsg[*dbmodels.Command]{
 			Items:       rows,
 			InfoMsg:     info,
@@ -473,6 +524,9 @@ func (m *commandsList) handleDeleteRows() tea.Cmd {
 		}
 	}
 	for _, row := range rows {
+		if row.Source != "" {
+			return tui.ReportError(&ErrTeamCommandReadOnly{})
+		}
 		if row.Status == dbmodels.CommandStatusDeleted {
 			return func() tea.Msg {
 				return tui.ErrorMsg(&ErrSelectionMismatch{})
@@ -576,12 +630,30 @@ func (m *commandsList) handleKeyMsg(msg tea.KeyMsg) (cmd tea.Cmd, forward bool)
 	case tui.CheckKey(msg, customK.RestoreCommand):
 		forward = false
 		cmds = append(cmds, m.handleRestoreCommand())
+	case tui.CheckKey(msg, customK.RelintCommand):
+		forward = false
+		cmds = append(cmds, m.handleRelintCommands())
+	case tui.CheckKey(msg, customK.RelintCategory):
+		forward = false
+		cmds = append(cmds, m.handleRelintCategory())
+	case tui.CheckKey(msg, customK.RelintAll):
+		forward = false
+		cmds = append(cmds, m.handleRelintAll())
 	case tui.CheckKey(msg, customK.CopyToClipboard):
 		forward = false
 		cmds = append(cmds, m.handleCopyToClipboard())
 	case tui.CheckKey(msg, customK.SelectForShell):
 		forward = false
 		cmds = append(cmds, m.handleSelectForShell())
+	case tui.CheckKey(msg, customK.RunCommand):
+		forward = false
+		cmds = append(cmds, m.handleRunCommand())
+	case tui.CheckKey(msg, customK.ExportScript):
+		forward = false
+		cmds = append(cmds, m.handleExportScript())
+	case tui.CheckKey(msg, customK.CopyToBookmarks):
+		forward = false
+		cmds = append(cmds, m.handleCopyToBookmarks())
 	}
 	return tea.Batch(cmds...), forward
 }
@@ -617,32 +689,118 @@ func (m *commandsList) handleRestoreCommand() tea.Cmd {
 	}
 }
 
-func (m *commandsList) handleComposeCommand() tea.Cmd {
+func (m *commandsList) handleRelintCommands() tea.Cmd {
+	if !m.LintService.IsLintingAvailable() {
+		return tui.ReportError(services.ErrNoLinterAvailable)
+	}
 	rows := m.Model.SelectedOrCurrent()
-	newCmd, err := m.HistoryService.ComposeCommand(rows)
+	if len(rows) == 0 {
+		return tui.ReportError(&ErrNoCommandsSelected{})
+	}
+	if slices.ContainsFunc(rows, isTeamCommand) {
+		return tui.ReportError(&ErrTeamCommandReadOnly{})
+	}
+	count := m.HistoryService.RelintCommands(rows)
+	m.Model.DeselectAll()
+	return reportRelintSubmitted(count)
+}
+
+func (m *commandsList) handleRelintCategory() tea.Cmd {
+	if !m.LintService.IsLintingAvailable() {
+		return tui.ReportError(services.ErrNoLinterAvailable)
+	}
+	if m.categoryTabs.GetActiveCategory() == tabs.TeamCommands {
+		return tui.ReportError(&ErrTeamCommandReadOnly{})
+	}
+	rows, err := m.HistoryService.GetCommandsByStatus(m.categoryTabs.GetActiveTabCommandTypes()...)
 	if err != nil {
+		return tui.ReportError(err)
+	}
+	return reportRelintSubmitted(m.HistoryService.RelintCommands(rows))
+}
+
+func (m *commandsList) handleRelintAll() tea.Cmd {
+	if !m.LintService.IsLintingAvailable() {
+		return tui.ReportError(services.ErrNoLinterAvailable)
+	}
+	return tui.YesNoPrompt(
+		"Lint again all the commands of the database?",
+		keys.GetFormKeyMap(),
+		func() tea.Cmd {
+			count, err := m.HistoryService.RelintCategory(services.CommandCategoryAll)
+			if err != nil {
+				return tui.ReportError(err)
+			}
+			return reportRelintSubmitted(count)
+		},
+	)
+}
+
+func reportRelintSubmitted(count int) tea.Cmd {
+	return tui.ReportInfo("%d command(s) submitted for lint", count)
+}
+
+// handleComposeCommand opens the composer with the selected commands
+func (m *commandsList) handleComposeCommand() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
 		return func() tea.Msg {
-			return tui.ErrorMsg(&ErrComposeCommand{Err: err})
+			return tui.ErrorMsg(&ErrNoCommandsSelected{})
 		}
 	}
 	m.Model.DeselectAll()
+	return tea.Sequence(
+		tui.CmdHandler(structure.NewNavigationMsg(
+			structure.ComposerKind, structure.WithPosition(structure.TopPane),
+		)),
+		tui.CmdHandler(ComposeMsg{Commands: rows}),
+	)
+}
+
+// handleCommandComposed selects the command saved by the composer
+func (m *commandsList) handleCommandComposed(msg commandComposedMsg) tea.Cmd {
 	infoMsg := tui.InfoMsg(fmt.Sprintf(
-		"New Command #%d created from %d selected commands", newCmd.GetID(), len(rows),
+		"New Command #%d created from %d steps", msg.command.GetID(), msg.stepsCount,
 	))
 	// change the category tab to "Available Commands" immediately
 	// so the user can see the new command right away
 	// The sort state will be automatically loaded from the target category tab
 	m.categoryTabs.ChangeCategoryTab(tabs.AvailableCommands)
-	return tea.Batch(
-		func() tea.Msg {
-			return table.ReloadMsg[*dbmodels.Command]{
-				RowID:   newCmd.ID,
-				InfoMsg: &infoMsg,
-			}
-		},
+	return func() tea.Msg {
+		return table.ReloadMsg[*dbmodels.Command]{
+			RowID:   msg.command.ID,
+			InfoMsg: &infoMsg,
+		}
+	}
+}
+
+// handleCopyToBookmarks stores a copy of the selected team commands in the
+// database, the copies can be edited
+func (m *commandsList) handleCopyToBookmarks() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return tui.ReportError(&ErrNoCommandsSelected{})
+	}
+	for _, row := range rows {
+		if !isTeamCommand(row) {
+			return tui.ReportError(&ErrSelectionMismatch{})
+		}
+	}
+	report, err := m.TeamCatalogService.CopyCommands(rows)
+	if err != nil {
+		return tui.ReportError(&ErrCopyTeamCommand{Err: err})
+	}
+	m.Model.DeselectAll()
+	m.updateCategoryCounts()
+	return tui.ReportInfo(
+		"%d command(s) copied to your bookmarks, %d already in your bookmarks", report.Added, report.Skipped,
 	)
 }
 
+func isTeamCommand(cmd *dbmodels.Command) bool {
+	return cmd.Source != ""
+}
+
 func (m *commandsList) handleCopyToClipboard() tea.Cmd {
 	rows := m.Model.SelectedOrCurrent()
 	if len(rows) == 0 {
@@ -651,21 +809,63 @@ func (m *commandsList) handleCopyToClipboard() tea.Cmd {
 		}
 	}
 
-	commandsString := m.HistoryService.CreateCommandsString(rows)
-	err := clipboard.WriteAll(commandsString)
-	if err != nil {
+	m.Model.DeselectAll()
+	return fillPlaceholders(m.PlaceholderService, m.styles.EditorStyle, rows, func(scripts []string) tea.Cmd {
+		err := clipboard.WriteAll(strings.Join(scripts, "\n"))
+		if err != nil {
+			return tui.ReportError(&ErrClipboardCopyFailed{Err: err})
+		}
+		return tui.ReportInfo("Copied %d command(s) to clipboard", len(scripts))
+	})
+}
+
+func (m *commandsList) handleSelectForShell() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
 		return func() tea.Msg {
-			return tui.ErrorMsg(&ErrClipboardCopyFailed{Err: err})
+			return tui.ErrorMsg(&ErrNoCommandsSelected{})
 		}
 	}
 
-	m.Model.DeselectAll()
-	return func() tea.Msg {
-		return tui.InfoMsg(fmt.Sprintf("Copied %d command(s) to clipboard", len(rows)))
+	// We only want the first command for shell pasting
+	return fillPlaceholders(m.PlaceholderService, m.styles.EditorStyle, rows[:1], func(scripts []string) tea.Cmd {
+		return m.confirmDangers(rows[0], scripts[0], "Paste it anyway?", func() tea.Cmd {
+			return func() tea.Msg {
+				return structure.CommandSelectedForShellMsg{Selected: rows[0], Command: scripts[0]}
+			}
+		})
+	})
+}
+
+func (m *commandsList) handleRunCommand() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return func() tea.Msg {
+			return tui.ErrorMsg(&ErrNoCommandsSelected{})
+		}
 	}
+
+	// only the first command is run
+	command := rows[0]
+	return fillPlaceholders(m.PlaceholderService, m.styles.EditorStyle, rows[:1], func(scripts []string) tea.Cmd {
+		return m.confirmDangers(command, scripts[0], "Run it anyway?", func() tea.Cmd {
+			run, err := m.ExecutionService.Run(command, scripts[0])
+			if err != nil {
+				return tui.ReportError(&ErrRunCommand{Err: err})
+			}
+			return tui.CmdHandler(structure.NavigationMsg{
+				Page:         structure.Page{Kind: structure.TaskKind, ID: run.ID},
+				Position:     structure.BottomPane,
+				DisableFocus: false,
+			})
+		})
+	})
 }
 
-func (m *commandsList) handleSelectForShell() tea.Cmd {
+// handleExportScript asks the path of the script file of the current command,
+// by default in the bin directory if configured, and confirms the overwrite of
+// an existing file
+func (m *commandsList) handleExportScript() tea.Cmd {
 	rows := m.Model.SelectedOrCurrent()
 	if len(rows) == 0 {
 		return func() tea.Msg {
@@ -673,12 +873,53 @@ func (m *commandsList) handleSelectForShell() tea.Cmd {
 		}
 	}
 
-	// We only want the first command for shell pasting
-	commandString := m.HistoryService.CreateCommandsString(rows[:1])
+	// only the first command is exported
+	command := rows[0]
+	path, binErr := m.ScriptExportService.GetBinPath(command)
+	if binErr != nil {
+		path = m.ScriptExportService.GetScriptFileName(command)
+		if cwd, err := os.Getwd(); err == nil {
+			path = filepath.Join(cwd, path)
+		}
+	}
+	return promptText("Export to", path, textPromptInputMaxSize, m.styles.EditorStyle, func(path string) tea.Cmd {
+		if path == "" {
+			return nil
+		}
+		export := func() tea.Cmd {
+			if err := m.ScriptExportService.ExportScript(command, path, true); err != nil {
+				return tui.ReportError(err)
+			}
+			return tui.ReportInfo("Command #%d exported to %s", command.GetID(), path)
+		}
+		if !m.ScriptExportService.Exists(path) {
+			return export()
+		}
+		return tui.YesNoPrompt(
+			fmt.Sprintf("File %s already exists, overwrite it?", path),
+			keys.GetFormKeyMap(),
+			export,
+		)
+	})
+}
 
-	return func() tea.Msg {
-		return structure.CommandSelectedForShellMsg{Command: commandString}
+// confirmDangers invokes the action if the script, with its placeholders
+// filled, is not dangerous, otherwise a confirmation naming the risks is asked
+func (m *commandsList) confirmDangers(
+	cmd *dbmodels.Command,
+	script string,
+	question string,
+	action tui.PromptAction,
+) tea.Cmd {
+	dangers := m.DangerService.Classify(script, cmd.Shell)
+	if len(dangers) == 0 {
+		return action()
 	}
+	return tui.YesNoPrompt(
+		fmt.Sprintf("Command #%d is risky:\n%s\n%s", cmd.GetID(), services.DescribeDangers(dangers), question),
+		keys.GetFormKeyMap(),
+		action,
+	)
 }
 
 func (m *commandsList) View() string {
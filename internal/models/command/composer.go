@@ -0,0 +1,364 @@
+package command
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+// composerHeaderHeight is the number of lines displayed above the steps and the preview
+const composerHeaderHeight = 3
+
+// ComposeMsg starts the composition of a script running the given commands
+type ComposeMsg struct {
+	Commands []*dbmodels.Command
+}
+
+func (ComposeMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.ComposerKind, ID: 0}
+}
+
+// composerLintedMsg contains the lint issues of a composed script
+type composerLintedMsg struct {
+	script     string
+	lintStatus dbmodels.LintStatus
+	issues     []services.ShellCheckIssue
+}
+
+func (composerLintedMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.ComposerKind, ID: 0}
+}
+
+// composerValueMsg contains the value typed in the prompt editing the
+// title, or the comment of the step at index stepIndex
+type composerValueMsg struct {
+	value     string
+	stepIndex int
+	isTitle   bool
+}
+
+func (composerValueMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.ComposerKind, ID: 0}
+}
+
+// commandComposedMsg is sent to the commands list when the composed command is saved
+type commandComposedMsg struct {
+	command    *dbmodels.Command
+	stepsCount int
+}
+
+type ComposerMaker struct {
+	App            *services.AppService
+	Styles         *styles.Styles
+	ComposerKeyMap *keys.ComposerKeyMap
+}
+
+// Make creates the page composing a script from several commands, the
+// commands are provided by a ComposeMsg
+func (mm *ComposerMaker) Make(_ resource.ID, width, height int) (structure.ChildModel, error) {
+	preview := viewport.New(width, max(0, height-composerHeaderHeight))
+	preview.KeyMap = viewport.KeyMap{
+		PageDown:     *mm.ComposerKeyMap.NextPage,
+		PageUp:       *mm.ComposerKeyMap.PreviousPage,
+		HalfPageUp:   key.NewBinding(key.WithDisabled()),
+		HalfPageDown: key.NewBinding(key.WithDisabled()),
+		Up:           key.NewBinding(key.WithDisabled()),
+		Down:         key.NewBinding(key.WithDisabled()),
+		Left:         key.NewBinding(key.WithDisabled()),
+		Right:        key.NewBinding(key.WithDisabled()),
+	}
+	return &composer{
+		historyService: mm.App.HistoryService,
+		lintService:    mm.App.LintService,
+		styles:         mm.Styles,
+		keyMap:         mm.ComposerKeyMap,
+		composition:    dbmodels.NewComposition(nil),
+		preview:        &preview,
+		script:         "",
+		lint:           composerLintedMsg{script: "", lintStatus: dbmodels.LintStatusNotAvailable, issues: nil},
+		cursor:         0,
+		width:          width,
+		height:         height,
+	}, nil
+}
+
+// composer displays the steps of the composition and a preview of the
+// composed script with its lint issues
+type composer struct {
+	historyService *services.HistoryService
+	lintService    *services.LintService
+	styles         *styles.Styles
+	keyMap         *keys.ComposerKeyMap
+	composition    *dbmodels.Composition
+	preview        *viewport.Model
+	// script is the composed script displayed in the preview
+	script string
+	// lint is the last lint result, it is outdated if its script is not the displayed one
+	lint   composerLintedMsg
+	cursor int
+	width  int
+	height int
+}
+
+func (*composer) Init() tea.Cmd {
+	return nil
+}
+
+func (*composer) BeforeSwitchPane() tea.Cmd {
+	return nil
+}
+
+func (m *composer) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resizePreview()
+	case ComposeMsg:
+		return m.start(msg.Commands)
+	case composerLintedMsg:
+		m.lint = msg
+		m.refreshPreview()
+	case composerValueMsg:
+		if msg.isTitle {
+			m.composition.Title = msg.value
+		} else if msg.stepIndex < len(m.composition.Steps) {
+			m.composition.Steps[msg.stepIndex].Comment = msg.value
+		}
+		return m.compose()
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	}
+	return nil
+}
+
+// start composes the commands in their creation order
+func (m *composer) start(commands []*dbmodels.Command) tea.Cmd {
+	commands = slices.Clone(commands)
+	slices.SortFunc(commands, func(a, b *dbmodels.Command) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	m.composition = dbmodels.NewComposition(commands)
+	m.cursor = 0
+	m.preview.GotoTop()
+	return m.compose()
+}
+
+//nolint:cyclop // one case per key
+func (m *composer) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	steps := m.composition.Steps
+	switch {
+	case tui.CheckKey(msg, m.keyMap.PreviousStep):
+		m.cursor = max(0, m.cursor-1)
+	case tui.CheckKey(msg, m.keyMap.NextStep):
+		m.cursor = max(0, min(len(steps)-1, m.cursor+1))
+	case tui.CheckKey(msg, m.keyMap.MoveStepUp):
+		return m.moveStep(-1)
+	case tui.CheckKey(msg, m.keyMap.MoveStepDown):
+		return m.moveStep(1)
+	case tui.CheckKey(msg, m.keyMap.ToggleFunction):
+		if m.cursor < len(steps) {
+			steps[m.cursor].AsFunction = !steps[m.cursor].AsFunction
+			return m.compose()
+		}
+	case tui.CheckKey(msg, m.keyMap.RemoveStep):
+		if m.composition.RemoveStep(m.cursor) {
+			m.cursor = max(0, min(len(m.composition.Steps)-1, m.cursor))
+			return m.compose()
+		}
+	case tui.CheckKey(msg, m.keyMap.EditComment):
+		return m.editComment()
+	case tui.CheckKey(msg, m.keyMap.EditTitle):
+		return promptText(
+			"Title", m.composition.Title, titleInputMaxSize, m.styles.EditorStyle,
+			func(value string) tea.Cmd {
+				return tui.CmdHandler(composerValueMsg{value: value, stepIndex: 0, isTitle: true})
+			},
+		)
+	case tui.CheckKey(msg, m.keyMap.NextErrorHandler):
+		errorHandlings := dbmodels.GetErrorHandlings()
+		index := slices.Index(errorHandlings, m.composition.ErrorHandling)
+		m.composition.ErrorHandling = errorHandlings[(index+1)%len(errorHandlings)]
+		return m.compose()
+	case tui.CheckKey(msg, m.keyMap.Save):
+		return m.save()
+	case tui.CheckKey(msg, m.keyMap.Close):
+		return tui.CmdHandler(structure.NewNavigationMsg(
+			structure.CommandListKind, structure.WithPosition(structure.TopPane),
+		))
+	default:
+		_, cmd := m.preview.Update(msg)
+		return cmd
+	}
+	// the key has been handled, the global keys must not be triggered
+	return tui.GetDummyCmd()
+}
+
+func (m *composer) moveStep(step int) tea.Cmd {
+	if !m.composition.SwapSteps(m.cursor, m.cursor+step) {
+		return tui.GetDummyCmd()
+	}
+	m.cursor += step
+	return m.compose()
+}
+
+func (m *composer) editComment() tea.Cmd {
+	if m.cursor >= len(m.composition.Steps) {
+		return tui.GetDummyCmd()
+	}
+	index := m.cursor
+	title := fmt.Sprintf("Comment of step %d", index+1)
+	return promptText(
+		title, m.composition.Steps[index].Comment, textPromptInputMaxSize, m.styles.EditorStyle,
+		func(value string) tea.Cmd {
+			return tui.CmdHandler(composerValueMsg{value: value, stepIndex: index, isTitle: false})
+		},
+	)
+}
+
+// compose generates the script and lints it in background
+func (m *composer) compose() tea.Cmd {
+	m.script = services.ComposeScript(m.composition)
+	m.resizePreview()
+	m.refreshPreview()
+	if m.lint.script == m.script {
+		return tui.GetDummyCmd()
+	}
+	script := m.script
+	return func() tea.Msg {
+		cmd := dbmodels.NewCommand(script, 0, time.Now())
+		issues := m.lintService.LintCommand(cmd)
+		return composerLintedMsg{script: script, lintStatus: cmd.LintStatus, issues: issues}
+	}
+}
+
+func (m *composer) save() tea.Cmd {
+	newCmd, err := m.historyService.ComposeCommand(m.composition)
+	if err != nil {
+		return tui.ReportError(&ErrComposeCommand{Err: err})
+	}
+	return tea.Sequence(
+		tui.CmdHandler(structure.NewNavigationMsg(
+			structure.CommandListKind, structure.WithPosition(structure.TopPane),
+		)),
+		tui.CmdHandler(commandComposedMsg{command: newCmd, stepsCount: len(m.composition.Steps)}),
+	)
+}
+
+// resizePreview gives the preview the height left by the steps
+func (m *composer) resizePreview() {
+	m.preview.Width = m.width
+	m.preview.Height = max(1, m.height-composerHeaderHeight-len(m.composition.Steps))
+}
+
+// refreshPreview renders the numbered lines of the script followed by the lint issues
+func (m *composer) refreshPreview() {
+	editorStyle := m.styles.EditorStyle
+	lines := strings.Split(strings.TrimRight(m.script, "\n"), "\n")
+	numberWidth := len(fmt.Sprint(len(lines)))
+	rendered := make([]string, 0, len(lines)+len(m.lint.issues)+1)
+	for i, line := range lines {
+		rendered = append(rendered, editorStyle.ReadonlyLabel.Render(fmt.Sprintf("%*d ", numberWidth, i+1))+line)
+	}
+	rendered = append(rendered, "", editorStyle.ReadonlyLabel.Render("Lint: ")+m.viewLintStatus())
+	for _, issue := range m.lint.issues {
+		message := fmt.Sprintf("  line %d: %s %s: %s", issue.Line, issue.Level, issue.GetCode(), issue.Message)
+		if issue.Level == string(dbmodels.LintLevelError) {
+			rendered = append(rendered, editorStyle.StatusError.Render(message))
+		} else {
+			rendered = append(rendered, editorStyle.StatusWarning.Render(message))
+		}
+	}
+	m.preview.SetContent(strings.Join(rendered, "\n"))
+}
+
+func (m *composer) viewLintStatus() string {
+	editorStyle := m.styles.EditorStyle
+	switch {
+	case m.lint.script != m.script:
+		return editorStyle.StatusDisabled.Render("linting…")
+	case m.lint.lintStatus == dbmodels.LintStatusNotAvailable:
+		return editorStyle.StatusDisabled.Render("not available")
+	case len(m.lint.issues) == 0:
+		return editorStyle.StatusOK.Render("no issue")
+	default:
+		return editorStyle.ReadonlyValue.Render(fmt.Sprintf("%d issue(s)", len(m.lint.issues)))
+	}
+}
+
+func (m *composer) View() string {
+	editorStyle := m.styles.EditorStyle
+	title := m.composition.Title
+	if title == "" {
+		title = editorStyle.StatusDisabled.Render("untitled")
+	}
+	lines := []string{
+		fmt.Sprintf("%s %s  %s %s  %s %s",
+			editorStyle.ReadonlyLabel.Render("Title:"), title,
+			editorStyle.ReadonlyLabel.Render("Error handling:"), m.composition.ErrorHandling,
+			editorStyle.ReadonlyLabel.Render("Shell:"), m.composition.GetShell(),
+		),
+		editorStyle.Title.Render("Steps"),
+	}
+	for i, step := range m.composition.Steps {
+		lines = append(lines, m.viewStep(i, step))
+	}
+	lines = append(lines, editorStyle.Title.Render("Preview"), m.preview.View())
+	return strings.Join(lines, "\n")
+}
+
+func (m *composer) viewStep(index int, step *dbmodels.CompositionStep) string {
+	editorStyle := m.styles.EditorStyle
+	label := step.Comment
+	if label == "" {
+		label, _, _ = strings.Cut(step.Command.GetSingleLineDescription(m.width/sidesCount), "\n")
+	}
+	function := "  "
+	if step.AsFunction {
+		function = "ƒ "
+	}
+	line := fmt.Sprintf("%2d. %s#%d %s", index+1, function, step.Command.ID, label)
+	if index == m.cursor {
+		return editorStyle.LabelFocused.Render("> " + line)
+	}
+	return "  " + line
+}
+
+// BorderText returns text to display in the border
+func (*composer) BorderText() map[styles.BorderPosition]string {
+	return map[styles.BorderPosition]string{
+		styles.TopMiddleBorder: "Compose a script",
+	}
+}
+
+// HelpBindings returns the keys of the pane displayed in the help
+func (m *composer) HelpBindings() []*key.Binding {
+	return []*key.Binding{
+		m.keyMap.PreviousStep,
+		m.keyMap.NextStep,
+		m.keyMap.MoveStepUp,
+		m.keyMap.MoveStepDown,
+		m.keyMap.EditComment,
+		m.keyMap.ToggleFunction,
+		m.keyMap.RemoveStep,
+		m.keyMap.EditTitle,
+		m.keyMap.NextErrorHandler,
+		m.keyMap.PreviousPage,
+		m.keyMap.NextPage,
+		m.keyMap.Save,
+		m.keyMap.Close,
+	}
+}
@@ -38,11 +38,13 @@ type EditorMaker struct {
 
 // Number of input fields
 const (
-	numInputFields           = 3    // Title, Description, Script
+	numInputFields           = 6    // Title, Description, Script, Shell, Placeholder sources, Short name
 	titleInputMaxSize        = 50   // Max size for title input
+	shellInputMaxSize        = 10   // Max size for shell input
 	descriptionInputMaxSize  = 1000 // Max size for description input
 	descriptionInputHeight   = 5    // Height for description input
 	scriptInputHeight        = 5    // Height for script input
+	placeholdersInputHeight  = 4    // Height for placeholder sources input
 	descriptionWordwrapWidth = 80   // Word wrap width for description input
 	inputFieldPadding        = 2
 )
@@ -55,6 +57,8 @@ func (mm *EditorMaker) Make(id resource.ID, width, height int) (structure.ChildM
 			AppService:    mm.App.Self(),
 			styles:        mm.Styles,
 			command:       nil,
+			lintResult:    nil,
+			fixPreview:    fixPreviewNone,
 			width:         width,
 			height:        height,
 			inputs:        make([]inputs.Input, numInputFields),
@@ -80,11 +84,13 @@ type commandEditor struct {
 	*services.AppService
 	styles        *styles.Styles
 	command       *dbmodels.Command
+	lintResult    *dbmodels.Command // lint of the script being edited
 	EditorKeyMap  *keys.EditorKeyMap
 	inputs        []inputs.Input
 	width         int
 	height        int
 	focused       int
+	fixPreview    int
 	pagePosition  int
 	contentHeight int
 	initialized   bool
@@ -95,6 +101,13 @@ func (m *commandEditor) BeforeSwitchPane() tea.Cmd {
 }
 
 func (m *commandEditor) getCommand(commandID resource.ID) (*dbmodels.Command, error) {
+	// the commands of the team catalogs have negative ids
+	if commandID < 0 {
+		if command := m.TeamCatalogService.GetCommandByID(commandID); command != nil {
+			return command, nil
+		}
+		return nil, fmt.Errorf("%w %d", ErrCommandNotFound, commandID)
+	}
 	// Load the command from the database
 	command, err := m.DBService.GetCommandByID(commandID)
 	if err != nil {
@@ -112,10 +125,23 @@ func (m *commandEditor) setCommand(command *dbmodels.Command) {
 		slog.Error("setCommand called with nil command")
 		return
 	}
+	if m.command != nil && m.command.ID == command.ID && m.EditionInProgress() {
+		// the command has been reloaded, its lint result for instance, keep the
+		// changes in progress
+		if m.lintResult == m.command {
+			m.lintResult = command
+		}
+		m.command = command
+		return
+	}
 	m.command = command
 	m.inputs[0].SetValue(m.command.Title)
 	m.inputs[1].SetValue(m.command.Description)
 	m.inputs[2].SetValue(m.command.Script)
+	m.inputs[3].SetValue(string(m.command.Shell))
+	m.inputs[4].SetValue(m.command.PlaceholderSources)
+	m.inputs[5].SetValue(m.command.ShortName)
+	m.resetLintResult()
 	m.initInputs()
 }
 
@@ -142,7 +168,27 @@ func (m *commandEditor) Init() tea.Cmd {
 		m.styles.EditorStyle,
 	)
 
-	m.inputs = []inputs.Input{titleInput, descriptionInput, scriptInput}
+	shellInput := inputs.NewInputWrapper(
+		"Enter shell ("+joinShellDialects()+")",
+		m.styles.EditorStyle,
+	)
+	shellInput.SetCharLimit(shellInputMaxSize)
+
+	placeholdersInput := inputs.NewTextAreaWrapper(
+		placeholdersInputHeight,
+		"eg: env: {choices: [dev, prod], default: dev}",
+		m.styles.EditorStyle,
+	)
+
+	shortNameInput := inputs.NewInputWrapper(
+		"Enter the name of the shell alias or function, eg: gs",
+		m.styles.EditorStyle,
+	)
+	shortNameInput.SetCharLimit(dbmodels.ShortNameMaxLength)
+
+	m.inputs = []inputs.Input{
+		titleInput, descriptionInput, scriptInput, shellInput, placeholdersInput, shortNameInput,
+	}
 	m.focused = -1
 	m.initialized = true
 
@@ -151,7 +197,7 @@ func (m *commandEditor) Init() tea.Cmd {
 
 // formatLintStatus returns a styled string representing the lint status
 func (m *commandEditor) formatLintStatus() string {
-	switch m.command.LintStatus {
+	switch m.getLintResult().LintStatus {
 	case dbmodels.LintStatusOK:
 		return m.styles.EditorStyle.StatusOK.Render("OK")
 	case dbmodels.LintStatusWarning:
@@ -182,6 +228,8 @@ func (m *commandEditor) Update(msg tea.Msg) tea.Cmd {
 	case table.RowSelectedActionMsg[*dbmodels.Command]:
 		// This message is sent when a row is selected in the command table
 		m.setCommand(msg.Row)
+	case editorLintedMsg:
+		return m.handleEditorLintedMsg(msg)
 	case tea.KeyMsg:
 		cmd := m.handleKeyMsg(msg)
 		if cmd != nil {
@@ -267,6 +315,10 @@ func (m *commandEditor) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
 		return m.save()
 	case key.Matches(msg, *editorK.Cancel) && editorK.Cancel.Enabled():
 		return m.confirmAbandonChanges(true)
+	case key.Matches(msg, *editorK.PreviewFix) && editorK.PreviewFix.Enabled():
+		return m.lintEditedScript(fixActionPreview)
+	case key.Matches(msg, *editorK.ApplyFix) && editorK.ApplyFix.Enabled():
+		return m.lintEditedScript(fixActionApply)
 	}
 
 	return tea.Batch(cmds...)
@@ -313,6 +365,9 @@ func (m *commandEditor) addCommonElements(content *strings.Builder) {
 	var helpText string
 	if m.command.IsEditable() {
 		helpText = helpTextStyle.Render("⭾/Shift-⭾: Fields • ⇞/⇟: Scroll • Ctrl+S: Save • Esc: Cancel")
+		if m.canFix() && len(m.getFixableIssues()) > 0 {
+			helpText += "\n" + helpTextStyle.Render("F7: Preview lint fixes • F8: Apply previewed lint fixes")
+		}
 	} else {
 		helpText = m.styles.EditorStyle.StatusWarning.Render("Command is read-only") +
 			"         " + helpTextStyle.Render("⭾/Shift-⭾: Fields • ⇞/⇟: Scroll • Esc: Close")
@@ -320,7 +375,11 @@ func (m *commandEditor) addCommonElements(content *strings.Builder) {
 	content.WriteString(helpText + "\n\n")
 
 	// Labels for our fields
-	labels := []string{"Title:", "Description(markdown):", "Script:"}
+	labels := []string{
+		"Title:", "Description(markdown):", "Script:", "Shell:",
+		"Placeholder suggestions (yaml: default, choices or command):",
+		"Short name (alias or function of the shell library):",
+	}
 
 	// Render each field with its label
 	for i, label := range labels {
@@ -355,17 +414,40 @@ func (m *commandEditor) addReadonlySection(content *strings.Builder) {
 	lintIssuesLabel := m.styles.EditorStyle.ReadonlyLabel.Render("Lint Issues:")
 
 	// Add the formatted readonly information
+	if m.command.Source != "" {
+		fmt.Fprintf(content, "%s %s\n", m.styles.EditorStyle.ReadonlyLabel.Render("Team catalog:"),
+			m.styles.EditorStyle.SourceBadge.Render(m.command.Source))
+	}
 	fmt.Fprintf(content, "%s %s\n", createLabel, createValue)
 	fmt.Fprintf(content, "%s %s\n", modifyLabel, modifyValue)
 	fmt.Fprintf(content, "%s %s\n", lintStatusLabel, m.formatLintStatus())
+	m.addLintRules(content)
 
 	m.addLintIssues(content, lintIssuesLabel)
+	m.addFixPreview(content)
+}
+
+// addLintRules adds the rules used to compute the lint issues, so that
+// the user can understand why an issue is reported or not
+func (m *commandEditor) addLintRules(content *strings.Builder) {
+	lintRules := m.getLintResult().LintRules
+	if lintRules == "" {
+		return
+	}
+	rules, err := dbmodels.ParseLintRules(lintRules)
+	if err != nil {
+		slog.Warn("Invalid lint rules", "id", m.command.ID, "error", err)
+		return
+	}
+	fmt.Fprintf(content, "%s %s\n",
+		m.styles.EditorStyle.ReadonlyLabel.Render("Lint Rules:"),
+		m.styles.EditorStyle.ReadonlyValue.Render(rules.Describe()))
 }
 
 // addLintIssues adds the lint issues section to the content
 func (m *commandEditor) addLintIssues(content *strings.Builder, lintIssuesLabel string) {
 	// Parse and display lint issues
-	issues := m.command.GetLintIssues()
+	issues := m.getLintResult().GetLintIssues()
 	if len(issues) == 0 {
 		fmt.Fprintf(content, "%s %s\n\n", lintIssuesLabel,
 			m.styles.EditorStyle.ReadonlyValue.Render("None"))
@@ -392,8 +474,18 @@ func (m *commandEditor) addLintIssues(content *strings.Builder, lintIssuesLabel
 			level = lvl
 		}
 
+		// Prefix the message with the shellcheck code or the rule, to be used in the lint rules
+		if rule, ok := issue["rule"].(string); ok && rule != "" {
+			message = fmt.Sprintf("%s: %s", rule, message)
+		} else if code, ok := issue["code"].(float64); ok {
+			message = fmt.Sprintf("SC%d: %s", int(code), message)
+		}
+
 		// Style based on level
 		styledMessage := m.getStyledMessage(level, message)
+		if issue["fix"] != nil {
+			styledMessage += m.styles.EditorStyle.ReadonlyLabel.Render(" (fix available)")
+		}
 		fmt.Fprintf(content, "   %s %s %s\n", num, level, styledMessage)
 	}
 	content.WriteString("\n")
@@ -506,7 +598,10 @@ func (m *commandEditor) prevPage() {
 func (m *commandEditor) EditionInProgress() bool {
 	return m.command.Title != m.inputs[0].Value() ||
 		m.command.Description != m.inputs[1].Value() ||
-		m.command.Script != m.inputs[2].Value()
+		m.command.Script != m.inputs[2].Value() ||
+		string(m.command.Shell) != m.inputs[3].Value() ||
+		m.command.PlaceholderSources != m.inputs[4].Value() ||
+		m.command.ShortName != m.inputs[5].Value()
 }
 
 // save saves the current command
@@ -515,15 +610,40 @@ func (m *commandEditor) save() tea.Cmd {
 	oldTitle := m.command.Title
 	oldDescription := m.command.Description
 	oldScript := m.command.Script
+	oldShell := m.command.Shell
+	oldPlaceholderSources := m.command.PlaceholderSources
+	oldShortName := m.command.ShortName
+
+	shell, ok := dbmodels.ParseShellDialect(m.inputs[3].Value())
+	if !ok {
+		return tui.ReportError(&ErrInvalidShellDialect{
+			Shell:    m.inputs[3].Value(),
+			Expected: joinShellDialects(),
+		})
+	}
+
+	if _, err := dbmodels.ParsePlaceholderSources(m.inputs[4].Value()); err != nil {
+		return tui.ReportError(err)
+	}
+
+	if err := dbmodels.ValidateShortName(m.inputs[5].Value()); err != nil {
+		return tui.ReportError(err)
+	}
 
 	m.command.Title = m.inputs[0].Value()
 	m.command.Description = m.inputs[1].Value()
 	m.command.Script = m.inputs[2].Value()
+	m.command.Shell = shell
+	m.command.PlaceholderSources = m.inputs[4].Value()
+	m.command.ShortName = m.inputs[5].Value()
 
 	// Only update if there are actual changes
 	if oldTitle != m.command.Title ||
 		oldDescription != m.command.Description ||
-		oldScript != m.command.Script {
+		oldScript != m.command.Script ||
+		oldShell != m.command.Shell ||
+		oldPlaceholderSources != m.command.PlaceholderSources ||
+		oldShortName != m.command.ShortName {
 		// Update command in database using HistoryService
 		newCommand, err := m.HistoryService.UpdateCommand(m.command)
 		if err != nil {
@@ -531,6 +651,7 @@ func (m *commandEditor) save() tea.Cmd {
 			return tui.ReportError(err)
 		}
 		m.command = newCommand
+		m.resetLintResult()
 
 		// Trigger table reload to reflect changes
 		infoMsg := tui.InfoMsg(fmt.Sprintf(
@@ -566,6 +687,20 @@ func (m *commandEditor) revertChanges() {
 	m.inputs[0].SetValue(m.command.Title)
 	m.inputs[1].SetValue(m.command.Description)
 	m.inputs[2].SetValue(m.command.Script)
+	m.inputs[3].SetValue(string(m.command.Shell))
+	m.inputs[4].SetValue(m.command.PlaceholderSources)
+	m.inputs[5].SetValue(m.command.ShortName)
+	m.resetLintResult()
+}
+
+// joinShellDialects returns the supported shell dialects separated by commas
+func joinShellDialects() string {
+	dialects := dbmodels.GetShellDialects()
+	names := make([]string, 0, len(dialects))
+	for _, dialect := range dialects {
+		names = append(names, string(dialect))
+	}
+	return strings.Join(names, ", ")
 }
 
 // BorderText returns text to display in the border
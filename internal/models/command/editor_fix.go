@@ -0,0 +1,206 @@
+package command
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/diff"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+// fix preview modes, a positive value previews only the fix at this position
+const (
+	fixPreviewNone = -1
+	fixPreviewAll  = 0
+)
+
+// scriptInputIndex is the position of the script field in the editor inputs
+const scriptInputIndex = 2
+
+// resetLintResult uses the lint result stored with the command
+func (m *commandEditor) resetLintResult() {
+	m.lintResult = m.command
+	m.fixPreview = fixPreviewNone
+}
+
+// getLintResult returns the command holding the lint issues of the script being edited
+func (m *commandEditor) getLintResult() *dbmodels.Command {
+	if m.lintResult == nil {
+		return m.command
+	}
+	return m.lintResult
+}
+
+// fixAction is the fix action performed once the edited script is linted
+type fixAction int
+
+const (
+	// fixActionNone only updates the lint result
+	fixActionNone fixAction = iota
+	fixActionPreview
+	fixActionApply
+)
+
+// editorLintedMsg contains the lint result of the script being edited
+type editorLintedMsg struct {
+	linted    *dbmodels.Command
+	commandID resource.ID
+	action    fixAction
+}
+
+func (msg editorLintedMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.CommandEditorKind, ID: msg.commandID}
+}
+
+// lintEditedScript performs the fix action, the script being edited is linted
+// in background first if it has changed since the last lint
+func (m *commandEditor) lintEditedScript(action fixAction) tea.Cmd {
+	if !m.canFix() {
+		return nil
+	}
+	script := m.inputs[scriptInputIndex].Value()
+	if m.getLintResult().Script == script {
+		return m.runFixAction(action)
+	}
+	linted := *m.command
+	linted.Script = script
+	if shell, ok := dbmodels.ParseShellDialect(m.inputs[3].Value()); ok {
+		linted.Shell = shell
+	}
+	lintService := m.LintService
+	commandID := m.command.ID
+	return func() tea.Msg {
+		lintService.LintCommand(&linted)
+		return editorLintedMsg{linted: &linted, commandID: commandID, action: action}
+	}
+}
+
+// handleEditorLintedMsg stores the lint result and performs the fix action,
+// the result is ignored if the script has been modified during the lint
+func (m *commandEditor) handleEditorLintedMsg(msg editorLintedMsg) tea.Cmd {
+	if m.command == nil || msg.linted.Script != m.inputs[scriptInputIndex].Value() {
+		return nil
+	}
+	m.lintResult = msg.linted
+	m.fixPreview = fixPreviewNone
+	return m.runFixAction(msg.action)
+}
+
+func (m *commandEditor) runFixAction(action fixAction) tea.Cmd {
+	switch action {
+	case fixActionPreview:
+		return m.previewNextFix()
+	case fixActionApply:
+		return m.applyFixes()
+	case fixActionNone:
+	}
+	return nil
+}
+
+// getFixableIssues returns the lint issues of the edited script providing a fix
+func (m *commandEditor) getFixableIssues() []services.ShellCheckIssue {
+	issues, err := services.ParseLintIssues(m.getLintResult().LintIssues)
+	if err != nil {
+		slog.Error("Error parsing lint issues", "id", m.command.ID, "error", err)
+		return []services.ShellCheckIssue{}
+	}
+	return services.GetFixableIssues(issues)
+}
+
+// canFix returns true if lint fixes can be applied to the edited script
+func (m *commandEditor) canFix() bool {
+	return m.command.IsEditable() && m.LintService.IsLintingAvailable()
+}
+
+// getPreviewedFixes returns the fixes selected by the current preview mode
+func (m *commandEditor) getPreviewedFixes(fixable []services.ShellCheckIssue) []services.ShellCheckIssue {
+	if m.fixPreview > fixPreviewAll && m.fixPreview <= len(fixable) {
+		return fixable[m.fixPreview-1 : m.fixPreview]
+	}
+	return fixable
+}
+
+// previewNextFix cycles between the preview of all the fixes, each fix
+// individually and no preview, the edited script must be linted
+func (m *commandEditor) previewNextFix() tea.Cmd {
+	if !m.canFix() {
+		return nil
+	}
+	fixable := m.getFixableIssues()
+	if len(fixable) == 0 {
+		m.fixPreview = fixPreviewNone
+		return tui.ReportError(&ErrNoFixAvailable{})
+	}
+	m.fixPreview++
+	if m.fixPreview > len(fixable) || (len(fixable) == 1 && m.fixPreview > fixPreviewAll) {
+		m.fixPreview = fixPreviewNone
+	}
+	return nil
+}
+
+// applyFixes applies the previewed fixes (all the fixes if no preview)
+// to the script field and lints the resulting script, the edited script
+// must be linted
+func (m *commandEditor) applyFixes() tea.Cmd {
+	if !m.canFix() {
+		return nil
+	}
+	fixable := m.getFixableIssues()
+	if len(fixable) == 0 {
+		return tui.ReportError(&ErrNoFixAvailable{})
+	}
+	selected := m.getPreviewedFixes(fixable)
+	fixed, err := services.ApplyFixes(m.inputs[scriptInputIndex].Value(), selected)
+	if err != nil {
+		slog.Error("Error applying lint fixes", "id", m.command.ID, "error", err)
+		return tui.ReportError(err)
+	}
+	m.inputs[scriptInputIndex].SetValue(fixed)
+	m.fixPreview = fixPreviewNone
+	return tea.Batch(
+		m.lintEditedScript(fixActionNone),
+		tui.ReportInfo("%d lint fix(es) applied to command #%d, save to keep them", len(selected), m.command.ID),
+	)
+}
+
+// addFixPreview adds the diff between the edited script and the fixed script
+func (m *commandEditor) addFixPreview(content *strings.Builder) {
+	if m.fixPreview == fixPreviewNone {
+		return
+	}
+	fixable := m.getFixableIssues()
+	if len(fixable) == 0 {
+		return
+	}
+	title := "Preview of all the fixes:"
+	if m.fixPreview > fixPreviewAll {
+		issue := fixable[m.fixPreview-1]
+		title = fmt.Sprintf("Preview of fix %d/%d (SC%d):", m.fixPreview, len(fixable), issue.Code)
+	}
+	content.WriteString(m.styles.EditorStyle.Label.Render(title) + "\n")
+
+	script := m.inputs[scriptInputIndex].Value()
+	fixed, err := services.ApplyFixes(script, m.getPreviewedFixes(fixable))
+	if err != nil {
+		content.WriteString(m.styles.EditorStyle.StatusError.Render(err.Error()) + "\n\n")
+		return
+	}
+	for _, line := range diff.Lines(script, fixed) {
+		style := m.styles.EditorStyle.ReadonlyValue
+		switch line.Op {
+		case diff.Delete:
+			style = m.styles.EditorStyle.StatusError
+		case diff.Insert:
+			style = m.styles.EditorStyle.StatusOK
+		case diff.Equal:
+		}
+		content.WriteString(style.Render(line.Op.Prefix()+line.Text) + "\n")
+	}
+	content.WriteString("\n")
+}
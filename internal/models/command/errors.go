@@ -15,6 +15,15 @@ func (e *ErrComposeCommand) Error() string {
 	return fmt.Sprintf("failed to compose command: %v", e.Err)
 }
 
+// ErrRunCommand represents an error when a command cannot be run
+type ErrRunCommand struct {
+	Err error
+}
+
+func (e *ErrRunCommand) Error() string {
+	return fmt.Sprintf("failed to run command: %v", e.Err)
+}
+
 // ErrRestoreCommand represents an error when restoring a command fails
 type ErrRestoreCommand struct {
 	Err error
@@ -31,6 +40,22 @@ func (*ErrSelectionMismatch) Error() string {
 	return "selection mismatch: the selected commands do not match the expected criteria for this operation"
 }
 
+// ErrTeamCommandReadOnly is returned when modifying a command of a team catalog
+type ErrTeamCommandReadOnly struct{}
+
+func (*ErrTeamCommandReadOnly) Error() string {
+	return "the commands of the team catalogs are read-only, copy them to your bookmarks first"
+}
+
+// ErrCopyTeamCommand represents an error when copying team commands to the database fails
+type ErrCopyTeamCommand struct {
+	Err error
+}
+
+func (e *ErrCopyTeamCommand) Error() string {
+	return fmt.Sprintf("failed to copy team command: %v", e.Err)
+}
+
 // ErrNoCommandsSelected is returned when no commands are selected for an operation
 type ErrNoCommandsSelected struct{}
 
@@ -55,3 +80,20 @@ type ErrCommandLoadingFailure struct {
 func (e *ErrCommandLoadingFailure) Error() string {
 	return fmt.Sprintf("failed to load command with ID %d: %v", e.CommandID, e.Err)
 }
+
+// ErrInvalidShellDialect is returned when the shell entered in the editor is not supported
+type ErrInvalidShellDialect struct {
+	Shell    string
+	Expected string
+}
+
+func (e *ErrInvalidShellDialect) Error() string {
+	return fmt.Sprintf("invalid shell %q, expected one of: %s", e.Shell, e.Expected)
+}
+
+// ErrNoFixAvailable is returned when applying lint fixes while no fix is proposed
+type ErrNoFixAvailable struct{}
+
+func (*ErrNoFixAvailable) Error() string {
+	return "no lint fix available for this script"
+}
@@ -0,0 +1,307 @@
+package command
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/command/inputs"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	pkgSearch "github.com/fchastanet/shell-command-bookmarker/pkg/search"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+const (
+	placeholderInputMaxSize  = 1024
+	placeholderPreviewLines  = 3
+	placeholderRecentDisplay = 5
+	placeholderChoiceDisplay = 5
+	noChoiceSelected         = -1
+)
+
+// placeholderSubmitFunc receives the scripts of the commands with their placeholders filled
+type placeholderSubmitFunc func(scripts []string) tea.Cmd
+
+// placeholderField is the input of a placeholder, the value is selected
+// among the choices matching the input if the placeholder has choices
+type placeholderField struct {
+	err         error
+	input       inputs.Input
+	name        string
+	suggestions services.PlaceholderSuggestions
+	// filtered are the choices matching the input, the best match first
+	filtered []string
+	// selected is the index of the selected choice in filtered
+	selected int
+	// recentIndex is the index of the recent value set in the input
+	recentIndex int
+}
+
+// placeholderForm is a prompt asking the values of the placeholders of
+// the commands, the suggested values of each placeholder are proposed
+type placeholderForm struct {
+	placeholderService *services.PlaceholderService
+	keyMap             *keys.PlaceholderFormKeyMap
+	styles             *styles.EditorStyle
+	onSubmit           placeholderSubmitFunc
+	commands           []*dbmodels.Command
+	fields             []*placeholderField
+	focused            int
+	completed          bool
+}
+
+// fillPlaceholders calls onSubmit with the scripts of the commands, a form is
+// displayed first if the commands have placeholders
+func fillPlaceholders(
+	placeholderService *services.PlaceholderService,
+	editorStyle *styles.EditorStyle,
+	commands []*dbmodels.Command,
+	onSubmit placeholderSubmitFunc,
+) tea.Cmd {
+	names := placeholderService.GetPlaceholders(commands)
+	if len(names) == 0 {
+		scripts := make([]string, 0, len(commands))
+		for _, cmd := range commands {
+			scripts = append(scripts, cmd.Script)
+		}
+		return onSubmit(scripts)
+	}
+	// suggestions commands can be slow, the form is built outside of the update loop
+	return func() tea.Msg {
+		form := &placeholderForm{
+			placeholderService: placeholderService,
+			keyMap:             keys.GetPlaceholderFormKeyMap(),
+			styles:             editorStyle,
+			onSubmit:           onSubmit,
+			commands:           commands,
+			fields:             make([]*placeholderField, 0, len(names)),
+			focused:            0,
+			completed:          false,
+		}
+		for _, name := range names {
+			suggestions, err := placeholderService.GetSuggestions(commands, name)
+			form.fields = append(form.fields, newPlaceholderField(name, suggestions, err, editorStyle))
+		}
+		return tui.PromptMsg{Prompt: form}
+	}
+}
+
+func newPlaceholderField(
+	name string,
+	suggestions services.PlaceholderSuggestions,
+	err error,
+	editorStyle *styles.EditorStyle,
+) *placeholderField {
+	input := inputs.NewInputWrapper("Enter "+name, editorStyle)
+	input.SetCharLimit(placeholderInputMaxSize)
+	field := &placeholderField{
+		err:         err,
+		input:       input,
+		name:        name,
+		suggestions: suggestions,
+		filtered:    suggestions.Choices,
+		selected:    noChoiceSelected,
+		recentIndex: 0,
+	}
+	if field.hasChoices() {
+		input.Model.Placeholder = "Type to filter " + name
+		field.selected = max(noChoiceSelected, slices.Index(field.filtered, suggestions.Default))
+	} else {
+		input.SetValue(suggestions.Default)
+	}
+	return field
+}
+
+func (f *placeholderField) hasChoices() bool {
+	return len(f.suggestions.Choices) > 0
+}
+
+// filter selects the best choice matching the input, the typed value
+// is used if no choice matches
+func (f *placeholderField) filter() {
+	pattern := f.input.Value()
+	scores := map[string]int{}
+	f.filtered = []string{}
+	for _, choice := range f.suggestions.Choices {
+		if score := pkgSearch.FuzzyMatchScore(choice, pattern); score >= 0 {
+			scores[choice] = score
+			f.filtered = append(f.filtered, choice)
+		}
+	}
+	slices.SortStableFunc(f.filtered, func(a, b string) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+	f.selected = noChoiceSelected
+	if len(f.filtered) > 0 {
+		f.selected = 0
+	}
+}
+
+// cycle selects another choice, or sets another recent value in the input
+// if the placeholder has no choices
+func (f *placeholderField) cycle(step int) {
+	if f.hasChoices() {
+		if len(f.filtered) > 0 {
+			f.selected = (f.selected + step + len(f.filtered)) % len(f.filtered)
+		}
+		return
+	}
+	recentValues := f.suggestions.Recent
+	if len(recentValues) == 0 {
+		return
+	}
+	f.recentIndex = (f.recentIndex + step + len(recentValues)) % len(recentValues)
+	f.input.SetValue(recentValues[f.recentIndex])
+}
+
+func (f *placeholderField) value() string {
+	if f.selected >= 0 && f.selected < len(f.filtered) {
+		return f.filtered[f.selected]
+	}
+	return f.input.Value()
+}
+
+func (f *placeholderForm) Init() tea.Cmd {
+	return f.fields[f.focused].input.Focus()
+}
+
+func (f *placeholderForm) IsCompleted() bool {
+	return f.completed
+}
+
+// CapturesText prevents the global keys to be triggered while typing a value
+func (*placeholderForm) CapturesText() bool {
+	return true
+}
+
+func (f *placeholderForm) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		for _, field := range f.fields {
+			field.input.SetWidth(msg.Width / sidesCount)
+		}
+		return nil
+	case tea.KeyMsg:
+		return f.handleKey(msg)
+	}
+	_, cmd := f.fields[f.focused].input.Update(msg)
+	return cmd
+}
+
+func (f *placeholderForm) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case tui.CheckKey(msg, f.keyMap.Cancel):
+		f.completed = true
+		return tui.ReportInfo("Placeholders filling cancelled")
+	case tui.CheckKey(msg, f.keyMap.Submit):
+		f.completed = true
+		return f.onSubmit(f.placeholderService.FillCommands(f.commands, f.getValues()))
+	case tui.CheckKey(msg, f.keyMap.NextField):
+		return f.focus((f.focused + 1) % len(f.fields))
+	case tui.CheckKey(msg, f.keyMap.PreviousField):
+		return f.focus((f.focused - 1 + len(f.fields)) % len(f.fields))
+	case tui.CheckKey(msg, f.keyMap.NextValue):
+		f.fields[f.focused].cycle(1)
+		return nil
+	case tui.CheckKey(msg, f.keyMap.PreviousValue):
+		f.fields[f.focused].cycle(-1)
+		return nil
+	}
+	field := f.fields[f.focused]
+	previousValue := field.input.Value()
+	_, cmd := field.input.Update(msg)
+	if field.hasChoices() && field.input.Value() != previousValue {
+		field.filter()
+	}
+	return cmd
+}
+
+func (f *placeholderForm) focus(index int) tea.Cmd {
+	f.fields[f.focused].input.Blur()
+	f.focused = index
+	return f.fields[f.focused].input.Focus()
+}
+
+func (f *placeholderForm) getValues() map[string]string {
+	values := make(map[string]string, len(f.fields))
+	for _, field := range f.fields {
+		values[field.name] = field.value()
+	}
+	return values
+}
+
+func (f *placeholderForm) View() string {
+	lines := []string{f.styles.Title.Render("Fill the placeholders")}
+	for i, field := range f.fields {
+		if i != f.focused {
+			lines = append(lines, f.styles.Label.Render(field.name+":")+" "+field.value())
+			continue
+		}
+		lines = append(lines, f.styles.LabelFocused.Render(field.name+":")+" "+field.input.View())
+		lines = append(lines, f.viewSuggestions(field)...)
+	}
+	lines = append(lines, f.styles.ReadonlyLabel.Render("Preview:"))
+	lines = append(lines, f.viewPreview()...)
+	lines = append(lines, f.viewHelp())
+	return strings.Join(lines, "\n")
+}
+
+// viewSuggestions returns the choices around the selected one, or the
+// recent values if the placeholder has no choices
+func (f *placeholderForm) viewSuggestions(field *placeholderField) []string {
+	lines := []string{}
+	if field.err != nil {
+		lines = append(lines, f.styles.StatusError.Render("  "+field.err.Error()))
+	}
+	if !field.hasChoices() {
+		if len(field.suggestions.Recent) > 0 {
+			recentValues := field.suggestions.Recent[:min(placeholderRecentDisplay, len(field.suggestions.Recent))]
+			lines = append(lines, f.styles.HelpText.Render("  recent: "+strings.Join(recentValues, ", ")))
+		}
+		return lines
+	}
+	if len(field.filtered) == 0 {
+		return append(lines, f.styles.HelpText.Render("  no matching choice, the typed value is used"))
+	}
+	start := max(0, min(field.selected-placeholderChoiceDisplay/sidesCount, len(field.filtered)-placeholderChoiceDisplay))
+	end := min(len(field.filtered), start+placeholderChoiceDisplay)
+	for i := start; i < end; i++ {
+		if i == field.selected {
+			lines = append(lines, f.styles.LabelFocused.Render("  > "+field.filtered[i]))
+		} else {
+			lines = append(lines, f.styles.ReadonlyValue.Render("    "+field.filtered[i]))
+		}
+	}
+	return lines
+}
+
+// viewPreview returns the first lines of the first script, the placeholders
+// without value are kept
+func (f *placeholderForm) viewPreview() []string {
+	values := f.getValues()
+	for name, value := range values {
+		if value == "" {
+			delete(values, name)
+		}
+	}
+	preview := strings.Split(dbmodels.FillPlaceholders(f.commands[0].Script, values), "\n")
+	if len(preview) > placeholderPreviewLines {
+		preview = append(preview[:placeholderPreviewLines], "…")
+	}
+	for i, line := range preview {
+		preview[i] = f.styles.ReadonlyValue.Render(line)
+	}
+	return preview
+}
+
+func (f *placeholderForm) viewHelp() string {
+	parts := []string{}
+	for _, binding := range f.keyMap.GetBindings() {
+		parts = append(parts, binding.Help().Key+" "+binding.Help().Desc)
+	}
+	return f.styles.HelpText.Render(strings.Join(parts, " • "))
+}
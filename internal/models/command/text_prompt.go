@@ -0,0 +1,87 @@
+package command
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/command/inputs"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+const textPromptInputMaxSize = 200
+
+// textPrompt asks a single line value, onSubmit receives the typed value
+type textPrompt struct {
+	input     inputs.Input
+	keyMap    *keys.TextPromptKeyMap
+	styles    *styles.EditorStyle
+	onSubmit  func(value string) tea.Cmd
+	title     string
+	completed bool
+}
+
+// promptText displays a prompt asking a value of at most charLimit
+// characters, initialized with the given value
+func promptText(
+	title string,
+	value string,
+	charLimit int,
+	editorStyle *styles.EditorStyle,
+	onSubmit func(value string) tea.Cmd,
+) tea.Cmd {
+	input := inputs.NewInputWrapper(title, editorStyle)
+	input.SetCharLimit(charLimit)
+	input.SetValue(value)
+	return tui.CmdHandler(tui.PromptMsg{Prompt: &textPrompt{
+		input:     input,
+		keyMap:    keys.GetTextPromptKeyMap(),
+		styles:    editorStyle,
+		onSubmit:  onSubmit,
+		title:     title,
+		completed: false,
+	}})
+}
+
+func (p *textPrompt) Init() tea.Cmd {
+	return p.input.Focus()
+}
+
+func (p *textPrompt) IsCompleted() bool {
+	return p.completed
+}
+
+// CapturesText prevents the global keys to be triggered while typing the value
+func (*textPrompt) CapturesText() bool {
+	return true
+}
+
+func (p *textPrompt) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.input.SetWidth(msg.Width / sidesCount)
+		return nil
+	case tea.KeyMsg:
+		switch {
+		case tui.CheckKey(msg, p.keyMap.Cancel):
+			p.completed = true
+			return nil
+		case tui.CheckKey(msg, p.keyMap.Submit):
+			p.completed = true
+			return p.onSubmit(strings.TrimSpace(p.input.Value()))
+		}
+	}
+	_, cmd := p.input.Update(msg)
+	return cmd
+}
+
+func (p *textPrompt) View() string {
+	return strings.Join([]string{
+		p.styles.LabelFocused.Render(p.title+":") + " " + p.input.View(),
+		p.styles.HelpText.Render(
+			p.keyMap.Submit.Help().Key + " " + p.keyMap.Submit.Help().Desc + " • " +
+				p.keyMap.Cancel.Help().Key + " " + p.keyMap.Cancel.Help().Desc,
+		),
+	}, "\n")
+}
@@ -0,0 +1,188 @@
+// Package conflicts displays the commands modified locally and on the remote
+// since the last sync, and lets the user choose the version to keep
+package conflicts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/diff"
+)
+
+// headerHeight is the number of lines displayed above the diff of a conflict
+const headerHeight = 5
+
+// Resolver runs the conflicts view until all the conflicts are resolved
+type Resolver struct{}
+
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// ResolveConflicts implements services.SyncConflictResolver
+func (*Resolver) ResolveConflicts(conflicts []*services.SyncConflict) error {
+	model := newModel(conflicts)
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		return err
+	}
+	if !model.confirmed {
+		return services.ErrSyncAborted
+	}
+	return nil
+}
+
+type model struct {
+	styles    *styles.Styles
+	keyMap    *keys.ConflictsKeyMap
+	conflicts []*services.SyncConflict
+	current   int
+	height    int
+	confirmed bool
+}
+
+func newModel(conflicts []*services.SyncConflict) *model {
+	return &model{
+		styles:    styles.NewStyles(),
+		keyMap:    keys.GetConflictsKeyMap(),
+		conflicts: conflicts,
+		current:   0,
+		height:    0,
+		confirmed: false,
+	}
+}
+
+func (*model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, *m.keyMap.Abort):
+			return m, tea.Quit
+		case key.Matches(msg, *m.keyMap.Local):
+			m.resolve(services.SyncResolutionLocal)
+		case key.Matches(msg, *m.keyMap.Remote):
+			m.resolve(services.SyncResolutionRemote)
+		case key.Matches(msg, *m.keyMap.KeepBoth):
+			m.resolve(services.SyncResolutionKeepBoth)
+		case key.Matches(msg, *m.keyMap.Previous):
+			m.current = max(0, m.current-1)
+		case key.Matches(msg, *m.keyMap.Next):
+			m.current = min(len(m.conflicts)-1, m.current+1)
+		case key.Matches(msg, *m.keyMap.Confirm):
+			if m.countUnresolved() == 0 {
+				m.confirmed = true
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+// resolve sets the resolution of the current conflict and selects the next one
+func (m *model) resolve(resolution services.SyncResolution) {
+	m.conflicts[m.current].Resolution = resolution
+	m.current = min(len(m.conflicts)-1, m.current+1)
+}
+
+func (m *model) countUnresolved() int {
+	count := 0
+	for _, conflict := range m.conflicts {
+		if conflict.Resolution == services.SyncResolutionAsk {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *model) View() string {
+	editorStyle := m.styles.EditorStyle
+	conflict := m.conflicts[m.current]
+	var content strings.Builder
+	content.WriteString(editorStyle.Title.Render(fmt.Sprintf(
+		"Conflict %d/%d: %s", m.current+1, len(m.conflicts), conflict.Local.Title,
+	)) + "\n")
+	resolution := editorStyle.StatusWarning.Render("not resolved")
+	if conflict.Resolution != services.SyncResolutionAsk {
+		resolution = editorStyle.StatusOK.Render(string(conflict.Resolution))
+	}
+	content.WriteString(editorStyle.ReadonlyLabel.Render("Resolution: ") + resolution + "  " +
+		editorStyle.ReadonlyLabel.Render("Unresolved: ") +
+		editorStyle.ReadonlyValue.Render(fmt.Sprint(m.countUnresolved())) + "\n")
+	content.WriteString(editorStyle.ReadonlyLabel.Render("Diff: ") +
+		editorStyle.StatusError.Render("- local") + " " + editorStyle.StatusOK.Render("+ remote") + "\n\n")
+
+	lines := m.renderDiff(conflict)
+	if available := m.height - headerHeight - 1; available > 1 && len(lines) > available {
+		hidden := len(lines) - available + 1
+		lines = append(lines[:available-1], editorStyle.HelpText.Render(fmt.Sprintf("… %d more line(s)", hidden)))
+	}
+	content.WriteString(strings.Join(lines, "\n") + "\n\n")
+	content.WriteString(m.renderHelp())
+	return content.String()
+}
+
+func (m *model) renderDiff(conflict *services.SyncConflict) []string {
+	editorStyle := m.styles.EditorStyle
+	rendered := []string{}
+	for _, line := range diff.Lines(formatCommand(conflict.Local), formatCommand(conflict.Remote)) {
+		text := line.Op.Prefix() + line.Text
+		switch line.Op {
+		case diff.Delete:
+			rendered = append(rendered, editorStyle.StatusError.Render(text))
+		case diff.Insert:
+			rendered = append(rendered, editorStyle.StatusOK.Render(text))
+		case diff.Equal:
+			rendered = append(rendered, text)
+		}
+	}
+	return rendered
+}
+
+func (m *model) renderHelp() string {
+	helpStyle := m.styles.HelpStyle
+	help := []string{}
+	for _, binding := range keys.KeyMapToSlice(*m.keyMap) {
+		help = append(help, helpStyle.KeyStyle.Render(binding.Help().Key)+" "+
+			helpStyle.DescStyle.Render(binding.Help().Desc))
+	}
+	return strings.Join(help, "  ")
+}
+
+// formatCommand returns the fields of the command that can be modified, one
+// field by line so that the diff shows the modified fields
+func formatCommand(cmd *dbmodels.CatalogCommand) string {
+	fields := []string{
+		"title: " + cmd.Title,
+		"status: " + string(cmd.Status),
+		"shell: " + string(cmd.Shell),
+		"folder: " + cmd.Folder,
+		"tags: " + strings.Join(cmd.Tags, ", "),
+		"short name: " + cmd.ShortName,
+		"modified: " + cmd.Modified.Format(time.DateTime),
+		"description:",
+	}
+	fields = append(fields, indent(cmd.Description), "script:", indent(cmd.Script))
+	if cmd.PlaceholderSources != "" {
+		fields = append(fields, "placeholders:", indent(cmd.PlaceholderSources))
+	}
+	return strings.Join(fields, "\n")
+}
+
+func indent(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "  " + strings.ReplaceAll(text, "\n", "\n  ")
+}
@@ -0,0 +1,111 @@
+package keys
+
+import "github.com/charmbracelet/bubbles/key"
+
+// ComposerKeyMap contains the keys of the page composing a script from several commands
+type ComposerKeyMap struct {
+	PreviousStep     *key.Binding
+	NextStep         *key.Binding
+	MoveStepUp       *key.Binding
+	MoveStepDown     *key.Binding
+	EditComment      *key.Binding
+	ToggleFunction   *key.Binding
+	RemoveStep       *key.Binding
+	EditTitle        *key.Binding
+	NextErrorHandler *key.Binding
+	PreviousPage     *key.Binding
+	NextPage         *key.Binding
+	Save             *key.Binding
+	Close            *key.Binding
+}
+
+func GetComposerKeyMap() *ComposerKeyMap {
+	previousStep := key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous step"),
+	)
+	nextStep := key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next step"),
+	)
+	moveStepUp := key.NewBinding(
+		key.WithKeys("shift+up", "K"),
+		key.WithHelp("Shift+↑/K", "move step up"),
+	)
+	moveStepDown := key.NewBinding(
+		key.WithKeys("shift+down", "J"),
+		key.WithHelp("Shift+↓/J", "move step down"),
+	)
+	editComment := key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "edit step comment"),
+	)
+	toggleFunction := key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "wrap step in a function"),
+	)
+	removeStep := key.NewBinding(
+		key.WithKeys("x", "delete"),
+		key.WithHelp("x/del", "remove step"),
+	)
+	editTitle := key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "edit title"),
+	)
+	nextErrorHandler := key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "change error handling"),
+	)
+	previousPage := key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("⇞", "scroll preview up"),
+	)
+	nextPage := key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("⇟", "scroll preview down"),
+	)
+	save := key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("Ctrl+s", "save composed command"),
+	)
+	closeKey := key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("␛", "back to commands"),
+	)
+	return &ComposerKeyMap{
+		PreviousStep:     &previousStep,
+		NextStep:         &nextStep,
+		MoveStepUp:       &moveStepUp,
+		MoveStepDown:     &moveStepDown,
+		EditComment:      &editComment,
+		ToggleFunction:   &toggleFunction,
+		RemoveStep:       &removeStep,
+		EditTitle:        &editTitle,
+		NextErrorHandler: &nextErrorHandler,
+		PreviousPage:     &previousPage,
+		NextPage:         &nextPage,
+		Save:             &save,
+		Close:            &closeKey,
+	}
+}
+
+// TextPromptKeyMap contains the keys of the prompt asking a single value
+type TextPromptKeyMap struct {
+	Submit *key.Binding
+	Cancel *key.Binding
+}
+
+func GetTextPromptKeyMap() *TextPromptKeyMap {
+	submit := key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "validate"),
+	)
+	cancel := key.NewBinding(
+		key.WithKeys("esc", "ctrl+c"),
+		key.WithHelp("␛", "cancel"),
+	)
+	return &TextPromptKeyMap{
+		Submit: &submit,
+		Cancel: &cancel,
+	}
+}
@@ -0,0 +1,54 @@
+package keys
+
+import "github.com/charmbracelet/bubbles/key"
+
+// ConflictsKeyMap contains the keys of the view resolving the sync conflicts
+type ConflictsKeyMap struct {
+	Local    *key.Binding
+	Remote   *key.Binding
+	KeepBoth *key.Binding
+	Previous *key.Binding
+	Next     *key.Binding
+	Confirm  *key.Binding
+	Abort    *key.Binding
+}
+
+func GetConflictsKeyMap() *ConflictsKeyMap {
+	local := key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "keep local"),
+	)
+	remote := key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "keep remote"),
+	)
+	keepBoth := key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "keep both"),
+	)
+	previous := key.NewBinding(
+		key.WithKeys("up", "k", "shift+tab"),
+		key.WithHelp("↑", "previous conflict"),
+	)
+	next := key.NewBinding(
+		key.WithKeys("down", "j", "tab"),
+		key.WithHelp("↓", "next conflict"),
+	)
+	confirm := key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "apply the resolutions"),
+	)
+	abort := key.NewBinding(
+		key.WithKeys("esc", "ctrl+c"),
+		key.WithHelp("␛", "abort the sync"),
+	)
+	return &ConflictsKeyMap{
+		Local:    &local,
+		Remote:   &remote,
+		KeepBoth: &keepBoth,
+		Previous: &previous,
+		Next:     &next,
+		Confirm:  &confirm,
+		Abort:    &abort,
+	}
+}
@@ -9,6 +9,8 @@ type EditorKeyMap struct {
 	NextPage      *key.Binding
 	Save          *key.Binding
 	Cancel        *key.Binding
+	PreviewFix    *key.Binding
+	ApplyFix      *key.Binding
 }
 
 // HelpBindings returns the key bindings for this model
@@ -37,6 +39,14 @@ func GetDefaultEditorKeyMap() *EditorKeyMap {
 		key.WithKeys("pgdown"),
 		key.WithHelp("⇟", "next page"),
 	)
+	previewFix := key.NewBinding(
+		key.WithKeys("f7"),
+		key.WithHelp("F7", "preview lint fixes"),
+	)
+	applyFix := key.NewBinding(
+		key.WithKeys("f8"),
+		key.WithHelp("F8", "apply previewed lint fixes"),
+	)
 
 	return &EditorKeyMap{
 		PreviousField: &previousField,
@@ -45,5 +55,7 @@ func GetDefaultEditorKeyMap() *EditorKeyMap {
 		Cancel:        &cancelKey,
 		PreviousPage:  &previousPage,
 		NextPage:      &nextPage,
+		PreviewFix:    &previewFix,
+		ApplyFix:      &applyFix,
 	}
 }
@@ -5,10 +5,11 @@ import (
 )
 
 type GlobalKeyMap struct {
-	Search *key.Binding
-	Quit   *key.Binding
-	Help   *key.Binding
-	Debug  *key.Binding
+	Search  *key.Binding
+	History *key.Binding
+	Quit    *key.Binding
+	Help    *key.Binding
+	Debug   *key.Binding
 }
 
 func GetGlobalKeyMap() *GlobalKeyMap {
@@ -16,6 +17,10 @@ func GetGlobalKeyMap() *GlobalKeyMap {
 		key.WithKeys("ctrl+f", "f3"),
 		key.WithHelp("Ctrl+f/F3", "search"),
 	)
+	history := key.NewBinding(
+		key.WithKeys("ctrl+t", "f4"),
+		key.WithHelp("Ctrl+t/F4", "execution history"),
+	)
 	quit := key.NewBinding(
 		key.WithKeys("esc", "ctrl+c"),
 		key.WithHelp("␛/Ctrl+c", "exit"),
@@ -30,9 +35,10 @@ func GetGlobalKeyMap() *GlobalKeyMap {
 	)
 
 	return &GlobalKeyMap{
-		Search: &search,
-		Quit:   &quit,
-		Help:   &help,
-		Debug:  &debug,
+		Search:  &search,
+		History: &history,
+		Quit:    &quit,
+		Help:    &help,
+		Debug:   &debug,
 	}
 }
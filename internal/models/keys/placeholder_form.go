@@ -0,0 +1,60 @@
+package keys
+
+import "github.com/charmbracelet/bubbles/key"
+
+// PlaceholderFormKeyMap contains the keys of the form filling the placeholders of a command
+type PlaceholderFormKeyMap struct {
+	PreviousField *key.Binding
+	NextField     *key.Binding
+	PreviousValue *key.Binding
+	NextValue     *key.Binding
+	Submit        *key.Binding
+	Cancel        *key.Binding
+}
+
+func GetPlaceholderFormKeyMap() *PlaceholderFormKeyMap {
+	previousField := key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("Shift+⭾", "previous placeholder"),
+	)
+	nextField := key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("⭾", "next placeholder"),
+	)
+	previousValue := key.NewBinding(
+		key.WithKeys("up", "ctrl+p"),
+		key.WithHelp("↑/Ctrl+p", "previous suggestion"),
+	)
+	nextValue := key.NewBinding(
+		key.WithKeys("down", "ctrl+n"),
+		key.WithHelp("↓/Ctrl+n", "next suggestion"),
+	)
+	submit := key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "validate"),
+	)
+	cancel := key.NewBinding(
+		key.WithKeys("esc", "ctrl+c"),
+		key.WithHelp("␛", "cancel"),
+	)
+	return &PlaceholderFormKeyMap{
+		PreviousField: &previousField,
+		NextField:     &nextField,
+		PreviousValue: &previousValue,
+		NextValue:     &nextValue,
+		Submit:        &submit,
+		Cancel:        &cancel,
+	}
+}
+
+// GetBindings returns the bindings displayed in the help
+func (k *PlaceholderFormKeyMap) GetBindings() []*key.Binding {
+	return []*key.Binding{
+		k.NextField,
+		k.PreviousField,
+		k.NextValue,
+		k.PreviousValue,
+		k.Submit,
+		k.Cancel,
+	}
+}
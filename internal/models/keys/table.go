@@ -11,6 +11,12 @@ type TableCustomActionKeyMap struct {
 	CopyToClipboard *key.Binding
 	SelectForShell  *key.Binding
 	RestoreCommand  *key.Binding
+	RelintCommand   *key.Binding
+	RelintCategory  *key.Binding
+	RelintAll       *key.Binding
+	RunCommand      *key.Binding
+	ExportScript    *key.Binding
+	CopyToBookmarks *key.Binding
 }
 
 func GetTableCustomActionKeyMap() *TableCustomActionKeyMap {
@@ -30,12 +36,43 @@ func GetTableCustomActionKeyMap() *TableCustomActionKeyMap {
 		key.WithKeys("r"),
 		key.WithHelp("r", "restore command"),
 	)
+	relintCommand := key.NewBinding(
+		key.WithKeys("l"),
+		key.WithHelp("l", "lint again selected commands"),
+	)
+	relintCategory := key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "lint again commands of the tab"),
+	)
+	relintAll := key.NewBinding(
+		key.WithKeys("ctrl+l"),
+		key.WithHelp("Ctrl+l", "lint again all commands"),
+	)
+
+	runCommand := key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "run command"),
+	)
+	exportScript := key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "export as script"),
+	)
+	copyToBookmarks := key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "copy team command to my bookmarks"),
+	)
 
 	return &TableCustomActionKeyMap{
 		ComposeCommand:  &composeCommand,
 		CopyToClipboard: &copyToClipboard,
 		SelectForShell:  &selectForShell,
 		RestoreCommand:  &restoreCommand,
+		RelintCommand:   &relintCommand,
+		RelintCategory:  &relintCategory,
+		RelintAll:       &relintAll,
+		RunCommand:      &runCommand,
+		ExportScript:    &exportScript,
+		CopyToBookmarks: &copyToBookmarks,
 	}
 }
 
@@ -70,9 +107,29 @@ func UpdateBindings(
 			selectedCommand != nil &&
 			selectedCommand.Status == dbmodels.CommandStatusDeleted,
 	)
+	tableCustomActions.RelintCommand.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil && selectedCommand.Source == "",
+	)
+	tableCustomActions.RelintCategory.SetEnabled(
+		!shellSelectionMode && (selectedCommand == nil || selectedCommand.Source == ""),
+	)
+	tableCustomActions.RelintAll.SetEnabled(!shellSelectionMode)
+	// the runs are recorded with the command id, the ids of the team commands
+	// change at each reload
+	tableCustomActions.RunCommand.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil && selectedCommand.Source == "",
+	)
+	tableCustomActions.ExportScript.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil,
+	)
+	// the commands of the team catalogs are read-only, they can only be copied
+	tableCustomActions.CopyToBookmarks.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil && selectedCommand.Source != "",
+	)
 	tableActions.Delete.SetEnabled(
 		!shellSelectionMode &&
 			selectedCommand != nil &&
+			selectedCommand.Source == "" &&
 			selectedCommand.Status != dbmodels.CommandStatusDeleted,
 	)
 	tableActions.Select.SetEnabled(selectedCommand != nil)
@@ -0,0 +1,57 @@
+package keys
+
+import "github.com/charmbracelet/bubbles/key"
+
+// TaskKeyMap contains the keys of the pane displaying a command execution
+type TaskKeyMap struct {
+	Cancel *key.Binding
+	Rerun  *key.Binding
+	Close  *key.Binding
+}
+
+func GetTaskKeyMap() *TaskKeyMap {
+	cancel := key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "cancel run"),
+	)
+	closeKey := key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("␛", "close"),
+	)
+	return &TaskKeyMap{
+		Cancel: &cancel,
+		Rerun:  getRerunBinding(),
+		Close:  &closeKey,
+	}
+}
+
+// TaskListKeyMap contains the keys of the execution history
+type TaskListKeyMap struct {
+	Rerun *key.Binding
+	Diff  *key.Binding
+	Close *key.Binding
+}
+
+func GetTaskListKeyMap() *TaskListKeyMap {
+	diff := key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "diff outputs of 2 executions"),
+	)
+	closeKey := key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("␛", "back to commands"),
+	)
+	return &TaskListKeyMap{
+		Rerun: getRerunBinding(),
+		Diff:  &diff,
+		Close: &closeKey,
+	}
+}
+
+func getRerunBinding() *key.Binding {
+	rerun := key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "run again"),
+	)
+	return &rerun
+}
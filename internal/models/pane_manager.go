@@ -186,16 +186,37 @@ func (p *PaneManager) handleSpecialMessages(msg tea.Msg) (tea.Cmd, bool) {
 	case structure.NavigationMsg:
 		return p.setPane(msg), true
 	case table.RowDefaultActionMsg[*models.Command]:
-		return p.setBottomPane(msg.RowID, true), true
+		return p.setBottomPane(structure.CommandEditorKind, msg.RowID, true), true
 	case table.RowSelectedActionMsg[*models.Command]:
-		if _, ok := p.panes[structure.BottomPane]; ok {
-			cmd := p.setBottomPane(msg.RowID, false)
+		// only the command editor follows the selected row
+		if p.isBottomPaneKind(structure.CommandEditorKind) {
+			cmd := p.setBottomPane(structure.CommandEditorKind, msg.RowID, false)
+			return cmd, cmd != nil
+		}
+	case table.RowDefaultActionMsg[*models.Execution]:
+		return p.setBottomPane(structure.TaskKind, msg.RowID, true), true
+	case table.RowSelectedActionMsg[*models.Execution]:
+		// the displayed execution follows the selected row
+		if p.isBottomPaneKind(structure.TaskKind) && msg.RowID != resource.ID(0) {
+			cmd := p.setBottomPane(structure.TaskKind, msg.RowID, false)
 			return cmd, cmd != nil
 		}
 	case command.EditorCancelledMsg:
 		// The command editor was cancelled, so we need to close the bottom pane
 		// and focus the top pane.
 		return p.closeFocusedPane(), true
+	case structure.TasksCompletedMsg:
+		// every pane can display a result of the background tasks
+		cmds := []tea.Cmd{p.updateModel(p.focused, msg)}
+		cmds = append(cmds, p.updateUnfocusedPanes(msg)...)
+		return tea.Batch(cmds...), true
+	case structure.CloseFocusedPaneMsg:
+		return p.closeFocusedPane(), true
+	case structure.PageMsg:
+		if model := p.cache.Get(msg.GetPage()); model != nil {
+			return model.Update(msg), true
+		}
+		return nil, true
 	}
 
 	return nil, false
@@ -297,10 +318,16 @@ func (p *PaneManager) handleFocusPane(position structure.Position) tea.Cmd {
 	return p.focusPane(position)
 }
 
-func (p *PaneManager) setBottomPane(rowID resource.ID, focusIfSameRowID bool) tea.Cmd {
-	// Handle row default action by opening the editor in the bottom right pane
+// isBottomPaneKind returns true if the bottom pane displays a page of the given kind
+func (p *PaneManager) isBottomPaneKind(kind resource.Kind) bool {
+	bottomPane, ok := p.panes[structure.BottomPane]
+	return ok && bottomPane.page.Kind == kind
+}
+
+func (p *PaneManager) setBottomPane(kind resource.Kind, rowID resource.ID, focusIfSameRowID bool) tea.Cmd {
+	// Handle row default action by opening the page of the row in the bottom right pane
 	bottomPane := p.panes[structure.BottomPane]
-	if bottomPane.page.ID == rowID {
+	if bottomPane.page.Kind == kind && bottomPane.page.ID == rowID {
 		var cmd tea.Cmd
 		// The bottom right pane is already showing the editor for this command
 		// so just bring it into focus.
@@ -312,7 +339,7 @@ func (p *PaneManager) setBottomPane(rowID resource.ID, focusIfSameRowID bool) te
 	return p.setPane(
 		structure.NavigationMsg{
 			Page: structure.Page{
-				Kind: structure.CommandEditorKind,
+				Kind: kind,
 				ID:   rowID,
 			},
 			Position:     structure.BottomPane,
@@ -9,6 +9,7 @@ const (
 	FieldScript           Field = "Script"
 	FieldStatus           Field = "Status"
 	FieldLintStatus       Field = "Lint Status"
+	FieldRisk             Field = "Risk"
 	FieldCreationDate     Field = "Creation Date"
 	FieldModificationDate Field = "Modification Date"
 	FieldFilterScore      Field = "Score"
@@ -16,6 +16,8 @@ var (
 	CommandListKind   = KindType{key: "commandList"}
 	CommandEditorKind = KindType{key: "commandEditor"}
 	TaskKind          = KindType{key: "task"}
+	TaskListKind      = KindType{key: "taskList"}
+	ComposerKind      = KindType{key: "composer"}
 	FolderKind        = KindType{key: "folder"}
 	SearchKind        = KindType{key: "search"}
 )
@@ -1,6 +1,7 @@
 package structure
 
 import (
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 )
 
@@ -42,12 +43,28 @@ func WithPosition(position Position) NavigateOption {
 	}
 }
 
+// PageMsg is a message sent only to the model of a page, even if the
+// page is not visible
+type PageMsg interface {
+	GetPage() Page
+}
+
+// CloseFocusedPaneMsg is sent by a model to close the pane displaying it
+type CloseFocusedPaneMsg struct{}
+
 type FocusedPaneChangedMsg struct {
 	From Position
 	To   Position
 }
 
+// TasksCompletedMsg is sent to all the panes when the queued background tasks
+// are completed, the commands they have updated can be reloaded
+type TasksCompletedMsg struct{}
+
 // CommandSelectedForShellMsg is sent when a command is selected for pasting to shell
 type CommandSelectedForShellMsg struct {
-	Command string
+	// Selected is the command selected, Command is its script with the
+	// placeholders filled
+	Selected *dbmodels.Command
+	Command  string
 }
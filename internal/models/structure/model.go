@@ -32,6 +32,9 @@ type KeyMaps struct {
 	TableAction       *table.Action
 	TableCustomAction *keys.TableCustomActionKeyMap
 	Editor            *keys.EditorKeyMap
+	Task              *keys.TaskKeyMap
+	TaskList          *keys.TaskListKeyMap
+	Composer          *keys.ComposerKeyMap
 	Form              *huh.KeyMap
 }
 
@@ -38,6 +38,7 @@ type FooterStyle struct {
 	DefaultStyle *lipgloss.Style
 	Main         *lipgloss.Style
 	Version      *lipgloss.Style
+	Tasks        *lipgloss.Style
 	Height       int
 }
 
@@ -122,6 +123,8 @@ type EditorStyle struct {
 	StatusWarning  *lipgloss.Style
 	StatusError    *lipgloss.Style
 	StatusDisabled *lipgloss.Style
+	// SourceBadge is the style of the name of the team catalog of a command
+	SourceBadge    *lipgloss.Style
 	ScrollbarStyle *tui.ScrollbarStyle
 	ContentPadding int
 }
@@ -278,6 +281,7 @@ func (s *Styles) initBaseStyles(colorTheme *ColorTheme) {
 		Foreground(colors.White)
 	footerInfoStyle := padded.Foreground(colors.Black).Background(colors.LightGreen)
 	versionStyle := padded.Background(colors.DarkGrey).Foreground(colors.White)
+	tasksStyle := padded.Background(colors.Blue).Foreground(colors.White)
 	s.FooterStyle = &FooterStyle{
 		Height:       HeightFooter,
 		DefaultStyle: &footerDefaultStyle,
@@ -285,6 +289,7 @@ func (s *Styles) initBaseStyles(colorTheme *ColorTheme) {
 		InfoStyle:    &footerInfoStyle,
 		Main:         &footerInline,
 		Version:      &versionStyle,
+		Tasks:        &tasksStyle,
 	}
 
 	// Initialize header style
@@ -344,6 +349,7 @@ func (s *Styles) initComponentStyles(colorTheme *ColorTheme) {
 	statusWarningStyle := regular.Foreground(colors.Yellow)
 	statusErrorStyle := regular.Foreground(colors.Red)
 	statusDisabledStyle := regular.Foreground(colors.DarkGrey)
+	sourceBadgeStyle := bold.Foreground(colors.White).Background(colors.Blue)
 
 	s.EditorStyle = &EditorStyle{
 		Title:           &titleStyle,
@@ -358,6 +364,7 @@ func (s *Styles) initComponentStyles(colorTheme *ColorTheme) {
 		StatusWarning:   &statusWarningStyle,
 		StatusError:     &statusErrorStyle,
 		StatusDisabled:  &statusDisabledStyle,
+		SourceBadge:     &sourceBadgeStyle,
 		ScrollbarStyle:  s.ScrollbarStyle,
 	}
 
@@ -0,0 +1,26 @@
+package task
+
+import "fmt"
+
+// ErrRerunExecution is returned when an execution cannot be run again
+type ErrRerunExecution struct {
+	Err error
+}
+
+func (e *ErrRerunExecution) Error() string {
+	return fmt.Sprintf("failed to run the execution again: %v", e.Err)
+}
+
+// ErrNoExecutionSelected is returned when no execution is selected for an operation
+type ErrNoExecutionSelected struct{}
+
+func (*ErrNoExecutionSelected) Error() string {
+	return "no execution selected"
+}
+
+// ErrDiffSelection is returned when the executions to compare cannot be determined
+type ErrDiffSelection struct{}
+
+func (*ErrDiffSelection) Error() string {
+	return "select 2 executions to compare, or an execution of a command run at least twice"
+}
@@ -0,0 +1,285 @@
+package task
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
+)
+
+const (
+	idColumnPercentWidth        = 5
+	commandColumnPercentWidth   = 7
+	scriptColumnPercentWidth    = 35
+	statusColumnPercentWidth    = 21
+	startedColumnPercentWidth   = 21
+	elapsedColumnPercentWidth   = 11
+	percent                     = 100
+	sidesCount                  = 2
+	diffExecutionsCount         = 2
+	columnKeyID                 = table.ColumnKey(structure.FieldID)
+	columnKeyCommand            = table.ColumnKey("Command")
+	columnKeyScript             = table.ColumnKey(structure.FieldScript)
+	columnKeyStatus             = table.ColumnKey(structure.FieldStatus)
+	columnKeyStartDatetime      = table.ColumnKey("Started")
+	columnKeyElapsed            = table.ColumnKey("Elapsed")
+	elapsedColumnRoundingPeriod = time.Millisecond
+)
+
+type ListMaker struct {
+	App              *services.AppService
+	Styles           *styles.Styles
+	NavigationKeyMap *table.Navigation
+	ActionKeyMap     *table.Action
+	TaskListKeyMap   *keys.TaskListKeyMap
+}
+
+// noEditorsCache is used by the history as executions cannot be edited
+type noEditorsCache struct{}
+
+func (noEditorsCache) Get(resource.ID) table.EditorInterface {
+	return nil
+}
+
+// Make creates the execution history, the most recent execution first
+func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildModel, error) {
+	m := &executionList{
+		Model:            nil,
+		executionService: mm.App.ExecutionService,
+		dangerService:    mm.App.DangerService,
+		styles:           mm.Styles,
+		keyMap:           mm.TaskListKeyMap,
+		executions:       []*dbmodels.Execution{},
+		columns: []table.Column{
+			newColumn(columnKeyID, "Id", table.NoTruncate),
+			newColumn(columnKeyCommand, "Command", table.NoTruncate),
+			newColumn(columnKeyScript, "Script", table.GetDefaultTruncationFunc()),
+			newColumn(columnKeyStatus, "Status", table.GetDefaultTruncationFunc()),
+			newColumn(columnKeyStartDatetime, "Started", table.NoTruncate),
+			newColumn(columnKeyElapsed, "Elapsed", table.NoTruncate),
+		},
+		width:  width,
+		height: height,
+	}
+	renderer := func(execution *dbmodels.Execution) table.RenderedRow {
+		return m.renderRow(execution)
+	}
+	cellRenderer := func(_ *dbmodels.Execution, cellContent string, _ int, _ bool) string {
+		return cellContent
+	}
+	headerCellRenderer := func(cellContent string, _ int) string {
+		return cellContent
+	}
+	tbl := table.New(
+		noEditorsCache{},
+		mm.Styles.TableStyle,
+		m.columns,
+		renderer,
+		cellRenderer,
+		headerCellRenderer,
+		width,
+		height,
+		table.WithSortFunc(func(a, b *dbmodels.Execution) int {
+			return cmp.Compare(b.ID, a.ID)
+		}),
+		table.WithPreview[*dbmodels.Execution](structure.TaskKind),
+		table.WithNavigation[*dbmodels.Execution](mm.NavigationKeyMap),
+		table.WithAction[*dbmodels.Execution](mm.ActionKeyMap),
+	)
+	m.Model = &tbl
+	m.computeColumnsWidth()
+	return m, nil
+}
+
+func newColumn(key table.ColumnKey, title string, truncationFunc table.TruncationFunc) table.Column {
+	return table.Column{
+		Key:            key,
+		Title:          title,
+		FlexFactor:     0,
+		Width:          0,
+		TruncationFunc: truncationFunc,
+		RightAlign:     false,
+	}
+}
+
+// executionList is the table of the executions stored in the database
+type executionList struct {
+	Model            *table.Model[*dbmodels.Execution]
+	executionService *services.ExecutionService
+	dangerService    *services.DangerService
+	styles           *styles.Styles
+	keyMap           *keys.TaskListKeyMap
+	// executions are the executions loaded in the table
+	executions []*dbmodels.Execution
+	columns    []table.Column
+	width      int
+	height     int
+}
+
+func (m *executionList) renderRow(execution *dbmodels.Execution) table.RenderedRow {
+	editorStyle := m.styles.EditorStyle
+	elapsed := ""
+	if !execution.EndDatetime.IsZero() {
+		elapsed = execution.GetElapsed().Round(elapsedColumnRoundingPeriod).String()
+	}
+	return table.RenderedRow{
+		columnKeyID:            strconv.Itoa(int(execution.ID)),
+		columnKeyCommand:       fmt.Sprintf("#%d", execution.CommandID),
+		columnKeyScript:        strings.SplitN(execution.Script, "\n", 2)[0],
+		columnKeyStatus:        viewStatus(editorStyle, execution),
+		columnKeyStartDatetime: execution.StartDatetime.Format(time.DateTime),
+		columnKeyElapsed:       elapsed,
+	}
+}
+
+func (m *executionList) computeColumnsWidth() {
+	percentWidths := []int{
+		idColumnPercentWidth,
+		commandColumnPercentWidth,
+		scriptColumnPercentWidth,
+		statusColumnPercentWidth,
+		startedColumnPercentWidth,
+		elapsedColumnPercentWidth,
+	}
+	w := m.width - len(m.columns)*m.styles.TableStyle.GetTableCellStyle().GetHorizontalPadding()*sidesCount
+	for i := range m.columns {
+		m.columns[i].Width = percentWidths[i] * w / percent
+	}
+	m.Model.SetColumns(m.columns)
+}
+
+func (m *executionList) Init() tea.Cmd {
+	return m.reload(0)
+}
+
+func (*executionList) BeforeSwitchPane() tea.Cmd {
+	return nil
+}
+
+// reload reads the executions, selectID is the execution to select, 0 to keep the selection
+func (m *executionList) reload(selectID resource.ID) tea.Cmd {
+	return func() tea.Msg {
+		executions, err := m.executionService.GetExecutions()
+		if err != nil {
+			return tui.ErrorMsg(err)
+		}
+		return table.BulkInsertMsg[*dbmodels.Execution]{
+			Items:       executions,
+			InfoMsg:     fmt.Sprintf("Loaded %d execution(s)", len(executions)),
+			SelectRowID: selectID,
+		}
+	}
+}
+
+func (m *executionList) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.computeColumnsWidth()
+	case structure.NavigationMsg:
+		return m.reload(0)
+	case ExecutionsChangedMsg:
+		return m.reload(msg.SelectID)
+	case table.BulkInsertMsg[*dbmodels.Execution]:
+		m.executions = msg.Items
+	case table.ReloadMsg[*dbmodels.Execution]:
+		return m.reload(msg.RowID)
+	case tea.FocusMsg:
+		m.Model.Focus()
+	case tea.BlurMsg:
+		m.Model.Blur()
+	case tea.KeyMsg:
+		if cmd := m.handleKeyMsg(msg); cmd != nil {
+			return cmd
+		}
+	}
+	return m.Model.Update(msg)
+}
+
+func (m *executionList) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case tui.CheckKey(msg, m.keyMap.Rerun):
+		execution, ok := m.Model.CurrentRow()
+		if !ok {
+			return tui.ReportError(&ErrNoExecutionSelected{})
+		}
+		return rerun(m.executionService, m.dangerService, execution)
+	case tui.CheckKey(msg, m.keyMap.Diff):
+		return m.handleDiff()
+	case tui.CheckKey(msg, m.keyMap.Close):
+		return tui.CmdHandler(structure.NewNavigationMsg(
+			structure.CommandListKind, structure.WithPosition(structure.TopPane),
+		))
+	}
+	return nil
+}
+
+// handleDiff compares the outputs of the 2 selected executions, or the
+// output of the current execution with the output of the previous run of
+// the same command
+func (m *executionList) handleDiff() tea.Cmd {
+	executions := m.Model.SelectedOrCurrent()
+	if len(executions) == 1 {
+		if previous := m.findPreviousExecution(executions[0]); previous != nil {
+			executions = append(executions, previous)
+		}
+	}
+	if len(executions) != diffExecutionsCount {
+		return tui.ReportError(&ErrDiffSelection{})
+	}
+	before, after := executions[0], executions[1]
+	if before.ID > after.ID {
+		before, after = after, before
+	}
+	m.Model.DeselectAll()
+	return tea.Sequence(
+		navigateToExecution(after.ID),
+		tui.CmdHandler(ShowDiffMsg{ID: after.ID, BaseID: before.ID}),
+	)
+}
+
+// findPreviousExecution returns the most recent execution of the same
+// command started before the given execution, nil if none
+func (m *executionList) findPreviousExecution(execution *dbmodels.Execution) *dbmodels.Execution {
+	var previous *dbmodels.Execution
+	for _, row := range m.executions {
+		if row.CommandID == execution.CommandID && row.ID < execution.ID &&
+			(previous == nil || row.ID > previous.ID) {
+			previous = row
+		}
+	}
+	return previous
+}
+
+func (m *executionList) View() string {
+	return m.Model.View()
+}
+
+// BorderText returns text to display in the border
+func (*executionList) BorderText() map[styles.BorderPosition]string {
+	return map[styles.BorderPosition]string{
+		styles.TopMiddleBorder: "Execution history",
+	}
+}
+
+// HelpBindings returns the keys of the pane displayed in the help
+func (m *executionList) HelpBindings() []*key.Binding {
+	return []*key.Binding{
+		m.keyMap.Rerun,
+		m.keyMap.Diff,
+		m.keyMap.Close,
+	}
+}
@@ -0,0 +1,411 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/diff"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	pkgTask "github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+const (
+	// elapsedRefreshInterval is the refresh interval of the elapsed time of a running command
+	elapsedRefreshInterval = time.Second
+	// headerHeight is the number of lines displayed above the output
+	headerHeight = 4
+)
+
+// RunUpdatedMsg is sent when the output or the status of a run changes
+type RunUpdatedMsg struct {
+	RunID resource.ID
+}
+
+func (msg RunUpdatedMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.TaskKind, ID: msg.RunID}
+}
+
+// runTickMsg refreshes the elapsed time of a running command
+type runTickMsg struct {
+	RunID resource.ID
+}
+
+func (msg runTickMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.TaskKind, ID: msg.RunID}
+}
+
+// ShowDiffMsg displays the differences between the output of the
+// execution BaseID and the output of the displayed execution ID
+type ShowDiffMsg struct {
+	ID     resource.ID
+	BaseID resource.ID
+}
+
+func (msg ShowDiffMsg) GetPage() structure.Page {
+	return structure.Page{Kind: structure.TaskKind, ID: msg.ID}
+}
+
+// ExecutionsChangedMsg is sent when an execution starts or finishes,
+// SelectID is the execution to select in the history, 0 to keep the selection
+type ExecutionsChangedMsg struct {
+	SelectID resource.ID
+}
+
+// navigateToExecution displays the execution in the bottom pane
+func navigateToExecution(id resource.ID) tea.Cmd {
+	return tui.CmdHandler(structure.NavigationMsg{
+		Page:         structure.Page{Kind: structure.TaskKind, ID: id},
+		Position:     structure.BottomPane,
+		DisableFocus: false,
+	})
+}
+
+// rerun runs the execution again and displays the new execution, a
+// confirmation naming the risks is asked first if the script is dangerous
+func rerun(
+	executionService *services.ExecutionService,
+	dangerService *services.DangerService,
+	execution *dbmodels.Execution,
+) tea.Cmd {
+	id := execution.ID
+	runAgain := func() tea.Cmd {
+		run, err := executionService.Rerun(id)
+		if err != nil {
+			return tui.ReportError(&ErrRerunExecution{Err: err})
+		}
+		return tea.Batch(
+			navigateToExecution(run.ID),
+			tui.CmdHandler(ExecutionsChangedMsg{SelectID: run.ID}),
+		)
+	}
+	dialect, ok := dbmodels.ParseShellDialect(execution.Shell)
+	if !ok {
+		dialect = dbmodels.ShellDialectBash
+	}
+	dangers := dangerService.Classify(execution.Script, dialect)
+	if len(dangers) == 0 {
+		return runAgain()
+	}
+	return tui.YesNoPrompt(
+		fmt.Sprintf("Execution #%d is risky:\n%s\nRun it again?", id, services.DescribeDangers(dangers)),
+		keys.GetFormKeyMap(),
+		runAgain,
+	)
+}
+
+type RunMaker struct {
+	App        *services.AppService
+	Styles     *styles.Styles
+	TaskKeyMap *keys.TaskKeyMap
+}
+
+// Make creates the pane displaying the execution having the given id
+func (mm *RunMaker) Make(id resource.ID, width, height int) (structure.ChildModel, error) {
+	output := viewport.New(width, max(0, height-headerHeight))
+	output.KeyMap = getOutputKeyMap()
+	m := &runPane{
+		executionService: mm.App.ExecutionService,
+		dangerService:    mm.App.DangerService,
+		styles:           mm.Styles,
+		keyMap:           mm.TaskKeyMap,
+		output:           &output,
+		execution:        nil,
+		diffBase:         nil,
+		waiting:          make(map[resource.ID]bool),
+		width:            width,
+		height:           height,
+		ticking:          false,
+	}
+	if err := m.setExecution(id); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// getOutputKeyMap returns the keys scrolling the output, the other
+// viewport keys are used by the application
+func getOutputKeyMap() viewport.KeyMap {
+	return viewport.KeyMap{
+		PageDown:     key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("⇟", "page down")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup"), key.WithHelp("⇞", "page up")),
+		HalfPageUp:   key.NewBinding(key.WithDisabled()),
+		HalfPageDown: key.NewBinding(key.WithDisabled()),
+		Up:           key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "scroll up")),
+		Down:         key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "scroll down")),
+		Left:         key.NewBinding(key.WithDisabled()),
+		Right:        key.NewBinding(key.WithDisabled()),
+	}
+}
+
+// runPane displays the output of a command execution, the output is
+// refreshed while the command is running
+type runPane struct {
+	executionService *services.ExecutionService
+	dangerService    *services.DangerService
+	styles           *styles.Styles
+	keyMap           *keys.TaskKeyMap
+	output           *viewport.Model
+	execution        *dbmodels.Execution
+	// diffBase is the execution whose output is compared to the displayed one, nil if none
+	diffBase *dbmodels.Execution
+	// waiting tracks the runs having a pending WaitForUpdate
+	waiting map[resource.ID]bool
+	width   int
+	height  int
+	ticking bool
+}
+
+func (m *runPane) setExecution(id resource.ID) error {
+	execution, err := m.executionService.GetExecution(id)
+	if err != nil {
+		return err
+	}
+	m.execution = execution
+	m.diffBase = nil
+	m.refreshOutput(true)
+	return nil
+}
+
+func (m *runPane) Init() tea.Cmd {
+	return tea.Batch(m.waitForUpdate(), m.tick())
+}
+
+func (*runPane) BeforeSwitchPane() tea.Cmd {
+	return nil
+}
+
+//nolint:cyclop // not really complex
+func (m *runPane) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.output.Width = msg.Width
+		m.output.Height = max(0, msg.Height-headerHeight)
+		m.refreshOutput(false)
+	case structure.NavigationMsg:
+		if err := m.setExecution(msg.Page.ID); err != nil {
+			return tui.ReportError(err)
+		}
+		return tea.Batch(m.waitForUpdate(), m.tick())
+	case ShowDiffMsg:
+		return m.showDiff(msg)
+	case RunUpdatedMsg:
+		m.waiting[msg.RunID] = false
+		if msg.RunID == m.execution.ID {
+			return m.refresh()
+		}
+	case runTickMsg:
+		m.ticking = false
+		return m.tick()
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	}
+	return nil
+}
+
+func (m *runPane) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case tui.CheckKey(msg, m.keyMap.Cancel):
+		run, ok := m.executionService.GetRun(m.execution.ID)
+		if !ok || run.GetStatus().IsFinal() {
+			return tui.ReportInfo("Execution #%d is already finished", m.execution.ID)
+		}
+		run.Cancel()
+		return tui.ReportInfo("Execution #%d canceled", m.execution.ID)
+	case tui.CheckKey(msg, m.keyMap.Rerun):
+		return rerun(m.executionService, m.dangerService, m.execution)
+	case tui.CheckKey(msg, m.keyMap.Close):
+		return tui.CmdHandler(structure.CloseFocusedPaneMsg{})
+	}
+	previousOffset := m.output.YOffset
+	_, cmd := m.output.Update(msg)
+	if cmd == nil && previousOffset != m.output.YOffset {
+		// the key has been used to scroll
+		return tui.GetDummyCmd()
+	}
+	return cmd
+}
+
+func (m *runPane) showDiff(msg ShowDiffMsg) tea.Cmd {
+	if msg.ID != m.execution.ID {
+		if err := m.setExecution(msg.ID); err != nil {
+			return tui.ReportError(err)
+		}
+	}
+	base, err := m.executionService.GetExecution(msg.BaseID)
+	if err != nil {
+		return tui.ReportError(err)
+	}
+	m.diffBase = base
+	m.refreshOutput(true)
+	m.output.GotoTop()
+	return nil
+}
+
+// refresh reads again the displayed execution, the history is notified
+// when the execution is finished
+func (m *runPane) refresh() tea.Cmd {
+	execution, err := m.executionService.GetExecution(m.execution.ID)
+	if err != nil {
+		return tui.ReportError(err)
+	}
+	m.execution = execution
+	m.refreshOutput(false)
+	if execution.Status.IsFinal() {
+		return tui.CmdHandler(ExecutionsChangedMsg{SelectID: 0})
+	}
+	return m.waitForUpdate()
+}
+
+// waitForUpdate waits for the next change of the displayed execution if it is running
+func (m *runPane) waitForUpdate() tea.Cmd {
+	run, ok := m.executionService.GetRun(m.execution.ID)
+	if !ok || m.waiting[run.ID] || m.execution.Status.IsFinal() {
+		return nil
+	}
+	m.waiting[run.ID] = true
+	return func() tea.Msg {
+		run.WaitForUpdate()
+		return RunUpdatedMsg{RunID: run.ID}
+	}
+}
+
+// tick refreshes the elapsed time while the displayed execution is running
+func (m *runPane) tick() tea.Cmd {
+	if m.ticking || m.execution.Status.IsFinal() {
+		return nil
+	}
+	m.ticking = true
+	runID := m.execution.ID
+	return tea.Tick(elapsedRefreshInterval, func(time.Time) tea.Msg {
+		return runTickMsg{RunID: runID}
+	})
+}
+
+// refreshOutput updates the output, following its end if it was displayed
+func (m *runPane) refreshOutput(gotoBottom bool) {
+	follow := gotoBottom || m.output.AtBottom()
+	var rendered []string
+	if m.diffBase != nil {
+		rendered = m.renderDiff()
+	} else {
+		rendered = m.renderOutput()
+	}
+	m.output.SetContent(strings.Join(rendered, "\n"))
+	if follow {
+		m.output.GotoBottom()
+	}
+}
+
+func (m *runPane) renderOutput() []string {
+	editorStyle := m.styles.EditorStyle
+	rendered := make([]string, 0, len(m.execution.Output)+1)
+	if m.execution.DroppedLines > 0 {
+		rendered = append(rendered, editorStyle.StatusWarning.Render(
+			fmt.Sprintf("… %d lines dropped", m.execution.DroppedLines),
+		))
+	}
+	for _, line := range m.execution.Output {
+		if line.Stream == dbmodels.OutputStreamStderr {
+			rendered = append(rendered, editorStyle.StatusError.Render(line.Text))
+		} else {
+			rendered = append(rendered, line.Text)
+		}
+	}
+	return rendered
+}
+
+func (m *runPane) renderDiff() []string {
+	editorStyle := m.styles.EditorStyle
+	lines := m.executionService.DiffOutputs(m.diffBase, m.execution)
+	if !diff.HasChanges(lines) {
+		return []string{editorStyle.StatusOK.Render("The outputs are identical")}
+	}
+	rendered := make([]string, 0, len(lines))
+	for _, line := range lines {
+		text := line.Op.Prefix() + line.Text
+		switch line.Op {
+		case diff.Delete:
+			rendered = append(rendered, editorStyle.StatusError.Render(text))
+		case diff.Insert:
+			rendered = append(rendered, editorStyle.StatusOK.Render(text))
+		case diff.Equal:
+			rendered = append(rendered, text)
+		}
+	}
+	return rendered
+}
+
+func (m *runPane) View() string {
+	editorStyle := m.styles.EditorStyle
+	script := strings.SplitN(m.execution.Script, "\n", 2)[0]
+	details := editorStyle.ReadonlyLabel.Render("Started: ") +
+		editorStyle.ReadonlyValue.Render(m.execution.StartDatetime.Format(time.DateTime)) +
+		"  " + editorStyle.ReadonlyLabel.Render("Directory: ") + editorStyle.ReadonlyValue.Render(m.execution.Cwd)
+	if m.diffBase != nil {
+		details = editorStyle.ReadonlyLabel.Render("Diff: ") + editorStyle.StatusError.Render(
+			fmt.Sprintf("- execution #%d", m.diffBase.ID),
+		) + " " + editorStyle.StatusOK.Render(fmt.Sprintf("+ execution #%d", m.execution.ID))
+	}
+	header := []string{
+		editorStyle.ReadonlyLabel.Render("$ ") + editorStyle.ReadonlyValue.Render(script),
+		editorStyle.ReadonlyLabel.Render("Shell: ") + editorStyle.ReadonlyValue.Render(m.execution.Shell) +
+			"  " + editorStyle.ReadonlyLabel.Render("Status: ") + viewStatus(editorStyle, m.execution) +
+			"  " + editorStyle.ReadonlyLabel.Render("Elapsed: ") +
+			editorStyle.ReadonlyValue.Render(m.execution.GetElapsed().Round(time.Millisecond).String()),
+		details,
+		editorStyle.HelpText.Render(strings.Repeat("─", max(0, m.width))),
+	}
+	return strings.Join(header, "\n") + "\n" + m.output.View()
+}
+
+func viewStatus(editorStyle *styles.EditorStyle, execution *dbmodels.Execution) string {
+	status := execution.Status
+	switch status {
+	case pkgTask.Exited:
+		return editorStyle.StatusOK.Render(fmt.Sprintf("%s (exit code %d)", status, execution.ExitCode))
+	case pkgTask.Errored:
+		if execution.Error != "" {
+			return editorStyle.StatusError.Render(fmt.Sprintf("%s: %s", status, execution.Error))
+		}
+		return editorStyle.StatusError.Render(fmt.Sprintf("%s (exit code %d)", status, execution.ExitCode))
+	case pkgTask.Canceled:
+		return editorStyle.StatusWarning.Render(status.String())
+	case pkgTask.Pending, pkgTask.Queued, pkgTask.Running:
+		return editorStyle.StatusDisabled.Render(status.String())
+	default:
+		return editorStyle.StatusDisabled.Render(status.String())
+	}
+}
+
+// BorderText returns text to display in the border
+func (m *runPane) BorderText() map[styles.BorderPosition]string {
+	return map[styles.BorderPosition]string{
+		styles.TopMiddleBorder: fmt.Sprintf("Execution #%d of command #%d", m.execution.ID, m.execution.CommandID),
+	}
+}
+
+// HelpBindings returns the keys of the pane displayed in the help
+func (m *runPane) HelpBindings() []*key.Binding {
+	m.keyMap.Cancel.SetEnabled(!m.execution.Status.IsFinal())
+	return []*key.Binding{
+		m.keyMap.Cancel,
+		m.keyMap.Rerun,
+		m.keyMap.Close,
+		&m.output.KeyMap.Up,
+		&m.output.KeyMap.Down,
+		&m.output.KeyMap.PageUp,
+		&m.output.KeyMap.PageDown,
+	}
+}
@@ -11,6 +11,7 @@ type Model struct {
 	styles        *styles.Styles
 	helpWidget    string
 	versionWidget string
+	tasksWidget   string
 	infoMsg       string
 	width         int
 }
@@ -22,6 +23,7 @@ func New(myStyles *styles.Styles, helpWidget, versionWidget string) Model {
 		styles:        myStyles,
 		helpWidget:    helpWidget,
 		versionWidget: versionWidget,
+		tasksWidget:   "",
 		errorMsg:      nil,
 		infoMsg:       "",
 	}
@@ -54,6 +56,16 @@ func (m *Model) SetInfo(info string) {
 	m.errorMsg = nil
 }
 
+// SetTasks updates the background tasks summary displayed before the version,
+// an empty summary hides the widget
+func (m *Model) SetTasks(tasks string) {
+	if tasks == "" {
+		m.tasksWidget = ""
+		return
+	}
+	m.tasksWidget = m.styles.FooterStyle.Tasks.Render(tasks)
+}
+
 // ClearMessages clears both error and info messages
 func (m *Model) ClearMessages() {
 	m.errorMsg = nil
@@ -63,7 +75,8 @@ func (m *Model) ClearMessages() {
 // availableMessageWidth returns the width available for messages
 func (m *Model) availableMessageWidth() int {
 	// -2 to accommodate padding
-	return max(0, m.width-lipgloss.Width(m.helpWidget)-lipgloss.Width(m.versionWidget))
+	return max(0, m.width-lipgloss.Width(m.helpWidget)-
+		lipgloss.Width(m.tasksWidget)-lipgloss.Width(m.versionWidget))
 }
 
 // View renders the footer component
@@ -85,7 +98,7 @@ func (m *Model) View() string {
 			Render(m.infoMsg)
 	}
 
-	footer += m.versionWidget
+	footer += m.tasksWidget + m.versionWidget
 
 	return m.styles.FooterStyle.Main.
 		MaxWidth(m.width).
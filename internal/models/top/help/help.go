@@ -12,6 +12,7 @@ import (
 	"github.com/fchastanet/shell-command-bookmarker/internal/services"
 	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/components/tabs"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/sort"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
@@ -35,6 +36,7 @@ type Model struct {
 	styles                  *styles.Styles
 	keyMaps                 *structure.KeyMaps
 	selectedCommand         *dbmodels.Command
+	topPaneKind             resource.Kind
 	currentSortState        *sort.State[*dbmodels.Command, string]
 	bindingSets             []BindingSet
 	width                   int
@@ -43,6 +45,7 @@ type Model struct {
 	focusedPane             structure.Position
 	showHelp                bool
 	filterEditActive        bool
+	promptCapturesText      bool
 }
 
 // New creates a new help component
@@ -65,8 +68,10 @@ func New(
 		keyMaps:                 keyMaps,
 		appService:              appService,
 		focusedPane:             structure.TopPane,
+		topPaneKind:             structure.CommandListKind,
 		mode:                    structure.NormalMode,
 		filterEditActive:        false,
+		promptCapturesText:      false,
 	}
 }
 
@@ -87,6 +92,8 @@ func (m *Model) Update(msg tea.Msg) (cmd tea.Cmd, forward bool) {
 		return nil, true
 	case structure.FocusedPaneChangedMsg:
 		return m.handleFocusedPaneChanged(msg), true
+	case structure.NavigationMsg:
+		return m.handleNavigation(msg), true
 	case tui.YesNoPromptMsg:
 		return m.updateHelpBindings(), true
 	case table.RowSelectedActionMsg[*dbmodels.Command]:
@@ -104,13 +111,18 @@ func (m *Model) Update(msg tea.Msg) (cmd tea.Cmd, forward bool) {
 }
 
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
-	if !m.filterEditActive && tui.CheckKey(msg, m.keyMaps.Global.Help) {
+	if !m.filterEditActive && !m.promptCapturesText && tui.CheckKey(msg, m.keyMaps.Global.Help) {
 		m.showHelp = !m.showHelp
 		return m.updateHelpBindings(), false
 	}
 	return nil, true
 }
 
+// SetPromptCapturesText disables the help key while a prompt needs all the keys
+func (m *Model) SetPromptCapturesText(captures bool) {
+	m.promptCapturesText = captures
+}
+
 func (m *Model) handleSortEditChange(msg sort.MsgSortEditModeChanged[*dbmodels.Command, string]) tea.Cmd {
 	m.currentSortState = msg.State
 	return m.updateHelpBindings()
@@ -126,6 +138,16 @@ func (m *Model) handleFocusedPaneChanged(msg structure.FocusedPaneChangedMsg) te
 	return m.updateHelpBindings()
 }
 
+// handleNavigation tracks the page displayed in the top pane, the command
+// actions are only displayed with the command list
+func (m *Model) handleNavigation(msg structure.NavigationMsg) tea.Cmd {
+	if msg.Position != structure.TopPane {
+		return nil
+	}
+	m.topPaneKind = msg.Page.Kind
+	return m.updateHelpBindings()
+}
+
 func (m *Model) handleChangeMode(msg structure.ChangeModeMsg) tea.Cmd {
 	m.mode = msg.NewMode
 	return m.updateHelpBindings()
@@ -163,6 +185,10 @@ func (m *Model) updateHelpBindingsPromptMode() {
 		return
 	}
 	// For prompt mode, just use a single set
+	if m.promptCapturesText {
+		m.AddBindingSet("Prompt Controls", keys.GetPlaceholderFormKeyMap().GetBindings())
+		return
+	}
 	m.AddBindingSet("Prompt Controls", keys.GetFormBindings())
 }
 
@@ -180,6 +206,7 @@ func (m *Model) updateHelpBindingsNormalMode() {
 	m.AddBindingSet("Global", keys.KeyMapToSlice(*m.keyMaps.Global))
 	m.AddBindingSet("Pane Navigation", m.paneManagerHelpBindings.HelpBindings())
 	if m.focusedPane == structure.TopPane &&
+		m.topPaneKind == structure.CommandListKind &&
 		m.currentSortState != nil &&
 		!m.currentSortState.IsEditActive {
 		tableCustomActions := keys.KeyMapToSlice(*m.keyMaps.TableCustomAction)
@@ -7,6 +7,7 @@ import (
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/command"
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/task"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
@@ -47,6 +48,23 @@ func NewMakerFactory(
 		Styles:       myStyles,
 		EditorKeyMap: keyMaps.Editor,
 	}
+	makers[structure.ComposerKind] = &command.ComposerMaker{
+		App:            app.Self(),
+		Styles:         myStyles,
+		ComposerKeyMap: keyMaps.Composer,
+	}
+	makers[structure.TaskKind] = &task.RunMaker{
+		App:        app.Self(),
+		Styles:     myStyles,
+		TaskKeyMap: keyMaps.Task,
+	}
+	makers[structure.TaskListKind] = &task.ListMaker{
+		App:              app.Self(),
+		Styles:           myStyles,
+		NavigationKeyMap: keyMaps.TableNavigation,
+		ActionKeyMap:     keyMaps.TableAction,
+		TaskListKeyMap:   keyMaps.TaskList,
+	}
 	return func(kind resource.Kind) models.Maker {
 		maker, ok := makers[kind]
 		if !ok {
@@ -21,6 +21,7 @@ import (
 	"github.com/fchastanet/shell-command-bookmarker/internal/services"
 	"github.com/fchastanet/shell-command-bookmarker/internal/version"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/sort"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
 )
 
@@ -49,11 +50,17 @@ const (
 
 	// How long messages remain displayed before auto-clearing
 	messageDisplayDuration = 2 * time.Second
+
+	// How often background tasks counts are refreshed
+	taskCountsRefreshInterval = time.Second
 )
 
 // MessageClearTickMsg represents a tick to check if messages should be cleared
 type MessageClearTickMsg struct{}
 
+// TaskCountsTickMsg represents a tick to refresh the background tasks counts
+type TaskCountsTickMsg struct{}
+
 type Model struct {
 	// Time when the current message should be cleared
 	messageClearTime time.Time
@@ -63,7 +70,7 @@ type Model struct {
 	styles     *styles.Styles
 	keyMaps    *structure.KeyMaps
 
-	prompt *tui.YesNoPromptMsg
+	prompt tui.Prompt
 
 	spinner *spinner.Model
 
@@ -83,6 +90,9 @@ type Model struct {
 
 	// Flag to indicate we're quitting and should clear the screen
 	quitting bool
+
+	// Number of background tasks finished when the panes were last notified
+	finishedTasks int
 }
 
 func NewModel(
@@ -103,6 +113,9 @@ func NewModel(
 		TableAction:       keys.GetTableActionKeyMap(),
 		TableCustomAction: keys.GetTableCustomActionKeyMap(),
 		Form:              keys.GetFormKeyMap(),
+		Task:              keys.GetTaskKeyMap(),
+		TaskList:          keys.GetTaskListKeyMap(),
+		Composer:          keys.GetComposerKeyMap(),
 	}
 
 	spinnerObj := spinner.New(spinner.WithSpinner(spinner.Line))
@@ -137,6 +150,7 @@ func NewModel(
 		messageClearTime:  time.Time{},
 		perfMonitorActive: false,
 		quitting:          false,
+		finishedTasks:     0,
 	}
 	helpModel := help.New(myStyles, keyMaps, appService, &m)
 	m.helpModel = &helpModel
@@ -149,6 +163,7 @@ func (m *Model) Init() tea.Cmd {
 	return models.SafeCmd(tea.Batch(
 		m.helpModel.Init(),
 		m.PaneManager.Init(),
+		scheduleTaskCounts(),
 	))
 }
 
@@ -192,7 +207,9 @@ func (m *Model) handleCommandMsg(msg tea.Msg) (tea.Cmd, bool) {
 	case spinner.TickMsg:
 		return m.handleSpinnerTick(msg), true
 	case tui.YesNoPromptMsg:
-		return m.handleYesNoPrompt(msg), true
+		return m.handlePrompt(msg), true
+	case tui.PromptMsg:
+		return m.handlePrompt(msg.Prompt), true
 	case tui.ErrorMsg, tui.InfoMsg, MessageClearTickMsg:
 		return m.handleStatusMsg(msg), true
 	case tea.WindowSizeMsg:
@@ -203,8 +220,13 @@ func (m *Model) handleCommandMsg(msg tea.Msg) (tea.Cmd, bool) {
 		return m.handleBlink(msg), true
 	case tui.MemoryStatsMsg:
 		return m.handleMemoryStats(msg), true
+	case TaskCountsTickMsg:
+		return m.handleTaskCountsTick(), true
 	case structure.CommandSelectedForShellMsg:
 		return m.handleCommandSelectedForShellMsg(msg), true
+	case structure.PageMsg:
+		// page messages are not consumed by the prompt
+		return m.PaneManager.Update(msg), true
 	}
 	return tea.Batch(cmds...), false
 }
@@ -229,7 +251,7 @@ func (m *Model) handleQuitClearScreenMsg() tea.Cmd {
 func (m *Model) handlePromptMode(msg tea.Msg) []tea.Cmd {
 	gKeys := m.keyMaps.Global
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		if tui.CheckKey(keyMsg, gKeys.Help) {
+		if !m.prompt.CapturesText() && tui.CheckKey(keyMsg, gKeys.Help) {
 			return m.displayHelp()
 		}
 	}
@@ -239,6 +261,7 @@ func (m *Model) handlePromptMode(msg tea.Msg) []tea.Cmd {
 		// If the prompt was completed, just reset the prompt
 		m.mode = structure.NormalMode
 		m.prompt = nil
+		m.helpModel.SetPromptCapturesText(false)
 		cmds = append(
 			cmds,
 			func() tea.Msg {
@@ -254,7 +277,11 @@ func (m *Model) handlePromptMode(msg tea.Msg) []tea.Cmd {
 
 func (m *Model) handleCommandSelectedForShellMsg(msg structure.CommandSelectedForShellMsg) tea.Cmd {
 	// When a command is selected for shell, store it and quit
-	if err := os.WriteFile(m.appService.Config.OutputFile, []byte(msg.Command), OutputFileMode); err != nil {
+	content, err := services.FormatSelectedCommand(msg.Selected, msg.Command, m.appService.Config.OutputFormat)
+	if err == nil {
+		err = os.WriteFile(m.appService.Config.OutputFile, []byte(content), OutputFileMode)
+	}
+	if err != nil {
 		slog.Error("Failed to write command to output file", "error", err)
 		fmt.Fprintf(os.Stderr, "Error writing command to output file: %v\n", err)
 	}
@@ -334,6 +361,40 @@ func scheduleClearMessage(d time.Duration) tea.Cmd {
 	})
 }
 
+// scheduleTaskCounts creates a tick command refreshing the background tasks counts
+func scheduleTaskCounts() tea.Cmd {
+	return tea.Tick(taskCountsRefreshInterval, func(_ time.Time) tea.Msg {
+		return TaskCountsTickMsg{}
+	})
+}
+
+// handleTaskCountsTick displays the queued, running and errored background
+// tasks, the panes are notified once the tasks submitted since the last
+// notification are all finished
+func (m *Model) handleTaskCountsTick() tea.Cmd {
+	if m.appService.TaskExecutor == nil {
+		return scheduleTaskCounts()
+	}
+	counts := m.appService.TaskExecutor.GetCounts()
+	m.footerModel.SetTasks(formatTaskCounts(counts))
+	finished := counts[task.Exited] + counts[task.Errored] + counts[task.Canceled]
+	if finished == m.finishedTasks || counts[task.Queued] > 0 || counts[task.Running] > 0 {
+		return scheduleTaskCounts()
+	}
+	m.finishedTasks = finished
+	// sent directly to the panes so that an opened prompt does not consume it
+	return tea.Batch(scheduleTaskCounts(), m.PaneManager.Update(structure.TasksCompletedMsg{}))
+}
+
+// formatTaskCounts returns the tasks summary, or an empty string if there is nothing to report
+func formatTaskCounts(counts map[task.Status]int) string {
+	if counts[task.Queued] == 0 && counts[task.Running] == 0 && counts[task.Errored] == 0 {
+		return ""
+	}
+	return fmt.Sprintf("tasks: %d queued • %d running • %d errored",
+		counts[task.Queued], counts[task.Running], counts[task.Errored])
+}
+
 // handleWindowSize processes window size messages
 func (m *Model) handleWindowSize(msg tea.WindowSizeMsg) tea.Cmd {
 	m.width = msg.Width
@@ -372,10 +433,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) []tea.Cmd {
 	return nil
 }
 
-func (m *Model) handleYesNoPrompt(promptMsg tui.YesNoPromptMsg) tea.Cmd {
+func (m *Model) handlePrompt(prompt tui.Prompt) tea.Cmd {
 	var cmds []tea.Cmd
 	m.mode = structure.PromptMode
-	m.prompt = &promptMsg
+	m.prompt = prompt
+	m.helpModel.SetPromptCapturesText(prompt.CapturesText())
 
 	cmds = append(cmds,
 		m.prompt.Init(),
@@ -421,6 +483,8 @@ func (m *Model) manageKey(msg tea.KeyMsg) []tea.Cmd {
 		return []tea.Cmd{tui.StartPerformanceMonitor(performanceMonitorInterval)}
 	case tui.CheckKey(msg, globalKeys.Search):
 		return []tea.Cmd{models.NavigateTo(structure.SearchKind, structure.WithPosition(structure.LeftPane))}
+	case tui.CheckKey(msg, globalKeys.History):
+		return []tea.Cmd{models.NavigateTo(structure.TaskListKind, structure.WithPosition(structure.TopPane))}
 	default:
 	}
 	return nil
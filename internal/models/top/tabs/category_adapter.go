@@ -20,25 +20,30 @@ const (
 	DeletedCommands
 	// AllCommands represents all commands regardless of status
 	AllCommands
+	// TeamCommands represents the read-only commands of the team catalogs
+	TeamCommands
 )
 
 // CategoryAdapter helps translate between UI category types and service-level categories
 type CategoryAdapter struct {
-	historyService *services.HistoryService
-	sortStyles     sort.EditorSortStylesInterface
-	sortKeyMap     *sort.KeyMap
+	historyService     *services.HistoryService
+	teamCatalogService *services.TeamCatalogService
+	sortStyles         sort.EditorSortStylesInterface
+	sortKeyMap         *sort.KeyMap
 }
 
 // NewCategoryAdapter creates a new adapter for category conversions
 func NewCategoryAdapter(
 	historyService *services.HistoryService,
+	teamCatalogService *services.TeamCatalogService,
 	sortStyles sort.EditorSortStylesInterface,
 	sortKeyMap *sort.KeyMap,
 ) *CategoryAdapter {
 	return &CategoryAdapter{
-		historyService: historyService,
-		sortStyles:     sortStyles,
-		sortKeyMap:     sortKeyMap,
+		historyService:     historyService,
+		teamCatalogService: teamCatalogService,
+		sortStyles:         sortStyles,
+		sortKeyMap:         sortKeyMap,
 	}
 }
 
@@ -77,7 +82,7 @@ func (ca *CategoryAdapter) GetCategoryTabs(
 		return sortState
 	}
 
-	return []pkgTabs.CategoryTab[
+	categoryTabs := []pkgTabs.CategoryTab[
 		*dbmodels.Command,
 		dbmodels.CommandStatus,
 		string,
@@ -127,6 +132,18 @@ func (ca *CategoryAdapter) GetCategoryTabs(
 			},
 		),
 	}
+	// the team tab is only displayed if team catalogs are configured
+	if ca.teamCatalogService.HasCatalogs() {
+		categoryTabs = append(categoryTabs, newCategoryTab(
+			"Team",
+			createNewSortState(),
+			TeamCommands,
+			[]dbmodels.CommandStatus{
+				dbmodels.CommandStatusSaved,
+			},
+		))
+	}
+	return categoryTabs
 }
 
 func newCategoryTab(
@@ -178,6 +195,9 @@ func (ca *CategoryAdapter) GetCategoryCounts() (map[category.Type]int, error) {
 	uiCounts[NewCommands] = serviceCounts[services.CommandCategoryNew]
 	uiCounts[DeletedCommands] = serviceCounts[services.CommandCategoryDeleted]
 	uiCounts[AllCommands] = serviceCounts[services.CommandCategoryAll]
+	if ca.teamCatalogService.HasCatalogs() {
+		uiCounts[TeamCommands] = ca.teamCatalogService.CountCommands()
+	}
 
 	return uiCounts, nil
 }
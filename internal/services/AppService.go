@@ -1,31 +1,57 @@
 package services
 
 import (
+	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/args"
 	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
 	"github.com/mattn/go-isatty"
 )
 
 type AppService struct {
 	Config                  *AppServiceConfig
+	ConfigService           *ConfigService
 	DBService               *DBService
 	LintService             *LintService
 	HistoryService          *HistoryService
+	PlaceholderService      *PlaceholderService
+	ExecutionService        *ExecutionService
+	DangerService           *DangerService
+	ScriptExportService     *ScriptExportService
+	CatalogService          *CatalogService
+	CheatSheetService       *CheatSheetService
+	SyncService             *SyncService
+	TeamCatalogService      *TeamCatalogService
 	LoggerService           *LoggerService
 	ShellIntegrationService *ShellIntegrationService
 	ShellDetectionService   ShellDetectionServiceInterface
+	TaskExecutor            *executors.TaskExecutor
+	SyncConflictResolver    SyncConflictResolver
 	cleanupFunc             func()
+	currentShell            ShellType
 }
 
 type AppServiceConfig struct {
 	DBPath       string
+	ConfigPath   string
 	SqliteSchema string
 	OutputFile   string // Flag to indicate if we're in shell selection mode
+	// OutputFormat is the format of the command written to the output file
+	OutputFormat models.OutputFormat
 	MaxTasks     int
 	Debug        bool
 }
@@ -33,13 +59,25 @@ type AppServiceConfig struct {
 func NewAppService() *AppService {
 	return &AppService{
 		Config:                  nil,
+		ConfigService:           nil,
 		cleanupFunc:             func() {},
 		DBService:               nil,
 		LintService:             nil,
 		HistoryService:          nil,
+		PlaceholderService:      nil,
+		ExecutionService:        nil,
+		DangerService:           nil,
+		ScriptExportService:     nil,
+		CatalogService:          nil,
+		CheatSheetService:       nil,
+		SyncService:             nil,
+		TeamCatalogService:      nil,
 		LoggerService:           nil,
 		ShellIntegrationService: nil,
 		ShellDetectionService:   nil,
+		TaskExecutor:            nil,
+		SyncConflictResolver:    nil,
+		currentShell:            ShellTypeUnknown,
 	}
 }
 
@@ -56,12 +94,38 @@ func (app *AppService) Init(cfg AppServiceConfig) error {
 		return err
 	}
 
-	app.DBService = NewDBService(cfg.DBPath, cfg.SqliteSchema)
+	app.ConfigService = NewConfigService(cfg.ConfigPath)
+	if err := app.ConfigService.Init(); err != nil {
+		slog.Error("Error loading configuration", "error", err)
+		return err
+	}
+
+	// the shell of the user is the shell of the commands stored before the
+	// shell column was added
+	app.ShellDetectionService = NewShellDetectionService()
+	app.currentShell = app.ShellDetectionService.DetectShell()
+	app.DBService = NewDBService(cfg.DBPath, cfg.SqliteSchema, getHistoryShellDialect(app.currentShell))
+	app.TaskExecutor = executors.NewTaskExecutor(cfg.MaxTasks)
+	app.ExecutionService = NewExecutionService(
+		app.DBService,
+		executors.NewCommandRunner(),
+		&app.ConfigService.GetConfig().Run,
+	)
 
 	// cleanup function to be invoked when app is terminated.
 	cleanup := func() {
 		// Perform cleanup tasks here
 		// e.g., close database connections, release resources, etc.
+		// running tasks have to finish before closing the database
+		app.ExecutionService.Stop()
+		app.TaskExecutor.Stop()
+		// the bookmarks may have changed during the session, the shell
+		// integration service is nil if the initialization failed
+		if app.ShellIntegrationService != nil {
+			if _, err := app.UpdateShellLibraries(); err != nil {
+				slog.Error("Error updating shell libraries", "error", err)
+			}
+		}
 		err := app.DBService.Close()
 		if err != nil {
 			slog.Error("Error closing database", "error", err)
@@ -74,10 +138,13 @@ func (app *AppService) Init(cfg AppServiceConfig) error {
 		return err
 	}
 
-	app.LintService = NewLintService()
+	app.LintService = NewLintService(
+		WithLintCache(app.DBService),
+		WithLintConfig(&app.ConfigService.GetConfig().Lint),
+	)
 	if err := app.LintService.Init(); err != nil {
 		if errors.Is(err, ErrShellCheckNotFound) {
-			slog.Warn("shellcheck command not found in PATH. Linting will be disabled.", "error", err)
+			slog.Warn("shellcheck command not found in PATH. Only the built-in linters will be used.", "error", err)
 		} else {
 			slog.Error("Error creating LintService", "error", err)
 			return err
@@ -88,14 +155,22 @@ func (app *AppService) Init(cfg AppServiceConfig) error {
 		processors.NewHistoryIngestor(),
 		app.DBService,
 		app.LintService,
+		app.TaskExecutor,
 	)
 	if err := app.HistoryService.Init(); err != nil {
 		slog.Error("Error initializing history service", "error", err)
 	}
-	slog.Info("AppService initialized successfully", "dbPath", cfg.DBPath, "debug", cfg.Debug)
+	app.PlaceholderService = NewPlaceholderService(app.DBService, &executors.DefaultCommandExecutor{})
+	app.DangerService = NewDangerService()
+	app.ScriptExportService = NewScriptExportService(&app.ConfigService.GetConfig().Export)
+	app.CatalogService = NewCatalogService(app.DBService)
+	app.CheatSheetService = NewCheatSheetService(&app.ConfigService.GetConfig().Export)
+	app.SyncService = NewSyncService(&app.ConfigService.GetConfig().Sync, &executors.DefaultCommandExecutor{})
+	app.TeamCatalogService = NewTeamCatalogService(app.ConfigService.GetConfig().Catalogs, app.CatalogService)
+	app.TaskExecutor.Start()
+	slog.Info("AppService initialized successfully", "dbPath", cfg.DBPath, "debug", cfg.Debug, "maxTasks", cfg.MaxTasks)
 
 	app.ShellIntegrationService = NewShellIntegrationService()
-	app.ShellDetectionService = NewShellDetectionService()
 
 	return nil
 }
@@ -106,13 +181,7 @@ func (app *AppService) Main(cli *args.Cli, sqliteSchema string) error {
 		return err
 	}
 
-	err := app.Init(AppServiceConfig{
-		SqliteSchema: sqliteSchema,
-		MaxTasks:     1,
-		DBPath:       string(cli.DBPath),
-		Debug:        cli.Debug,
-		OutputFile:   cli.OutputFile,
-	})
+	err := app.Init(getAppServiceConfig(cli, sqliteSchema))
 	if err != nil {
 		slog.Error("Error initializing AppService", "error", err)
 		return err
@@ -129,6 +198,557 @@ func (app *AppService) Main(cli *args.Cli, sqliteSchema string) error {
 	return nil
 }
 
+func getAppServiceConfig(cli *args.Cli, sqliteSchema string) AppServiceConfig {
+	return AppServiceConfig{
+		SqliteSchema: sqliteSchema,
+		MaxTasks:     cli.MaxTasks,
+		DBPath:       string(cli.DBPath),
+		ConfigPath:   cli.ConfigPath,
+		Debug:        cli.Debug,
+		OutputFile:   cli.OutputFile,
+		OutputFormat: models.OutputFormat(cli.Tui.Format),
+	}
+}
+
+// HandleCliCommand runs the non interactive command selected on the command
+// line, handled is false if the interactive interface has to be launched
+func (app *AppService) HandleCliCommand(cli *args.Cli, sqliteSchema string) (handled bool, err error) {
+	if cli.Command == args.CommandTui {
+		return false, nil
+	}
+	if err := app.Init(getAppServiceConfig(cli, sqliteSchema)); err != nil {
+		slog.Error("Error initializing AppService", "error", err)
+		return true, err
+	}
+	switch cli.Command {
+	case args.CommandRelint:
+		return true, app.relint(&cli.Relint)
+	case args.CommandExportScript:
+		return true, app.exportScript(&cli.ExportScript)
+	case args.CommandExport:
+		return true, app.exportCatalog(&cli.Export)
+	case args.CommandImport:
+		return true, app.importCatalog(&cli.Import)
+	case args.CommandLibrary:
+		return true, app.library(&cli.Library)
+	case args.CommandExtract:
+		return true, app.extract(&cli.Extract)
+	case args.CommandImportRc:
+		return true, app.importRcFiles(&cli.ImportRc)
+	case args.CommandSync:
+		return true, app.sync(&cli.Sync)
+	case args.CommandList:
+		return true, app.list(&cli.List)
+	case args.CommandSearch:
+		return true, app.search(&cli.Search)
+	case args.CommandShow:
+		return true, app.show(&cli.Show)
+	case args.CommandAdd:
+		return true, app.add(&cli.Add)
+	case args.CommandEdit:
+		return true, app.edit(&cli.Edit)
+	case args.CommandDelete:
+		return true, app.delete(&cli.Delete)
+	case args.CommandRestore:
+		return true, app.restore(&cli.Restore)
+	case args.CommandTag:
+		return true, app.tag(&cli.Tag)
+	default:
+		return true, &UnknownCliCommandError{Command: cli.Command}
+	}
+}
+
+// relint lints again the commands selected by the relint command
+// and waits for the lint to complete
+func (app *AppService) relint(relintCmd *args.RelintCmd) error {
+	if !app.LintService.IsLintingAvailable() {
+		return ErrNoLinterAvailable
+	}
+	var count int
+	if len(relintCmd.IDs) > 0 {
+		commands, err := app.getCommandsByIDs(relintCmd.IDs)
+		if err != nil {
+			return err
+		}
+		count = app.HistoryService.RelintCommands(commands)
+	} else {
+		var err error
+		count, err = app.HistoryService.RelintCategory(CommandCategory(relintCmd.Category))
+		if err != nil {
+			return err
+		}
+	}
+	app.TaskExecutor.Wait()
+	fmt.Printf("%d command(s) linted, %d error(s)\n", count, app.TaskExecutor.GetCounts()[task.Errored])
+	return nil
+}
+
+// exportScript writes the command selected by the export-script command as
+// an executable script, or prints it if no destination is provided
+func (app *AppService) exportScript(exportCmd *args.ExportScriptCmd) error {
+	cmd, err := app.getCommandByID(exportCmd.ID)
+	if err != nil {
+		return err
+	}
+	path := exportCmd.Path
+	if exportCmd.Bin {
+		path, err = app.ScriptExportService.GetBinPath(cmd)
+		if err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		fmt.Print(app.ScriptExportService.GenerateScriptFile(cmd))
+		return nil
+	}
+	overwrite := exportCmd.Force
+	if !overwrite && app.ScriptExportService.Exists(path) {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return &ScriptFileExistsError{Path: path}
+		}
+		fmt.Printf("File %s already exists, overwrite it? [y/N] ", path)
+		var answer string
+		_, _ = fmt.Scanln(&answer)
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return &ScriptFileExistsError{Path: path}
+		}
+		overwrite = true
+	}
+	if err := app.ScriptExportService.ExportScript(cmd, path, overwrite); err != nil {
+		return err
+	}
+	fmt.Printf("Command #%d exported to %s\n", cmd.ID, path)
+	return nil
+}
+
+// exportCatalog writes the selected commands to the catalog file or to the
+// markdown cheat sheet, or to stdout if no path is provided. The cheat sheet
+// lists the available commands by default, the catalogs all the commands.
+func (app *AppService) exportCatalog(exportCmd *args.ExportCmd) error {
+	format := models.CatalogFormat(cmp.Or(exportCmd.Format, string(DetectCatalogFormat(exportCmd.Path))))
+	category := CommandCategory(exportCmd.Category)
+	if category == "" && format == models.CatalogFormatMarkdown {
+		category = CommandCategoryAvailable
+	}
+	statuses := app.HistoryService.GetCommandStatusesByCategory(cmp.Or(category, CommandCategoryAll))
+	catalog, err := app.CatalogService.ExportCatalog(statuses...)
+	if err != nil {
+		return err
+	}
+	catalog.Filter(&models.CatalogFilter{Folder: exportCmd.Folder, Tags: exportCmd.Tags})
+	write := func(writer io.Writer) error {
+		if format == models.CatalogFormatMarkdown {
+			return app.CheatSheetService.WriteCheatSheet(
+				writer, catalog, CheatSheetGroupBy(exportCmd.GroupBy), exportCmd.Template,
+			)
+		}
+		return WriteCatalog(writer, catalog, format)
+	}
+	if exportCmd.Path == "" {
+		return write(os.Stdout)
+	}
+	var content bytes.Buffer
+	if err := write(&content); err != nil {
+		return err
+	}
+	if err := os.WriteFile(exportCmd.Path, content.Bytes(), catalogFileMode); err != nil {
+		return err
+	}
+	fmt.Printf("%d command(s) exported to %s\n", len(catalog.Commands), exportCmd.Path)
+	return nil
+}
+
+// importCatalog stores the commands of the catalog file, merging the existing
+// commands using the selected strategy
+func (app *AppService) importCatalog(importCmd *args.ImportCmd) error {
+	file, err := os.Open(importCmd.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	format := models.CatalogFormat(cmp.Or(importCmd.Format, string(DetectCatalogFormat(importCmd.Path))))
+	catalog, err := ReadCatalog(file, format)
+	if err != nil {
+		return err
+	}
+	report, err := app.CatalogService.ImportCatalog(catalog, models.MergeStrategy(importCmd.Strategy))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d command(s) added, %d overwritten, %d skipped\n", report.Added, report.Overwritten, report.Skipped)
+	return nil
+}
+
+// extract imports the commands of the markdown files and of the shell scripts
+// and waits for the lint of the new commands to complete
+func (app *AppService) extract(extractCmd *args.ExtractCmd) error {
+	report, err := app.HistoryService.IngestSnippets(extractCmd.Paths)
+	app.TaskExecutor.Wait()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d file(s) scanned (%d failed), %d command(s) added, %d already existing, %d filtered out\n",
+		report.Files, report.Failed, report.Added, report.Existing, report.FilteredOut)
+	return nil
+}
+
+// importRcFiles saves the aliases and the functions of the rc files and
+// waits for the lint of the new commands to complete
+func (app *AppService) importRcFiles(importRcCmd *args.ImportRcCmd) error {
+	report, err := app.HistoryService.ImportRcFiles(importRcCmd.Paths)
+	app.TaskExecutor.Wait()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d file(s) read (%d failed), %d command(s) saved, %d already existing\n",
+		report.Files, report.Failed, report.Added, report.Existing)
+	return nil
+}
+
+// sync syncs the saved and deleted commands with the git repository, stores
+// the merged commands and waits for the lint of the modified commands
+func (app *AppService) sync(syncCmd *args.SyncCmd) error {
+	config := &app.ConfigService.GetConfig().Sync
+	config.Dir = cmp.Or(syncCmd.Dir, config.Dir)
+	config.Remote = cmp.Or(syncCmd.Remote, config.Remote)
+	config.Branch = cmp.Or(syncCmd.Branch, config.Branch)
+	resolver := app.SyncConflictResolver
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		resolver = nil
+	}
+
+	local, err := app.CatalogService.ExportCatalog(models.CommandStatusSaved, models.CommandStatusDeleted)
+	if err != nil {
+		return err
+	}
+	merged, report, err := app.SyncService.Sync(local, SyncResolution(syncCmd.Strategy), resolver)
+	if err != nil {
+		return err
+	}
+	importReport, err := app.CatalogService.ImportSyncedCatalog(merged)
+	if err != nil {
+		return err
+	}
+	if importReport.Added+importReport.Overwritten > 0 && app.LintService.IsLintingAvailable() {
+		// the lint results are not synced
+		commands := []*models.Command{}
+		for _, catalogCommand := range merged.Commands {
+			cmd, err := app.DBService.GetCommandByUUID(catalogCommand.UUID)
+			if err != nil {
+				return err
+			}
+			if cmd != nil && cmd.LintStatus == models.LintStatusNotAvailable {
+				commands = append(commands, cmd)
+			}
+		}
+		app.HistoryService.RelintCommands(commands)
+		app.TaskExecutor.Wait()
+	}
+
+	fmt.Printf("%d command(s) committed, %d pulled (%d conflict(s)), %d added, %d updated\n",
+		report.Committed, report.Pulled, report.Conflicts, importReport.Added, importReport.Overwritten)
+	if report.Pushed {
+		fmt.Printf("Commands pushed to %s\n", config.Remote)
+	}
+	return nil
+}
+
+// list prints the commands of the category selected by the list command in
+// the selected format
+func (app *AppService) list(listCmd *args.ListCmd) error {
+	statuses := app.HistoryService.GetCommandStatusesByCategory(CommandCategory(listCmd.Category))
+	commands, err := app.HistoryService.GetCommandsByStatus(statuses...)
+	if err != nil {
+		return err
+	}
+	filter := &models.CatalogFilter{Folder: listCmd.Folder, Tags: listCmd.Tags}
+	commands = slices.DeleteFunc(commands, func(cmd *models.Command) bool {
+		return !filter.MatchesCommand(cmd)
+	})
+	return WriteCommands(os.Stdout, commands, models.OutputFormat(listCmd.Format))
+}
+
+// search prints the commands of the category matching the query of the
+// search command in the selected format, the best matches first
+func (app *AppService) search(searchCmd *args.SearchCmd) error {
+	statuses := app.HistoryService.GetCommandStatusesByCategory(CommandCategory(searchCmd.Category))
+	commands, err := app.HistoryService.GetCommandsByStatus(statuses...)
+	if err != nil {
+		return err
+	}
+	matches := SearchCommands(strings.Join(searchCmd.Query, " "), commands)
+	return WriteCommands(os.Stdout, matches, models.OutputFormat(searchCmd.Format))
+}
+
+// show prints the fields of the command selected by the show command in the
+// selected format
+func (app *AppService) show(showCmd *args.ShowCmd) error {
+	cmd, err := app.getCommandByID(showCmd.ID)
+	if err != nil {
+		return err
+	}
+	return WriteCommand(os.Stdout, cmd, models.OutputFormat(showCmd.Format))
+}
+
+// add saves the command provided by the add command and waits for its lint
+// to complete
+func (app *AppService) add(addCmd *args.AddCmd) error {
+	script, err := readScriptArg(addCmd.Script)
+	if err != nil {
+		return err
+	}
+	existing, err := app.DBService.GetCommandByScript(script)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return &CommandAlreadyExistsError{ID: existing.ID}
+	}
+	cmd := models.NewCommand(script, 0, time.Now())
+	cmd.Title = addCmd.Title
+	cmd.Description = addCmd.Description
+	cmd.Status = models.CommandStatusSaved
+	if addCmd.Shell != "" {
+		cmd.Shell = models.ShellDialect(addCmd.Shell)
+	}
+	cmd.Folder = addCmd.Folder
+	cmd.Tags = addCmd.Tags
+	cmd.ShortName = addCmd.ShortName
+	if reason := getInvalidFieldReason(cmd); reason != "" {
+		return &InvalidCommandError{Reason: reason}
+	}
+	if err := app.HistoryService.SaveCommand(cmd); err != nil {
+		return err
+	}
+	app.TaskExecutor.Wait()
+	fmt.Printf("Command #%d saved\n", cmd.ID)
+	return nil
+}
+
+// edit modifies the fields of the command provided by the edit command, an
+// imported command is saved like in the editor of the interactive interface
+func (app *AppService) edit(editCmd *args.EditCmd) error {
+	cmd, err := app.getCommandByID(editCmd.ID)
+	if err != nil {
+		return err
+	}
+	if !cmd.IsEditable() {
+		return &CommandStatusError{ID: cmd.ID, Status: cmd.Status, Action: "edited"}
+	}
+	original := *cmd
+	if editCmd.Script != nil {
+		if cmd.Script, err = readScriptArg(*editCmd.Script); err != nil {
+			return err
+		}
+	}
+	if editCmd.Title != nil {
+		cmd.Title = *editCmd.Title
+	}
+	if editCmd.Description != nil {
+		cmd.Description = *editCmd.Description
+	}
+	if editCmd.Shell != nil {
+		cmd.Shell = models.ShellDialect(*editCmd.Shell)
+	}
+	if editCmd.Folder != nil {
+		cmd.Folder = *editCmd.Folder
+	}
+	if editCmd.ShortName != nil {
+		cmd.ShortName = *editCmd.ShortName
+	}
+	if reason := getInvalidFieldReason(cmd); reason != "" {
+		return &InvalidCommandError{Reason: reason}
+	}
+	if cmd.Folder != original.Folder {
+		if err := app.DBService.SetCommandFolder(cmd.ID, cmd.Folder); err != nil {
+			return err
+		}
+	}
+	if cmd.Script != original.Script || cmd.Title != original.Title || cmd.Description != original.Description ||
+		cmd.Shell != original.Shell || cmd.ShortName != original.ShortName {
+		if _, err := app.HistoryService.UpdateCommand(cmd); err != nil {
+			return err
+		}
+		app.TaskExecutor.Wait()
+	}
+	fmt.Printf("Command #%d updated\n", cmd.ID)
+	return nil
+}
+
+// delete marks the commands selected by the delete command as deleted, none
+// of them is deleted if one of them is already deleted
+func (app *AppService) delete(deleteCmd *args.DeleteCmd) error {
+	commands, err := app.getCommandsByIDs(deleteCmd.IDs)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if cmd.Status == models.CommandStatusDeleted {
+			return &CommandStatusError{ID: cmd.ID, Status: cmd.Status, Action: "deleted"}
+		}
+	}
+	for _, cmd := range commands {
+		cmd.Status = models.CommandStatusDeleted
+		if err := app.DBService.UpdateCommand(cmd); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%d command(s) deleted\n", len(commands))
+	return nil
+}
+
+// restore saves again the deleted commands selected by the restore command
+// and waits for their lint to complete
+func (app *AppService) restore(restoreCmd *args.RestoreCmd) error {
+	commands, err := app.getCommandsByIDs(restoreCmd.IDs)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if cmd.Status != models.CommandStatusDeleted {
+			return &CommandStatusError{ID: cmd.ID, Status: cmd.Status, Action: "restored"}
+		}
+	}
+	if err := app.HistoryService.RestoreCommand(commands); err != nil {
+		return err
+	}
+	app.TaskExecutor.Wait()
+	fmt.Printf("%d command(s) restored\n", len(commands))
+	return nil
+}
+
+// tag adds the tags of the tag command to the command, or removes them, and
+// prints the tags of the command, one tag by line
+func (app *AppService) tag(tagCmd *args.TagCmd) error {
+	cmd, err := app.getCommandByID(tagCmd.ID)
+	if err != nil {
+		return err
+	}
+	if len(tagCmd.Tags) > 0 {
+		if !cmd.IsEditable() {
+			return &CommandStatusError{ID: cmd.ID, Status: cmd.Status, Action: "tagged"}
+		}
+		if tagCmd.Remove {
+			cmd.Tags = slices.DeleteFunc(cmd.Tags, func(tag string) bool {
+				return slices.Contains(tagCmd.Tags, tag)
+			})
+		} else {
+			for _, tag := range tagCmd.Tags {
+				if !slices.Contains(cmd.Tags, tag) {
+					cmd.Tags = append(cmd.Tags, tag)
+				}
+			}
+		}
+		if reason := getInvalidFieldReason(cmd); reason != "" {
+			return &InvalidCommandError{Reason: reason}
+		}
+		if err := app.DBService.SetCommandTags(cmd.ID, cmd.Tags); err != nil {
+			return err
+		}
+	}
+	for _, tag := range cmd.Tags {
+		fmt.Println(tag)
+	}
+	return nil
+}
+
+// getCommandByID returns the command having the id provided on the command line
+func (app *AppService) getCommandByID(id int) (*models.Command, error) {
+	cmd, err := app.DBService.GetCommandByID(resource.ID(id))
+	if err != nil {
+		return nil, err
+	}
+	if cmd == nil {
+		return nil, &CommandNotFoundError{ID: resource.ID(id)}
+	}
+	return cmd, nil
+}
+
+// getCommandsByIDs returns the commands having the ids provided on the
+// command line, an error is returned if one of them does not exist
+func (app *AppService) getCommandsByIDs(ids []int) ([]*models.Command, error) {
+	commands := make([]*models.Command, 0, len(ids))
+	for _, id := range ids {
+		cmd, err := app.getCommandByID(id)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+// readScriptArg returns the script provided on the command line, the script
+// is read from stdin if it is "-"
+func readScriptArg(script string) (string, error) {
+	if script != "-" {
+		return script, nil
+	}
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\n"), nil
+}
+
+// library prints the shell library of the selected shell, or writes the
+// library files of the configuration if no shell is selected
+func (app *AppService) library(libraryCmd *args.LibraryCmd) error {
+	if libraryCmd.Shell != "" {
+		library, err := app.GenerateShellLibrary(models.ShellDialect(libraryCmd.Shell))
+		if err != nil {
+			return err
+		}
+		fmt.Print(library)
+		return nil
+	}
+	if len(app.ConfigService.GetConfig().Library.GetPaths()) == 0 {
+		return &LibraryNotConfiguredError{}
+	}
+	paths, err := app.UpdateShellLibraries()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Printf("Shell library written to %s\n", path)
+	}
+	if len(paths) == 0 {
+		fmt.Println("Shell libraries are up to date")
+	}
+	return nil
+}
+
+// GenerateShellLibrary returns the library of the given shell, defining the
+// commands having a short name
+func (app *AppService) GenerateShellLibrary(shell models.ShellDialect) (string, error) {
+	commands, err := app.DBService.GetCommands(models.CommandStatusSaved, models.CommandStatusImported)
+	if err != nil {
+		return "", err
+	}
+	return app.ShellIntegrationService.GenerateLibrary(commands, shell), nil
+}
+
+// UpdateShellLibraries writes the library files of the configuration whose
+// content changed, and returns their paths
+func (app *AppService) UpdateShellLibraries() ([]string, error) {
+	paths := app.ConfigService.GetConfig().Library.GetPaths()
+	written := []string{}
+	for _, shell := range slices.Sorted(maps.Keys(paths)) {
+		library, err := app.GenerateShellLibrary(shell)
+		if err != nil {
+			return written, err
+		}
+		changed, err := app.ShellIntegrationService.WriteLibrary(paths[shell], library)
+		if err != nil {
+			return written, err
+		}
+		if changed {
+			written = append(written, paths[shell])
+		}
+	}
+	return written, nil
+}
+
 func (*AppService) IsTerminalCompatible() error {
 	if !isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()) {
 		slog.Error("This program requires a terminal to run. Please run it in a terminal emulator.")
@@ -141,6 +761,12 @@ func (app *AppService) IsShellSelectionMode() bool {
 	return app.Config.OutputFile != ""
 }
 
+// GetCurrentShellDialect returns the dialect of the shell that launched the
+// application, ok is false if this shell could not be detected
+func (app *AppService) GetCurrentShellDialect() (dialect models.ShellDialect, ok bool) {
+	return models.ParseShellDialect(string(app.currentShell))
+}
+
 func (app *AppService) HandleShellIntegrationScriptGeneration(cli *args.Cli) bool {
 	if !cli.GenerateBash && !cli.GenerateZsh && !cli.AutoDetect {
 		return false
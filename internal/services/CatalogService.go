@@ -0,0 +1,333 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// catalogFileMode is the mode of the exported catalog files
+	catalogFileMode = 0o600
+	// catalogIndent is the indentation of the exported JSON and YAML catalogs
+	catalogIndent = 2
+	// commandTitleMaxLength is the maximum length of a command title in the database
+	commandTitleMaxLength = 50
+	// tagTitleMaxLength is the maximum length of a tag or a folder title in the database
+	tagTitleMaxLength = 30
+)
+
+// ImportReport counts the commands of an imported catalog by outcome
+type ImportReport struct {
+	Added       int
+	Overwritten int
+	Skipped     int
+}
+
+// CatalogService exports the commands to a JSON or YAML catalog and imports
+// them back, eg: to move the bookmarks between machines
+type CatalogService struct {
+	store CatalogStoreInterface
+}
+
+func NewCatalogService(store CatalogStoreInterface) *CatalogService {
+	return &CatalogService{store: store}
+}
+
+// ErrCatalogFormatNotReadable indicates that a markdown cheat sheet was provided as catalog to import
+var ErrCatalogFormatNotReadable = errors.New("this format can be exported but not imported")
+
+// DetectCatalogFormat returns the format matching the extension of the file,
+// YAML by default
+func DetectCatalogFormat(path string) models.CatalogFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return models.CatalogFormatJSON
+	case ".md", ".markdown":
+		return models.CatalogFormatMarkdown
+	case ".cheat":
+		return models.CatalogFormatNavi
+	case ".toml":
+		return models.CatalogFormatPet
+	default:
+		return models.CatalogFormatYAML
+	}
+}
+
+// ExportCatalog returns the commands having one of the given statuses, all
+// the commands if none, ordered by creation. The obsolete copies are not
+// exported as they share the uuid of the command they were copied from.
+func (s *CatalogService) ExportCatalog(statuses ...models.CommandStatus) (*models.Catalog, error) {
+	commands, err := s.store.GetCommands(statuses...)
+	if err != nil {
+		return nil, err
+	}
+	commands = slices.DeleteFunc(commands, func(cmd *models.Command) bool {
+		return cmd.Status == models.CommandStatusObsolete
+	})
+	slices.SortFunc(commands, func(a, b *models.Command) int {
+		return int(a.ID - b.ID)
+	})
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Commands:   make([]*models.CatalogCommand, 0, len(commands)),
+	}
+	for _, cmd := range commands {
+		catalog.Commands = append(catalog.Commands, models.NewCatalogCommand(cmd))
+	}
+	return catalog, nil
+}
+
+// WriteCatalog serializes the catalog in the given format
+func WriteCatalog(writer io.Writer, catalog *models.Catalog, format models.CatalogFormat) error {
+	switch format {
+	case models.CatalogFormatNavi:
+		return WriteNaviCheats(writer, catalog)
+	case models.CatalogFormatPet:
+		return WritePetSnippets(writer, catalog)
+	case models.CatalogFormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", strings.Repeat(" ", catalogIndent))
+		return encoder.Encode(catalog)
+	default:
+		encoder := yaml.NewEncoder(writer)
+		encoder.SetIndent(catalogIndent)
+		if err := encoder.Encode(catalog); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+}
+
+// ReadCatalog parses a catalog in the given format and checks its version
+func ReadCatalog(reader io.Reader, format models.CatalogFormat) (*models.Catalog, error) {
+	var catalog models.Catalog
+	var err error
+	switch format {
+	case models.CatalogFormatMarkdown:
+		return nil, &CatalogParseError{Err: ErrCatalogFormatNotReadable, Format: format}
+	case models.CatalogFormatNavi:
+		cheats, err := ReadNaviCheats(reader)
+		if err != nil {
+			return nil, &CatalogParseError{Err: err, Format: format}
+		}
+		return cheats, nil
+	case models.CatalogFormatPet:
+		snippets, err := ReadPetSnippets(reader)
+		if err != nil {
+			return nil, &CatalogParseError{Err: err, Format: format}
+		}
+		return snippets, nil
+	case models.CatalogFormatJSON:
+		err = json.NewDecoder(reader).Decode(&catalog)
+	case models.CatalogFormatYAML:
+		err = yaml.NewDecoder(reader).Decode(&catalog)
+	}
+	if err != nil {
+		return nil, &CatalogParseError{Err: err, Format: format}
+	}
+	if catalog.Version != models.CatalogVersion {
+		return nil, &UnsupportedCatalogVersionError{Version: catalog.Version}
+	}
+	return &catalog, nil
+}
+
+// ImportCatalog stores the commands of the catalog, a command having the same
+// uuid as an existing command, or the same script if the uuid does not match,
+// is merged using the given strategy. A command identical to the existing
+// command is skipped whatever the strategy, as well as the obsolete copies
+// which would replace the command sharing their uuid. All the commands are
+// checked before storing the first one, and none is stored if one fails.
+func (s *CatalogService) ImportCatalog(
+	catalog *models.Catalog, strategy models.MergeStrategy,
+) (*ImportReport, error) {
+	return s.importCatalog(catalog, strategy, getMatchingCommand)
+}
+
+// ImportSyncedCatalog stores the commands of the sync repository, a command
+// having the same uuid as an existing command overwrites it. The commands are
+// not matched by script as the copies kept on conflict share their script.
+func (s *CatalogService) ImportSyncedCatalog(catalog *models.Catalog) (*ImportReport, error) {
+	return s.importCatalog(catalog, models.MergeStrategyOverwrite,
+		func(store CatalogStoreInterface, cmd *models.Command) (*models.Command, error) {
+			return store.GetCommandByUUID(cmd.UUID)
+		},
+	)
+}
+
+// matchingCommandGetter returns the existing command matching the imported
+// command, nil if none
+type matchingCommandGetter func(store CatalogStoreInterface, cmd *models.Command) (*models.Command, error)
+
+func (s *CatalogService) importCatalog(
+	catalog *models.Catalog, strategy models.MergeStrategy, getMatchingCommand matchingCommandGetter,
+) (*ImportReport, error) {
+	commands := make([]*models.Command, 0, len(catalog.Commands))
+	for i, catalogCommand := range catalog.Commands {
+		cmd, err := getImportedCommand(i, catalogCommand)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+
+	report := &ImportReport{Added: 0, Overwritten: 0, Skipped: 0}
+	// all the commands are stored or none of them, a partial import would be
+	// duplicated when imported again
+	err := s.store.RunInTransaction(func(store CatalogStoreInterface) error {
+		for _, cmd := range commands {
+			if err := storeImportedCommand(store, cmd, strategy, getMatchingCommand, report); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Catalog imported",
+		"added", report.Added, "overwritten", report.Overwritten, "skipped", report.Skipped,
+	)
+	return report, nil
+}
+
+// storeImportedCommand stores the imported command using the merge strategy
+// and counts it in the report
+func storeImportedCommand(
+	store CatalogStoreInterface, cmd *models.Command, strategy models.MergeStrategy,
+	getMatchingCommand matchingCommandGetter, report *ImportReport,
+) error {
+	if cmd.Status == models.CommandStatusObsolete {
+		report.Skipped++
+		return nil
+	}
+	existing, err := getMatchingCommand(store, cmd)
+	if err != nil {
+		return err
+	}
+	switch {
+	case existing == nil:
+		err = store.SaveCommand(cmd)
+		report.Added++
+	case isSameImportedCommand(existing, cmd):
+		report.Skipped++
+		return nil
+	case strategy == models.MergeStrategyKeepBoth:
+		// the uuid identifies a single command
+		cmd.UUID = resource.NewUUID()
+		err = store.SaveCommand(cmd)
+		report.Added++
+	case strategy == models.MergeStrategyOverwrite:
+		cmd.ID = existing.ID
+		err = store.ReplaceCommand(cmd)
+		report.Overwritten++
+	default:
+		report.Skipped++
+		return nil
+	}
+	if err == nil {
+		err = store.SetCommandTags(cmd.ID, cmd.Tags)
+	}
+	if err == nil {
+		err = store.SetCommandFolder(cmd.ID, cmd.Folder)
+	}
+	return err
+}
+
+// isSameImportedCommand returns true if the imported command has the content,
+// the status, the folder and the tags of the existing command
+func isSameImportedCommand(existing *models.Command, cmd *models.Command) bool {
+	return existing.ContentHash == cmd.GetContentHash() && existing.Status == cmd.Status &&
+		existing.Folder == cmd.Folder && slices.Equal(slices.Sorted(slices.Values(existing.Tags)),
+		slices.Sorted(slices.Values(cmd.Tags)))
+}
+
+// getMatchingCommand returns the existing command having the uuid of the
+// imported command, or its script, nil if none
+func getMatchingCommand(store CatalogStoreInterface, cmd *models.Command) (*models.Command, error) {
+	existing, err := store.GetCommandByUUID(cmd.UUID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+	return store.GetCommandByScript(cmd.Script)
+}
+
+// getImportedCommand converts the command of the catalog, the missing
+// fields are defaulted and the fields rejected by the database are reported
+func getImportedCommand(index int, catalogCommand *models.CatalogCommand) (*models.Command, error) {
+	invalid := func(reason string) error {
+		return &InvalidCatalogCommandError{Reason: reason, Index: index}
+	}
+	if catalogCommand == nil || strings.TrimSpace(catalogCommand.Script) == "" {
+		return nil, invalid("the script is missing")
+	}
+	if catalogCommand.Status == "" {
+		catalogCommand.Status = models.CommandStatusSaved
+	}
+	if !slices.Contains(models.GetCommandStatuses(), catalogCommand.Status) {
+		return nil, invalid("unknown status " + string(catalogCommand.Status))
+	}
+	if catalogCommand.Lint != nil && !slices.Contains(models.GetLintStatuses(), catalogCommand.Lint.Status) {
+		return nil, invalid("unknown lint status " + string(catalogCommand.Lint.Status))
+	}
+	if catalogCommand.Created.IsZero() {
+		catalogCommand.Created = time.Now()
+	}
+	if catalogCommand.Modified.IsZero() {
+		catalogCommand.Modified = catalogCommand.Created
+	}
+	cmd := catalogCommand.ToCommand()
+	if reason := getInvalidFieldReason(cmd); reason != "" {
+		return nil, invalid(reason)
+	}
+	return cmd, nil
+}
+
+// truncateTitle returns the title cut to the maximum length of a command
+// title, counted in characters as the database does
+func truncateTitle(title string) string {
+	if utf8.RuneCountInString(title) <= commandTitleMaxLength {
+		return title
+	}
+	return strings.TrimSpace(string([]rune(title)[:commandTitleMaxLength]))
+}
+
+// getInvalidFieldReason returns the reason why the command would be rejected
+// by the database, empty if the command can be stored
+func getInvalidFieldReason(cmd *models.Command) string {
+	if strings.TrimSpace(cmd.Script) == "" {
+		return "the script is missing"
+	}
+	if utf8.RuneCountInString(cmd.Title) > commandTitleMaxLength {
+		return fmt.Sprintf("the title is longer than %d characters", commandTitleMaxLength)
+	}
+	if err := models.ValidateShortName(cmd.ShortName); err != nil {
+		return err.Error()
+	}
+	if !slices.Contains(models.GetShellDialects(), cmd.Shell) {
+		return "unknown shell " + string(cmd.Shell)
+	}
+	for _, tag := range cmd.Tags {
+		if tag == "" || utf8.RuneCountInString(tag) > tagTitleMaxLength {
+			return fmt.Sprintf("the tags must have between 1 and %d characters", tagTitleMaxLength)
+		}
+	}
+	for _, folder := range strings.Split(cmd.Folder, folderSeparator) {
+		if utf8.RuneCountInString(strings.TrimSpace(folder)) > tagTitleMaxLength {
+			return fmt.Sprintf("the folder names must have at most %d characters", tagTitleMaxLength)
+		}
+	}
+	return ""
+}
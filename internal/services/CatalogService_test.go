@@ -0,0 +1,361 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCatalogStore struct {
+	commands []*models.Command
+}
+
+func (m *MockCatalogStore) GetCommands(_ ...models.CommandStatus) ([]*models.Command, error) {
+	return m.commands, nil
+}
+
+func (m *MockCatalogStore) GetCommandByScript(script string) (*models.Command, error) {
+	for _, cmd := range m.commands {
+		if cmd.Script == script {
+			return cmd, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockCatalogStore) GetCommandByUUID(uuid resource.UUID) (*models.Command, error) {
+	for _, cmd := range m.commands {
+		if cmd.UUID == uuid {
+			return cmd, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockCatalogStore) SaveCommand(command *models.Command) error {
+	command.ID = resource.ID(len(m.commands) + 1)
+	command.ContentHash = command.GetContentHash()
+	m.commands = append(m.commands, command)
+	return nil
+}
+
+func (m *MockCatalogStore) ReplaceCommand(command *models.Command) error {
+	command.ContentHash = command.GetContentHash()
+	m.commands[command.ID-1] = command
+	return nil
+}
+
+func (m *MockCatalogStore) SetCommandTags(commandID resource.ID, tags []string) error {
+	m.commands[commandID-1].Tags = tags
+	return nil
+}
+
+func (m *MockCatalogStore) SetCommandFolder(commandID resource.ID, path string) error {
+	m.commands[commandID-1].Folder = path
+	return nil
+}
+
+func (m *MockCatalogStore) RunInTransaction(fn func(store CatalogStoreInterface) error) error {
+	return fn(m)
+}
+
+func newCatalogTestCommand(id int, title string, script string) *models.Command {
+	cmd := models.NewCommand(script, 0, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	cmd.ID = resource.ID(id)
+	cmd.Title = title
+	cmd.ModificationDatetime = cmd.CreationDatetime
+	cmd.Status = models.CommandStatusSaved
+	cmd.ContentHash = cmd.GetContentHash()
+	return cmd
+}
+
+func TestCatalogService_RoundTrip(t *testing.T) {
+	cmd := newCatalogTestCommand(2, "Backup", "pg_dump db\ngzip db.sql")
+	cmd.Description = "Dump the database"
+	cmd.Folder = "ops/db"
+	cmd.Tags = []string{"db", "backup"}
+	cmd.LintStatus = models.LintStatusWarning
+	cmd.LintIssues = `[{"code":2086,"level":"info"}]`
+	cmd.LintRules = `{"severity":"style"}`
+	cmd.ContentHash = cmd.GetContentHash()
+	source := &MockCatalogStore{commands: []*models.Command{
+		cmd,
+		newCatalogTestCommand(1, "", "ls -la"),
+	}}
+
+	for _, format := range []models.CatalogFormat{models.CatalogFormatJSON, models.CatalogFormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			catalog, err := NewCatalogService(source).ExportCatalog()
+			require.NoError(t, err)
+			var content bytes.Buffer
+			require.NoError(t, WriteCatalog(&content, catalog, format))
+
+			imported, err := ReadCatalog(&content, format)
+			require.NoError(t, err)
+			target := &MockCatalogStore{commands: []*models.Command{}}
+			report, err := NewCatalogService(target).ImportCatalog(imported, models.MergeStrategySkip)
+			require.NoError(t, err)
+			assert.Equal(t, &ImportReport{Added: 2, Overwritten: 0, Skipped: 0}, report)
+
+			require.Len(t, target.commands, 2)
+			assert.Equal(t, "ls -la", target.commands[0].Script, "commands are exported by id")
+			restored := target.commands[1]
+			assert.Equal(t, cmd.UUID, restored.UUID)
+			assert.Equal(t, cmd.ContentHash, restored.ContentHash)
+			assert.Equal(t, cmd.Title, restored.Title)
+			assert.Equal(t, cmd.Description, restored.Description)
+			assert.Equal(t, cmd.Script, restored.Script)
+			assert.Equal(t, cmd.Status, restored.Status)
+			assert.Equal(t, cmd.Folder, restored.Folder)
+			assert.Equal(t, cmd.Tags, restored.Tags)
+			assert.Equal(t, cmd.LintStatus, restored.LintStatus)
+			assert.JSONEq(t, cmd.LintIssues, restored.LintIssues)
+			assert.JSONEq(t, cmd.LintRules, restored.LintRules)
+			assert.True(t, cmd.CreationDatetime.Equal(restored.CreationDatetime))
+		})
+	}
+}
+
+func TestCatalogService_RoundTripObsoleteCopy(t *testing.T) {
+	live := newCatalogTestCommand(1, "Deploy", "make deploy ENV=prod")
+	obsolete := newCatalogTestCommand(2, "Deploy", "make deploy")
+	obsolete.UUID = live.UUID
+	obsolete.Status = models.CommandStatusObsolete
+	obsolete.ContentHash = obsolete.GetContentHash()
+	store := &MockCatalogStore{commands: []*models.Command{live, obsolete}}
+	service := NewCatalogService(store)
+
+	catalog, err := service.ExportCatalog()
+	require.NoError(t, err)
+	require.Len(t, catalog.Commands, 1, "the obsolete copy is not exported")
+	assert.Equal(t, live.Script, catalog.Commands[0].Script)
+
+	// a catalog exported before the obsolete copies were excluded
+	catalog.Commands = append(catalog.Commands, models.NewCatalogCommand(obsolete))
+	report, err := service.ImportCatalog(catalog, models.MergeStrategyOverwrite)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 0, Overwritten: 0, Skipped: 2}, report)
+	assert.Equal(t, "make deploy ENV=prod", store.commands[0].Script)
+	assert.Equal(t, models.CommandStatusSaved, store.commands[0].Status)
+}
+
+func TestCatalogService_ImportStrategies(t *testing.T) {
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands: []*models.CatalogCommand{
+			models.NewCatalogCommand(newCatalogTestCommand(0, "Imported title", "echo existing")),
+			models.NewCatalogCommand(newCatalogTestCommand(0, "New", "echo new")),
+		},
+	}
+	tests := []struct {
+		strategy       models.MergeStrategy
+		expectedReport ImportReport
+		expectedTitles []string
+	}{
+		{
+			strategy:       models.MergeStrategySkip,
+			expectedReport: ImportReport{Added: 1, Overwritten: 0, Skipped: 1},
+			expectedTitles: []string{"Local title", "New"},
+		},
+		{
+			strategy:       models.MergeStrategyOverwrite,
+			expectedReport: ImportReport{Added: 1, Overwritten: 1, Skipped: 0},
+			expectedTitles: []string{"Imported title", "New"},
+		},
+		{
+			strategy:       models.MergeStrategyKeepBoth,
+			expectedReport: ImportReport{Added: 2, Overwritten: 0, Skipped: 0},
+			expectedTitles: []string{"Local title", "Imported title", "New"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			store := &MockCatalogStore{commands: []*models.Command{
+				newCatalogTestCommand(1, "Local title", "echo existing"),
+			}}
+			report, err := NewCatalogService(store).ImportCatalog(catalog, tt.strategy)
+			require.NoError(t, err)
+			assert.Equal(t, &tt.expectedReport, report)
+			titles := []string{}
+			for _, cmd := range store.commands {
+				titles = append(titles, cmd.Title)
+			}
+			assert.Equal(t, tt.expectedTitles, titles)
+		})
+	}
+}
+
+func TestCatalogService_ImportRolledBackOnError(t *testing.T) {
+	dbService := openTestDBService(t, newLegacyTestDB(t), models.ShellDialectBash)
+	_, err := dbService.GetDBAdapter().GetDB().Exec(`CREATE TRIGGER fail_import BEFORE INSERT ON command
+		WHEN NEW.script = 'echo failing' BEGIN SELECT RAISE(ABORT, 'insert failed'); END`)
+	require.NoError(t, err)
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands: []*models.CatalogCommand{
+			models.NewCatalogCommand(newCatalogTestCommand(0, "Stored", "echo stored")),
+			models.NewCatalogCommand(newCatalogTestCommand(0, "Failing", "echo failing")),
+		},
+	}
+	catalog.Commands[0].Tags = []string{"imported"}
+
+	report, err := NewCatalogService(dbService).ImportCatalog(catalog, models.MergeStrategyKeepBoth)
+	require.ErrorContains(t, err, "insert failed")
+	assert.Nil(t, report)
+	commands, err := dbService.GetCommands()
+	require.NoError(t, err)
+	assert.Empty(t, commands, "the commands stored before the error are rolled back")
+
+	catalog.Commands = catalog.Commands[:1]
+	report, err = NewCatalogService(dbService).ImportCatalog(catalog, models.MergeStrategyKeepBoth)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 1, Overwritten: 0, Skipped: 0}, report)
+	commands, err = dbService.GetCommands()
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, []string{"imported"}, commands[0].Tags)
+}
+
+func TestCatalogService_ImportMatchingUUID(t *testing.T) {
+	local := newCatalogTestCommand(1, "Deploy", "make deploy")
+	t.Run("Script changed on the other machine", func(t *testing.T) {
+		remote := newCatalogTestCommand(0, "Deploy", "make deploy ENV=prod")
+		remote.UUID = local.UUID
+		store := &MockCatalogStore{commands: []*models.Command{local}}
+		report, err := NewCatalogService(store).ImportCatalog(&models.Catalog{
+			Version:    models.CatalogVersion,
+			ExportedAt: time.Now(),
+			Commands:   []*models.CatalogCommand{models.NewCatalogCommand(remote)},
+		}, models.MergeStrategyOverwrite)
+		require.NoError(t, err)
+		assert.Equal(t, &ImportReport{Added: 0, Overwritten: 1, Skipped: 0}, report)
+		require.Len(t, store.commands, 1)
+		assert.Equal(t, "make deploy ENV=prod", store.commands[0].Script)
+		assert.Equal(t, local.UUID, store.commands[0].UUID)
+	})
+
+	t.Run("Identical command is never duplicated", func(t *testing.T) {
+		store := &MockCatalogStore{commands: []*models.Command{local}}
+		report, err := NewCatalogService(store).ImportCatalog(&models.Catalog{
+			Version:    models.CatalogVersion,
+			ExportedAt: time.Now(),
+			Commands:   []*models.CatalogCommand{models.NewCatalogCommand(local)},
+		}, models.MergeStrategyKeepBoth)
+		require.NoError(t, err)
+		assert.Equal(t, &ImportReport{Added: 0, Overwritten: 0, Skipped: 1}, report)
+	})
+
+	t.Run("Kept copy gets a new uuid", func(t *testing.T) {
+		remote := newCatalogTestCommand(0, "Deploy to prod", "make deploy")
+		remote.UUID = local.UUID
+		store := &MockCatalogStore{commands: []*models.Command{local}}
+		_, err := NewCatalogService(store).ImportCatalog(&models.Catalog{
+			Version:    models.CatalogVersion,
+			ExportedAt: time.Now(),
+			Commands:   []*models.CatalogCommand{models.NewCatalogCommand(remote)},
+		}, models.MergeStrategyKeepBoth)
+		require.NoError(t, err)
+		require.Len(t, store.commands, 2)
+		assert.NotEqual(t, store.commands[0].UUID, store.commands[1].UUID)
+	})
+}
+
+func TestCatalogService_ImportTagsChanged(t *testing.T) {
+	local := newCatalogTestCommand(1, "Deploy", "make deploy")
+	remote := newCatalogTestCommand(0, "Deploy", "make deploy")
+	remote.UUID = local.UUID
+	remote.Tags = []string{"ops"}
+	store := &MockCatalogStore{commands: []*models.Command{local}}
+	report, err := NewCatalogService(store).ImportCatalog(&models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands:   []*models.CatalogCommand{models.NewCatalogCommand(remote)},
+	}, models.MergeStrategyOverwrite)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 0, Overwritten: 1, Skipped: 0}, report)
+	assert.Equal(t, []string{"ops"}, store.commands[0].Tags)
+}
+
+func TestCatalogService_ImportSyncedCatalog(t *testing.T) {
+	local := newCatalogTestCommand(1, "Deploy", "make deploy")
+	copied := newCatalogTestCommand(0, "Deploy to prod", "make deploy")
+	store := &MockCatalogStore{commands: []*models.Command{local}}
+	report, err := NewCatalogService(store).ImportSyncedCatalog(&models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands:   []*models.CatalogCommand{models.NewCatalogCommand(local), models.NewCatalogCommand(copied)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 1, Overwritten: 0, Skipped: 1}, report,
+		"the commands are only matched by uuid")
+	require.Len(t, store.commands, 2)
+	assert.Equal(t, copied.UUID, store.commands[1].UUID)
+}
+
+func TestTruncateTitle(t *testing.T) {
+	assert.Equal(t, "Short title", truncateTitle("Short title"))
+	accented := strings.Repeat("é", commandTitleMaxLength)
+	assert.Equal(t, accented, truncateTitle(accented), "the length is counted in characters")
+	truncated := truncateTitle(strings.Repeat("a", commandTitleMaxLength-1) + "éé")
+	assert.True(t, utf8.ValidString(truncated), "a character is never cut")
+	assert.Equal(t, strings.Repeat("a", commandTitleMaxLength-1)+"é", truncated)
+	assert.Equal(t, "Title", truncateTitle("Title"+strings.Repeat(" ", commandTitleMaxLength)))
+}
+
+func TestCatalogService_InvalidCatalog(t *testing.T) {
+	t.Run("Unsupported version", func(t *testing.T) {
+		_, err := ReadCatalog(strings.NewReader(`{"version": 2, "commands": []}`), models.CatalogFormatJSON)
+		var versionErr *UnsupportedCatalogVersionError
+		require.ErrorAs(t, err, &versionErr)
+		assert.Equal(t, 2, versionErr.Version)
+	})
+
+	t.Run("Markdown cannot be imported", func(t *testing.T) {
+		_, err := ReadCatalog(strings.NewReader("# Shell commands"), models.CatalogFormatMarkdown)
+		assert.ErrorIs(t, err, ErrCatalogFormatNotReadable)
+	})
+
+	t.Run("Nothing imported if a command is invalid", func(t *testing.T) {
+		catalog, err := ReadCatalog(strings.NewReader(
+			"version: 1\ncommands:\n  - script: echo ok\n  - script: echo ko\n    status: UNKNOWN\n",
+		), models.CatalogFormatYAML)
+		require.NoError(t, err)
+		store := &MockCatalogStore{commands: []*models.Command{}}
+		_, err = NewCatalogService(store).ImportCatalog(catalog, models.MergeStrategySkip)
+		var commandErr *InvalidCatalogCommandError
+		require.ErrorAs(t, err, &commandErr)
+		assert.Equal(t, 1, commandErr.Index)
+		assert.Empty(t, store.commands)
+	})
+
+	t.Run("Missing fields are defaulted", func(t *testing.T) {
+		catalog, err := ReadCatalog(strings.NewReader(
+			"version: 1\ncommands:\n  - script: \"#!/bin/zsh\\necho ok\"\n",
+		), models.CatalogFormatYAML)
+		require.NoError(t, err)
+		store := &MockCatalogStore{commands: []*models.Command{}}
+		_, err = NewCatalogService(store).ImportCatalog(catalog, models.MergeStrategySkip)
+		require.NoError(t, err)
+		require.Len(t, store.commands, 1)
+		assert.Equal(t, models.CommandStatusSaved, store.commands[0].Status)
+		assert.Equal(t, models.ShellDialectZsh, store.commands[0].Shell)
+		assert.Equal(t, models.LintStatusNotAvailable, store.commands[0].LintStatus)
+		assert.False(t, store.commands[0].CreationDatetime.IsZero())
+	})
+}
+
+func TestDetectCatalogFormat(t *testing.T) {
+	assert.Equal(t, models.CatalogFormatJSON, DetectCatalogFormat("catalog.JSON"))
+	assert.Equal(t, models.CatalogFormatYAML, DetectCatalogFormat("catalog.yml"))
+	assert.Equal(t, models.CatalogFormatYAML, DetectCatalogFormat(""))
+	assert.Equal(t, models.CatalogFormatMarkdown, DetectCatalogFormat("cheatsheet.md"))
+}
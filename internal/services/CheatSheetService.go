@@ -0,0 +1,169 @@
+package services
+
+import (
+	"cmp"
+	_ "embed"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+//go:embed templates/cheatsheet.md.tmpl
+var cheatSheetTemplate string
+
+// backticksRegexp matches the runs of backticks that would close a code fence
+//
+//nolint:gochecknoglobals // compiled once
+var backticksRegexp = regexp.MustCompile("`{3,}")
+
+// CheatSheetGroupBy is the field used to group the commands of a cheat sheet
+type CheatSheetGroupBy string
+
+const (
+	CheatSheetGroupByFolder CheatSheetGroupBy = "folder"
+	// CheatSheetGroupByTag lists a command in the group of each of its tags
+	CheatSheetGroupByTag CheatSheetGroupBy = "tag"
+
+	// minFenceLength is the length of the code fences of the scripts
+	minFenceLength = 3
+)
+
+// CheatSheet is the data rendered by the cheat sheet template
+type CheatSheet struct {
+	ExportedAt time.Time
+	GroupBy    CheatSheetGroupBy
+	Groups     []*CheatSheetGroup
+}
+
+// CheatSheetGroup lists the commands of a folder or of a tag, the name is
+// empty for the commands without folder or tag
+type CheatSheetGroup struct {
+	Name     string
+	Commands []*CheatSheetCommand
+}
+
+// CheatSheetCommand is a command of the catalog with the fields computed for the template
+type CheatSheetCommand struct {
+	*models.CatalogCommand
+	// Heading is the title of the command, or the first line of its script
+	Heading string
+	// Body is the script without the trailing new lines
+	Body string
+	// Fence is a code fence longer than the backtick runs of the script
+	Fence      string
+	LintStatus models.LintStatus
+}
+
+// CheatSheetService renders the commands of a catalog to a Markdown cheat
+// sheet using a template that can be overridden in the configuration
+type CheatSheetService struct {
+	config *ExportConfig
+}
+
+func NewCheatSheetService(config *ExportConfig) *CheatSheetService {
+	return &CheatSheetService{config: config}
+}
+
+// WriteCheatSheet renders the catalog using the template at templatePath,
+// the template of the configuration if empty, or the default template
+func (s *CheatSheetService) WriteCheatSheet(
+	writer io.Writer, catalog *models.Catalog, groupBy CheatSheetGroupBy, templatePath string,
+) error {
+	tmpl, err := s.getTemplate(cmp.Or(templatePath, s.config.CheatSheetTemplate))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, NewCheatSheet(catalog, groupBy))
+}
+
+// getTemplate parses the template file, or the default template if path is empty
+func (*CheatSheetService) getTemplate(path string) (*template.Template, error) {
+	content := cheatSheetTemplate
+	if path != "" {
+		expandedPath, err := expandHomeDir(path)
+		if err != nil {
+			return nil, &CheatSheetTemplateError{Path: path, Err: err}
+		}
+		file, err := os.ReadFile(expandedPath)
+		if err != nil {
+			return nil, &CheatSheetTemplateError{Path: path, Err: err}
+		}
+		content = string(file)
+	}
+	tmpl, err := template.New("cheatsheet").Funcs(template.FuncMap{
+		"date": func(date time.Time) string {
+			return date.Format(time.DateOnly)
+		},
+		"join": strings.Join,
+	}).Parse(content)
+	if err != nil {
+		return nil, &CheatSheetTemplateError{Path: path, Err: err}
+	}
+	return tmpl, nil
+}
+
+// NewCheatSheet groups the commands of the catalog by folder or by tag, the
+// groups are ordered by name, the commands without folder or tag last
+func NewCheatSheet(catalog *models.Catalog, groupBy CheatSheetGroupBy) *CheatSheet {
+	groups := map[string]*CheatSheetGroup{}
+	for _, catalogCommand := range catalog.Commands {
+		names := []string{catalogCommand.Folder}
+		if groupBy == CheatSheetGroupByTag {
+			names = catalogCommand.Tags
+			if len(names) == 0 {
+				names = []string{""}
+			}
+		}
+		cmd := newCheatSheetCommand(catalogCommand)
+		for _, name := range names {
+			if groups[name] == nil {
+				groups[name] = &CheatSheetGroup{Name: name, Commands: []*CheatSheetCommand{}}
+			}
+			groups[name].Commands = append(groups[name].Commands, cmd)
+		}
+	}
+	cheatSheet := &CheatSheet{
+		ExportedAt: catalog.ExportedAt,
+		GroupBy:    groupBy,
+		Groups:     make([]*CheatSheetGroup, 0, len(groups)),
+	}
+	for _, name := range slices.SortedFunc(maps.Keys(groups), compareGroupNames) {
+		cheatSheet.Groups = append(cheatSheet.Groups, groups[name])
+	}
+	return cheatSheet
+}
+
+// compareGroupNames orders the names case-insensitively, the empty name last
+func compareGroupNames(a, b string) int {
+	if (a == "") != (b == "") {
+		return strings.Compare(b, a)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func newCheatSheetCommand(catalogCommand *models.CatalogCommand) *CheatSheetCommand {
+	body := strings.TrimRight(catalogCommand.Script, "\n")
+	fenceLength := minFenceLength
+	for _, backticks := range backticksRegexp.FindAllString(body, -1) {
+		fenceLength = max(fenceLength, len(backticks)+1)
+	}
+	heading, _, _ := strings.Cut(strings.TrimSpace(getStepBody(body)), "\n")
+	lintStatus := models.LintStatusNotAvailable
+	if catalogCommand.Lint != nil {
+		lintStatus = catalogCommand.Lint.Status
+	}
+	return &CheatSheetCommand{
+		CatalogCommand: catalogCommand,
+		Heading:        cmp.Or(strings.TrimSpace(catalogCommand.Title), heading),
+		Body:           body,
+		Fence:          strings.Repeat("`", fenceLength),
+		LintStatus:     lintStatus,
+	}
+}
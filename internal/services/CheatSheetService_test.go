@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCheatSheetTestCatalog() *models.Catalog {
+	backup := newCatalogTestCommand(1, "Backup", "#!/bin/bash\npg_dump db | gzip > db.gz\n")
+	backup.Description = "Dump the **database**"
+	backup.Folder = "ops/db"
+	backup.Tags = []string{"db"}
+	backup.LintStatus = models.LintStatusWarning
+	markdown := newCatalogTestCommand(2, "", "cat <<'EOF'\n```\ncode\n```\nEOF")
+	markdown.Tags = []string{"docs", "db"}
+	pods := newCatalogTestCommand(3, "Pods", "kubectl get pods")
+	pods.Folder = "k8s"
+	return &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+		Commands: []*models.CatalogCommand{
+			models.NewCatalogCommand(backup),
+			models.NewCatalogCommand(markdown),
+			models.NewCatalogCommand(pods),
+		},
+	}
+}
+
+func TestCheatSheetService_WriteCheatSheet(t *testing.T) {
+	t.Run("Default template grouped by folder", func(t *testing.T) {
+		var content bytes.Buffer
+		service := NewCheatSheetService(&ExportConfig{BinDir: "", CheatSheetTemplate: ""})
+		require.NoError(t, service.WriteCheatSheet(&content, newCheatSheetTestCatalog(), CheatSheetGroupByFolder, ""))
+		assert.Equal(t, "# Shell commands\n\n"+
+			"## k8s\n\n"+
+			"### Pods\n\n```bash\nkubectl get pods\n```\n\n"+
+			"- Lint status: NOT_AVAILABLE\n- Last modified: 2025-01-02\n\n"+
+			"## ops/db\n\n"+
+			"### Backup\n\nDump the **database**\n\n"+
+			"```bash\n#!/bin/bash\npg_dump db | gzip > db.gz\n```\n\n"+
+			"- Lint status: WARNING\n- Last modified: 2025-01-02\n- Tags: db\n\n"+
+			"## Other commands\n\n"+
+			"### cat <<'EOF'\n\n````bash\ncat <<'EOF'\n```\ncode\n```\nEOF\n````\n\n"+
+			"- Lint status: NOT_AVAILABLE\n- Last modified: 2025-01-02\n- Tags: docs, db\n",
+			content.String())
+	})
+
+	t.Run("Grouped by tag", func(t *testing.T) {
+		cheatSheet := NewCheatSheet(newCheatSheetTestCatalog(), CheatSheetGroupByTag)
+		names := []string{}
+		for _, group := range cheatSheet.Groups {
+			names = append(names, group.Name)
+		}
+		assert.Equal(t, []string{"db", "docs", ""}, names)
+		assert.Len(t, cheatSheet.Groups[0].Commands, 2, "a command is listed under each of its tags")
+	})
+
+	t.Run("Template of the configuration", func(t *testing.T) {
+		templatePath := filepath.Join(t.TempDir(), "cheatsheet.md.tmpl")
+		require.NoError(t, os.WriteFile(templatePath, []byte(
+			"{{ range .Groups }}{{ range .Commands }}* {{ .Heading }} ({{ date .Modified }})\n{{ end }}{{ end }}",
+		), 0o600))
+		var content bytes.Buffer
+		service := NewCheatSheetService(&ExportConfig{BinDir: "", CheatSheetTemplate: templatePath})
+		catalog := newCheatSheetTestCatalog()
+		catalog.Filter(&models.CatalogFilter{Folder: "ops/", Tags: nil})
+		require.NoError(t, service.WriteCheatSheet(&content, catalog, CheatSheetGroupByFolder, ""))
+		assert.Equal(t, "* Backup (2025-01-02)\n", content.String())
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		templatePath := filepath.Join(t.TempDir(), "cheatsheet.md.tmpl")
+		require.NoError(t, os.WriteFile(templatePath, []byte("{{ range .Groups }}"), 0o600))
+		service := NewCheatSheetService(&ExportConfig{BinDir: "", CheatSheetTemplate: ""})
+		err := service.WriteCheatSheet(&bytes.Buffer{}, newCheatSheetTestCatalog(), CheatSheetGroupByFolder, templatePath)
+		var templateErr *CheatSheetTemplateError
+		require.ErrorAs(t, err, &templateErr)
+		assert.Equal(t, templatePath, templateErr.Path)
+	})
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+// recordIndent is the indentation of the JSON records
+const recordIndent = 2
+
+// tsvEscaper escapes the characters that cannot be part of a field of tab
+// separated values
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// getCommandRecordHeader returns the names of the fields of a record, in the
+// order of the tab separated values
+func getCommandRecordHeader() []string {
+	return []string{"id", "title", "script", "shell", "tags", "lintStatus"}
+}
+
+// WriteCommands writes the commands in the given format, the plain format
+// writes one line by command (see WriteCommandList)
+func WriteCommands(w io.Writer, commands []*models.Command, format models.OutputFormat) error {
+	if format == models.OutputFormatPlain {
+		return WriteCommandList(w, commands)
+	}
+	records := make([]*models.CommandRecord, 0, len(commands))
+	for _, cmd := range commands {
+		records = append(records, models.NewCommandRecord(cmd))
+	}
+	if format == models.OutputFormatJSON {
+		return writeJSONRecords(w, records)
+	}
+	return writeTSVRecords(w, records)
+}
+
+// WriteCommand writes the command in the given format, the plain format
+// writes all its fields (see WriteCommandDetails)
+func WriteCommand(w io.Writer, cmd *models.Command, format models.OutputFormat) error {
+	if format == models.OutputFormatPlain {
+		return WriteCommandDetails(w, cmd)
+	}
+	if format == models.OutputFormatJSON {
+		return writeJSONRecords(w, models.NewCommandRecord(cmd))
+	}
+	return writeTSVRecords(w, []*models.CommandRecord{models.NewCommandRecord(cmd)})
+}
+
+// FormatSelectedCommand returns the content of the output file written when
+// the command is selected for the shell, the script being the script of the
+// command with its placeholders filled. The plain format is the script alone.
+func FormatSelectedCommand(cmd *models.Command, script string, format models.OutputFormat) (string, error) {
+	if format == models.OutputFormatPlain {
+		return script, nil
+	}
+	selected := *cmd
+	selected.Script = script
+	var content strings.Builder
+	if err := WriteCommand(&content, &selected, format); err != nil {
+		return "", err
+	}
+	return content.String(), nil
+}
+
+// WriteCommandList writes one line by command: its id, its status and its
+// title separated by tabs, the first line of the script is written if the
+// command has no title
+func WriteCommandList(w io.Writer, commands []*models.Command) error {
+	for _, cmd := range commands {
+		if _, err := fmt.Fprintf(w, "%d\t%s\t%s\n", cmd.ID, cmd.Status, getCommandSummary(cmd)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCommandDetails writes the fields of the command, one field by line,
+// the description and the script are indented below their label
+func WriteCommandDetails(w io.Writer, cmd *models.Command) error {
+	fields := []string{
+		fmt.Sprintf("id: %d", cmd.ID),
+		"uuid: " + string(cmd.UUID),
+		"title: " + cmd.Title,
+		"status: " + string(cmd.Status),
+		"shell: " + string(cmd.Shell),
+		"folder: " + cmd.Folder,
+		"tags: " + strings.Join(cmd.Tags, ", "),
+		"short name: " + cmd.ShortName,
+		"lint: " + string(cmd.LintStatus),
+		"created: " + cmd.CreationDatetime.Format(time.DateTime),
+		"modified: " + cmd.ModificationDatetime.Format(time.DateTime),
+		"description:",
+	}
+	fields = append(fields, indentText(cmd.Description), "script:", indentText(cmd.Script))
+	if cmd.PlaceholderSources != "" {
+		fields = append(fields, "placeholders:", indentText(cmd.PlaceholderSources))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(fields, "\n"))
+	return err
+}
+
+// writeJSONRecords writes a record or a list of records as indented JSON
+func writeJSONRecords(w io.Writer, records any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", strings.Repeat(" ", recordIndent))
+	return encoder.Encode(records)
+}
+
+// writeTSVRecords writes the header line then one line by record, the tabs,
+// the new lines and the backslashes of the fields are escaped with a
+// backslash, the tags are separated by commas
+func writeTSVRecords(w io.Writer, records []*models.CommandRecord) error {
+	if _, err := fmt.Fprintln(w, strings.Join(getCommandRecordHeader(), "\t")); err != nil {
+		return err
+	}
+	for _, record := range records {
+		fields := []string{
+			fmt.Sprint(record.ID),
+			record.Title,
+			record.Script,
+			string(record.Shell),
+			strings.Join(record.Tags, ","),
+			string(record.LintStatus),
+		}
+		for i, field := range fields {
+			fields[i] = tsvEscaper.Replace(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getCommandSummary returns the title of the command, or the first line of
+// its script if it has no title
+func getCommandSummary(cmd *models.Command) string {
+	if cmd.Title != "" {
+		return cmd.Title
+	}
+	firstLine, _, multiLine := strings.Cut(cmd.Script, "\n")
+	if multiLine {
+		return firstLine + "..."
+	}
+	return firstLine
+}
+
+func indentText(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "  " + strings.ReplaceAll(text, "\n", "\n  ")
+}
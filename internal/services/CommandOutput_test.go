@@ -0,0 +1,85 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCommandList(t *testing.T) {
+	untitled := newCatalogTestCommand(3, "", "cd /tmp\nls -l")
+	var output bytes.Buffer
+	require.NoError(t, WriteCommandList(&output, []*models.Command{
+		newCatalogTestCommand(2, "Backup", "pg_dump db"),
+		untitled,
+	}))
+	assert.Equal(t, "2\tSAVED\tBackup\n3\tSAVED\tcd /tmp...\n", output.String(),
+		"the first line of the script is written if the command has no title")
+}
+
+func TestWriteCommandDetails(t *testing.T) {
+	cmd := newCatalogTestCommand(2, "Backup", "pg_dump db\ngzip db.sql")
+	cmd.Folder = "ops/db"
+	cmd.Tags = []string{"db", "backup"}
+	var output bytes.Buffer
+	require.NoError(t, WriteCommandDetails(&output, cmd))
+	assert.Equal(t, "id: 2\n"+
+		"uuid: "+string(cmd.UUID)+"\n"+
+		"title: Backup\n"+
+		"status: SAVED\n"+
+		"shell: bash\n"+
+		"folder: ops/db\n"+
+		"tags: db, backup\n"+
+		"short name: \n"+
+		"lint: NOT_AVAILABLE\n"+
+		"created: 2025-01-02 03:04:05\n"+
+		"modified: 2025-01-02 03:04:05\n"+
+		"description:\n\n"+
+		"script:\n  pg_dump db\n  gzip db.sql\n", output.String())
+}
+
+func TestWriteCommands(t *testing.T) {
+	cmd := newCatalogTestCommand(2, "Backup", "pg_dump db\tgzip")
+	cmd.Tags = []string{"db", "backup"}
+	cmd.LintStatus = models.LintStatusOK
+
+	t.Run("json", func(t *testing.T) {
+		var output bytes.Buffer
+		require.NoError(t, WriteCommands(&output, []*models.Command{cmd}, models.OutputFormatJSON))
+		assert.JSONEq(t, `[{"id": 2, "title": "Backup", "script": "pg_dump db\tgzip", "shell": "bash",
+			"tags": ["db", "backup"], "lintStatus": "OK"}]`, output.String())
+	})
+
+	t.Run("json without command", func(t *testing.T) {
+		var output bytes.Buffer
+		require.NoError(t, WriteCommands(&output, []*models.Command{}, models.OutputFormatJSON))
+		assert.Equal(t, "[]\n", output.String())
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		var output bytes.Buffer
+		untitled := newCatalogTestCommand(3, "", "cd /tmp\nls -l")
+		require.NoError(t, WriteCommands(&output, []*models.Command{cmd, untitled}, models.OutputFormatTSV))
+		assert.Equal(t, "id\ttitle\tscript\tshell\ttags\tlintStatus\n"+
+			"2\tBackup\tpg_dump db\\tgzip\tbash\tdb,backup\tOK\n"+
+			"3\t\tcd /tmp\\nls -l\tbash\t\tNOT_AVAILABLE\n", output.String(),
+			"the tabs and the new lines of the fields are escaped")
+	})
+}
+
+func TestFormatSelectedCommand(t *testing.T) {
+	cmd := newCatalogTestCommand(2, "Greet", "echo <name>")
+
+	content, err := FormatSelectedCommand(cmd, "echo world", models.OutputFormatPlain)
+	require.NoError(t, err)
+	assert.Equal(t, "echo world", content, "the plain format is the script alone")
+
+	content, err = FormatSelectedCommand(cmd, "echo world", models.OutputFormatJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": 2, "title": "Greet", "script": "echo world", "shell": "bash",
+		"tags": [], "lintStatus": "NOT_AVAILABLE"}`, content, "the script has its placeholders filled")
+	assert.Equal(t, "echo <name>", cmd.Script, "the command is not modified")
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	pkgSearch "github.com/fchastanet/shell-command-bookmarker/pkg/search"
+)
+
+// MatchCommand returns true if the command matches the query, the score is
+// the highest for an exact match of the id, the title, the description or
+// the script, then for a substring of these fields, fuzzy matching is used
+// otherwise. An empty query matches all the commands.
+func MatchCommand(query string, cmd *models.Command) (matched bool, score int) {
+	if query == "" {
+		return true, 0
+	}
+
+	// Try exact match first (fastest)
+	if cmd.Title == query ||
+		cmd.Description == query ||
+		cmd.Script == query ||
+		strconv.Itoa(int(cmd.GetID())) == query {
+		return true, pkgSearch.MaxScore
+	}
+
+	// Check if the query is a substring of any of the fields
+	col := cmd.Title + " " + cmd.Description + " " + cmd.Script
+	if strings.Contains(strings.ToLower(col), strings.ToLower(query)) {
+		return true, pkgSearch.MaxScore - 1
+	}
+
+	// Try advanced scoring if needed
+	score = pkgSearch.FuzzyMatchScore(col, query)
+	return score > pkgSearch.ScoreThreshold, score
+}
+
+// SearchCommands returns the commands matching the query, the best matches
+// first, the FilterScore of the commands is set to their score
+func SearchCommands(query string, commands []*models.Command) []*models.Command {
+	matches := make([]*models.Command, 0, len(commands))
+	for _, cmd := range commands {
+		if matched, score := MatchCommand(query, cmd); matched {
+			cmd.FilterScore = score
+			matches = append(matches, cmd)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b *models.Command) int {
+		return cmp.Compare(b.FilterScore, a.FilterScore)
+	})
+	return matches
+}
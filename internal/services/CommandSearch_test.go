@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	pkgSearch "github.com/fchastanet/shell-command-bookmarker/pkg/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchCommand(t *testing.T) {
+	cmd := newCatalogTestCommand(12, "List containers", "docker ps --all")
+	tests := []struct {
+		query     string
+		wantMatch bool
+		wantScore int
+	}{
+		{"", true, 0},
+		{"12", true, pkgSearch.MaxScore},
+		{"List containers", true, pkgSearch.MaxScore},
+		{"DOCKER PS", true, pkgSearch.MaxScore - 1},
+		{"kubectl get pods", false, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matched, score := MatchCommand(tt.query, cmd)
+			assert.Equal(t, tt.wantMatch, matched)
+			if tt.wantScore >= 0 {
+				assert.Equal(t, tt.wantScore, score)
+			}
+		})
+	}
+}
+
+func TestSearchCommands(t *testing.T) {
+	partial := newCatalogTestCommand(1, "Backup", "pg_dump db > backup.sql")
+	exact := newCatalogTestCommand(2, "", "pg_dump")
+	other := newCatalogTestCommand(3, "Restart", "systemctl restart nginx")
+
+	matches := SearchCommands("pg_dump", []*models.Command{partial, exact, other})
+	assert.Equal(t, []*models.Command{exact, partial}, matches, "the best matches are first")
+	assert.Equal(t, pkgSearch.MaxScore, exact.FilterScore)
+}
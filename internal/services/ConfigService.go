@@ -0,0 +1,302 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of the YAML configuration file
+type Config struct {
+	Lint    LintConfig    `yaml:"lint"`
+	Run     RunConfig     `yaml:"run"`
+	Export  ExportConfig  `yaml:"export"`
+	Library LibraryConfig `yaml:"library"`
+	Sync    SyncConfig    `yaml:"sync"`
+	// Catalogs are the read-only team catalogs displayed with the commands
+	Catalogs []TeamCatalogConfig `yaml:"catalogs"`
+}
+
+// RunConfig is the run section of the configuration file, eg:
+//
+//	run:
+//	  shell: /bin/bash
+type RunConfig struct {
+	// Shell runs the commands, the shell of each command is used if empty
+	Shell string `yaml:"shell"`
+}
+
+// ExportConfig is the export section of the configuration file, eg:
+//
+//	export:
+//	  binDir: ~/.local/bin
+//	  cheatSheetTemplate: ~/.config/shell-command-bookmarker/cheatsheet.md.tmpl
+type ExportConfig struct {
+	// BinDir is the directory the commands can be exported to as executable scripts
+	BinDir string `yaml:"binDir"`
+	// CheatSheetTemplate is the Go template of the Markdown export, the
+	// built-in template is used if empty
+	CheatSheetTemplate string `yaml:"cheatSheetTemplate"`
+}
+
+// LibraryConfig is the library section of the configuration file, eg:
+//
+//	library:
+//	  bash: ~/.config/shell-command-bookmarker/library.bash
+//	  zsh: ~/.config/shell-command-bookmarker/library.zsh
+type LibraryConfig struct {
+	// Bash is the file the bash library is written to, not written if empty
+	Bash string `yaml:"bash"`
+	// Zsh is the file the zsh library is written to, not written if empty
+	Zsh string `yaml:"zsh"`
+}
+
+// GetPaths returns the configured library files by shell
+func (c *LibraryConfig) GetPaths() map[models.ShellDialect]string {
+	paths := map[models.ShellDialect]string{}
+	if c.Bash != "" {
+		paths[models.ShellDialectBash] = c.Bash
+	}
+	if c.Zsh != "" {
+		paths[models.ShellDialectZsh] = c.Zsh
+	}
+	return paths
+}
+
+// SyncConfig is the sync section of the configuration file, eg:
+//
+//	sync:
+//	  dir: ~/.config/shell-command-bookmarker/sync
+//	  remote: git@github.com:me/bookmarks.git
+//	  branch: main
+type SyncConfig struct {
+	// Dir is the git repository the commands are synced with, a directory of
+	// the configuration directory is used if empty
+	Dir string `yaml:"dir"`
+	// Remote is the url of the git remote, the commands are only committed if empty
+	Remote string `yaml:"remote"`
+	// Branch is the branch pulled and pushed, main if empty
+	Branch string `yaml:"branch"`
+}
+
+// TeamCatalogConfig is an entry of the catalogs section of the configuration file, eg:
+//
+//	catalogs:
+//	  - name: team
+//	    path: /mnt/shared/team.db
+//	  - name: ops
+//	    path: /mnt/shared/ops-catalog
+type TeamCatalogConfig struct {
+	// Name is the badge of the commands of the catalog, the base name of the
+	// path without extension is used if empty
+	Name string `yaml:"name"`
+	// Path is a database file, or a directory of catalog files and sync
+	// repository command files
+	Path string `yaml:"path"`
+}
+
+// LintConfig is the lint section of the configuration file, eg:
+//
+//	lint:
+//	  exclude: [SC2086]
+//	  enable: [require-variable-braces]
+//	  severity: info
+//	  warningLevel: warning
+//	  errorLevel: error
+//	  tags:
+//	    legacy:
+//	      exclude: [SC2006]
+//	  linters: [shellcheck, syntax, rules, format]
+//	  customRules:
+//	    - name: no-docker-latest
+//	      pattern: 'docker\s+run\s+\S+:latest'
+//	      message: Use a pinned image version
+//	      level: warning
+//	  format:
+//	    indent: 2
+type LintConfig struct {
+	// Tags overrides the rules for the commands having the tag
+	Tags map[string]models.LintRules `yaml:"tags"`
+	// Linters is the list of the linters run in sequence, all the linters but
+	// format are run if empty
+	Linters []string `yaml:"linters"`
+	// CustomRules are added to the built-in rules, a rule with the name of
+	// a built-in rule replaces it
+	CustomRules      []CustomLintRule `yaml:"customRules"`
+	Format           FormatConfig     `yaml:"format"`
+	models.LintRules `yaml:",inline"`
+}
+
+// CustomLintRule reports an issue for each match of the pattern in the script
+type CustomLintRule struct {
+	Name    string           `yaml:"name"`
+	Pattern string           `yaml:"pattern"`
+	Message string           `yaml:"message"`
+	Level   models.LintLevel `yaml:"level"`
+	// Disabled allows to disable a built-in rule
+	Disabled bool `yaml:"disabled"`
+}
+
+// FormatConfig is the shfmt style used to format the scripts
+type FormatConfig struct {
+	// Indent is the number of spaces used to indent, tabs are used if 0
+	Indent           uint `yaml:"indent"`
+	BinaryNextLine   bool `yaml:"binaryNextLine"`
+	SwitchCaseIndent bool `yaml:"switchCaseIndent"`
+	SpaceRedirects   bool `yaml:"spaceRedirects"`
+}
+
+// ConfigService loads the configuration file
+type ConfigService struct {
+	config     *Config
+	configPath string
+}
+
+// NewConfigService creates a config service reading the given file,
+// the default configuration file is used if configPath is empty
+func NewConfigService(configPath string) *ConfigService {
+	return &ConfigService{
+		configPath: configPath,
+		config:     newDefaultConfig(),
+	}
+}
+
+func newDefaultConfig() *Config {
+	return &Config{
+		Lint: LintConfig{
+			Tags:        map[string]models.LintRules{},
+			Linters:     []string{},
+			CustomRules: []CustomLintRule{},
+			Format: FormatConfig{
+				Indent:           0,
+				BinaryNextLine:   false,
+				SwitchCaseIndent: false,
+				SpaceRedirects:   false,
+			},
+			LintRules: models.LintRules{
+				Severity:     "",
+				WarningLevel: "",
+				ErrorLevel:   "",
+				Exclude:      []string{},
+				Enable:       []string{},
+				Sources:      []string{},
+			},
+		},
+		Run: RunConfig{
+			Shell: "",
+		},
+		Export: ExportConfig{
+			BinDir:             "",
+			CheatSheetTemplate: "",
+		},
+		Library: LibraryConfig{
+			Bash: "",
+			Zsh:  "",
+		},
+		Sync: SyncConfig{
+			Dir:    "",
+			Remote: "",
+			Branch: "",
+		},
+		Catalogs: []TeamCatalogConfig{},
+	}
+}
+
+// GetDefaultConfigPath returns ~/.config/shell-command-bookmarker/config.yaml
+func GetDefaultConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		slog.Warn("Unable to find user config directory", "error", err)
+		return ""
+	}
+	return filepath.Join(configDir, "shell-command-bookmarker", "config.yaml")
+}
+
+// Init loads the configuration file, a missing default configuration file is not an error
+func (s *ConfigService) Init() error {
+	configPath := s.configPath
+	if configPath == "" {
+		configPath = GetDefaultConfigPath()
+	}
+	if configPath == "" {
+		return nil
+	}
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && s.configPath == "" {
+			slog.Debug("No configuration file", "file", configPath)
+			return nil
+		}
+		slog.Error("Error reading configuration file", "file", configPath, "error", err)
+		return &ConfigLoadError{File: configPath, Err: err}
+	}
+
+	config := newDefaultConfig()
+	if err := yaml.Unmarshal(content, config); err != nil {
+		slog.Error("Error parsing configuration file", "file", configPath, "error", err)
+		return &ConfigLoadError{File: configPath, Err: err}
+	}
+	if err := config.Validate(); err != nil {
+		return &ConfigLoadError{File: configPath, Err: err}
+	}
+	s.config = config
+	slog.Info("Configuration loaded", "file", configPath)
+	return nil
+}
+
+// GetConfig returns the loaded configuration
+func (s *ConfigService) GetConfig() *Config {
+	return s.config
+}
+
+// Validate checks the values of the configuration
+func (c *Config) Validate() error {
+	if err := c.Lint.Validate(); err != nil {
+		return err
+	}
+	for _, rules := range c.Lint.Tags {
+		if err := rules.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, linter := range c.Lint.Linters {
+		if !slices.Contains(GetLinterNames(), linter) {
+			return &UnknownLinterError{Linter: linter}
+		}
+	}
+	for _, rule := range c.Lint.CustomRules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, catalog := range c.Catalogs {
+		if catalog.Path == "" {
+			return &TeamCatalogError{Err: ErrTeamCatalogPathMissing, Name: catalog.Name, Path: ""}
+		}
+	}
+	return nil
+}
+
+// Validate checks that the rule has a name, a valid pattern and a valid level
+func (r *CustomLintRule) Validate() error {
+	if r.Name == "" {
+		return &InvalidCustomLintRuleError{Name: r.Name, Err: ErrMissingRuleName}
+	}
+	if r.Disabled {
+		return nil
+	}
+	if _, err := regexp.Compile(r.Pattern); err != nil || r.Pattern == "" {
+		return &InvalidCustomLintRuleError{Name: r.Name, Err: cmp.Or(err, ErrMissingRulePattern)}
+	}
+	if r.Level != "" && !r.Level.IsValid() {
+		return &InvalidCustomLintRuleError{Name: r.Name, Err: &models.InvalidLintLevelError{Level: string(r.Level)}}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_Init(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+		return configPath
+	}
+
+	t.Run("Lint section", func(t *testing.T) {
+		service := NewConfigService(writeConfig(t, `
+lint:
+  exclude: [SC2086]
+  enable: [require-variable-braces]
+  severity: info
+  tags:
+    legacy:
+      errorLevel: warning
+run:
+  shell: /bin/zsh
+`))
+		require.NoError(t, service.Init())
+		assert.Equal(t, "/bin/zsh", service.GetConfig().Run.Shell)
+		lint := service.GetConfig().Lint
+		assert.Equal(t, []string{"SC2086"}, lint.Exclude)
+		assert.Equal(t, []string{"require-variable-braces"}, lint.Enable)
+		assert.Equal(t, models.LintLevelInfo, lint.Severity)
+		assert.Equal(t, models.LintLevelWarning, lint.Tags["legacy"].ErrorLevel)
+	})
+
+	t.Run("Library section", func(t *testing.T) {
+		service := NewConfigService(writeConfig(t, "library:\n  zsh: ~/.zsh/bookmarks.zsh\n"))
+		require.NoError(t, service.Init())
+		assert.Equal(t,
+			map[models.ShellDialect]string{models.ShellDialectZsh: "~/.zsh/bookmarks.zsh"},
+			service.GetConfig().Library.GetPaths(),
+		)
+	})
+
+	t.Run("Invalid level", func(t *testing.T) {
+		service := NewConfigService(writeConfig(t, "lint:\n  tags:\n    legacy:\n      severity: fatal\n"))
+		err := service.Init()
+		var loadErr *ConfigLoadError
+		require.ErrorAs(t, err, &loadErr)
+		var levelErr *models.InvalidLintLevelError
+		assert.ErrorAs(t, err, &levelErr)
+	})
+
+	t.Run("Unknown linter and invalid rule", func(t *testing.T) {
+		service := NewConfigService(writeConfig(t, "lint:\n  linters: [shellcheck, pylint]\n"))
+		var linterErr *UnknownLinterError
+		assert.ErrorAs(t, service.Init(), &linterErr)
+
+		service = NewConfigService(writeConfig(t, "lint:\n  customRules:\n    - name: broken\n      pattern: '('\n"))
+		var ruleErr *InvalidCustomLintRuleError
+		assert.ErrorAs(t, service.Init(), &ruleErr)
+	})
+
+	t.Run("Team catalog without path", func(t *testing.T) {
+		service := NewConfigService(writeConfig(t, "catalogs:\n  - name: team\n"))
+		assert.ErrorIs(t, service.Init(), ErrTeamCatalogPathMissing)
+	})
+
+	t.Run("Missing explicit file", func(t *testing.T) {
+		service := NewConfigService(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.ErrorIs(t, service.Init(), os.ErrNotExist)
+	})
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"log/slog"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Danger rules
+const (
+	DangerRuleRecursiveRemove     = "recursive-remove"
+	DangerRuleDeviceOverwrite     = "device-overwrite"
+	DangerRuleDiskFormat          = "disk-format"
+	DangerRuleGitForcePush        = "git-force-push"
+	DangerRuleGitDiscard          = "git-discard"
+	DangerRuleRecursivePermission = "recursive-permission"
+	DangerRuleDestructiveSQL      = "destructive-sql"
+	DangerRuleResourceDeletion    = "resource-deletion"
+	DangerRuleShutdown            = "shutdown"
+	DangerRuleUnverified          = "unverified"
+)
+
+// maxClassifiedScripts is the number of classified scripts kept in the cache,
+// the oldest ones are removed first
+const maxClassifiedScripts = 10000
+
+// destructiveSQLRegexp matches the SQL statements removing tables or all the rows of a table
+//
+//nolint:gochecknoglobals // compiled once
+var destructiveSQLRegexp = regexp.MustCompile(
+	`(?i)\b(drop\s+(table|database|schema)|truncate(\s+table)?\s+\w|delete\s+from\s+[\w."]+\s*(;|$))`,
+)
+
+// Danger is a risky operation found in a script
+type Danger struct {
+	// Rule identifies the rule detecting the danger (see DangerRule* constants)
+	Rule string
+	// Reason describes the risk
+	Reason string
+	// Command is the part of the script doing the risky operation
+	Command string
+}
+
+func (d Danger) String() string {
+	return d.Command + ": " + d.Reason
+}
+
+// DescribeDangers returns the description of the dangers, one per line
+func DescribeDangers(dangers []Danger) string {
+	descriptions := make([]string, 0, len(dangers))
+	for _, danger := range dangers {
+		descriptions = append(descriptions, danger.String())
+	}
+	return strings.Join(descriptions, "\n")
+}
+
+// DangerService detects the risky operations of the scripts, eg: removing
+// files recursively or overwriting a device, using the syntax tree of the script
+type DangerService struct {
+	// cache stores the dangers by hash of the dialect and of the script as
+	// the command list classifies all the commands at each reload
+	cache map[[sha256.Size]byte][]Danger
+	// cacheOrder lists the cache keys from the oldest to the newest
+	cacheOrder [][sha256.Size]byte
+	mu         sync.Mutex
+}
+
+func NewDangerService() *DangerService {
+	return &DangerService{
+		cache:      make(map[[sha256.Size]byte][]Danger),
+		cacheOrder: [][sha256.Size]byte{},
+		mu:         sync.Mutex{},
+	}
+}
+
+// Classify returns the dangers of the script, the unverified danger is
+// reported if the script cannot be parsed
+func (s *DangerService) Classify(script string, dialect models.ShellDialect) []Danger {
+	cacheKey := sha256.Sum256([]byte(string(dialect) + "\x1f" + script))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if dangers, ok := s.cache[cacheKey]; ok {
+		return dangers
+	}
+	// the placeholders are not shell syntax, eg: <pod> would be a redirection
+	masked, placeholders := models.MaskPlaceholders(script)
+	dangers := classifyScript(masked, dialect)
+	for i := range dangers {
+		dangers[i].Command = models.UnmaskPlaceholders(dangers[i].Command, placeholders)
+	}
+	if len(s.cacheOrder) >= maxClassifiedScripts {
+		delete(s.cache, s.cacheOrder[0])
+		s.cacheOrder = s.cacheOrder[1:]
+	}
+	s.cache[cacheKey] = dangers
+	s.cacheOrder = append(s.cacheOrder, cacheKey)
+	return dangers
+}
+
+// ClassifyCommand returns the dangers of the script of the command
+func (s *DangerService) ClassifyCommand(command *models.Command) []Danger {
+	return s.Classify(command.Script, command.Shell)
+}
+
+func classifyScript(script string, dialect models.ShellDialect) []Danger {
+	variant, ok := getLangVariant(dialect)
+	if !ok {
+		// zsh scripts are close enough to bash scripts for this purpose
+		variant = syntax.LangBash
+	}
+	parser := syntax.NewParser(syntax.Variant(variant))
+	file, err := parser.Parse(strings.NewReader(script), "")
+	if err != nil {
+		slog.Debug("Script partially classified as it cannot be parsed", "error", err)
+		return classifyUnparsedScript(parser, script)
+	}
+	return uniqueDangers(classifyFile(file))
+}
+
+// classifyUnparsedScript classifies the lines of the script that can be parsed
+// alone, the unverified danger is reported as the other lines could hide a
+// risky operation, eg: zsh specific syntax
+func classifyUnparsedScript(parser *syntax.Parser, script string) []Danger {
+	firstLine, _, _ := strings.Cut(script, "\n")
+	dangers := []Danger{{
+		Rule:    DangerRuleUnverified,
+		Reason:  "cannot be parsed, its risky operations cannot be detected",
+		Command: firstLine,
+	}}
+	for _, line := range strings.Split(script, "\n") {
+		if file, err := parser.Parse(strings.NewReader(line), ""); err == nil {
+			dangers = append(dangers, classifyFile(file)...)
+		}
+	}
+	return uniqueDangers(dangers)
+}
+
+// classifyFile returns the dangers of the commands and of the redirections
+// of the syntax tree
+func classifyFile(file *syntax.File) []Danger {
+	dangers := []Danger{}
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.CallExpr:
+			dangers = append(dangers, classifyCall(node)...)
+		case *syntax.Redirect:
+			dangers = append(dangers, classifyRedirect(node)...)
+		}
+		return true
+	})
+	return dangers
+}
+
+// uniqueDangers removes the dangers reported several times, eg: the same
+// command repeated in the script
+func uniqueDangers(dangers []Danger) []Danger {
+	seen := make(map[Danger]bool, len(dangers))
+	result := make([]Danger, 0, len(dangers))
+	for _, danger := range dangers {
+		if !seen[danger] {
+			seen[danger] = true
+			result = append(result, danger)
+		}
+	}
+	return result
+}
+
+// shellWord is a word of a command with its value once unquoted
+type shellWord struct {
+	value string
+	// dynamic is true if the word contains an expansion, eg: $DIR or $(pwd)
+	dynamic bool
+}
+
+// getShellWord unquotes the word, the expansions are kept as written
+func getShellWord(word *syntax.Word) shellWord {
+	result := shellWord{value: "", dynamic: false}
+	appendWordParts(&result, word.Parts)
+	return result
+}
+
+func appendWordParts(result *shellWord, parts []syntax.WordPart) {
+	for _, part := range parts {
+		switch part := part.(type) {
+		case *syntax.Lit:
+			result.value += part.Value
+		case *syntax.SglQuoted:
+			result.value += part.Value
+		case *syntax.DblQuoted:
+			appendWordParts(result, part.Parts)
+		default:
+			result.value += printNode(part)
+			result.dynamic = true
+		}
+	}
+}
+
+func printNode(node syntax.Node) string {
+	var buffer bytes.Buffer
+	if err := syntax.NewPrinter(syntax.SingleLine(true)).Print(&buffer, node); err != nil {
+		return ""
+	}
+	return buffer.String()
+}
+
+// getWrappedCommandWords removes the commands running the command given as
+// argument, eg: sudo or env, with their options
+func getWrappedCommandWords(words []shellWord) []shellWord {
+	wrappers := []string{"sudo", "doas", "env", "nohup", "time", "command", "exec", "nice", "xargs"}
+	for len(words) > 0 && slices.Contains(wrappers, path.Base(words[0].value)) {
+		words = words[1:]
+		for len(words) > 0 && (strings.HasPrefix(words[0].value, "-") || strings.Contains(words[0].value, "=")) {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+// splitOptions returns the options and the other arguments
+func splitOptions(args []shellWord) (options []string, operands []shellWord) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg.value, "-") && len(arg.value) > 1 {
+			options = append(options, arg.value)
+		} else {
+			operands = append(operands, arg)
+		}
+	}
+	return options, operands
+}
+
+// hasOption returns true if one of the long options is present or if one of
+// the short options letters is present in a group of short options, eg: -rf
+func hasOption(options []string, shortLetters string, longOptions ...string) bool {
+	for _, option := range options {
+		if strings.HasPrefix(option, "--") {
+			if slices.Contains(longOptions, strings.SplitN(option, "=", 2)[0]) {
+				return true
+			}
+			continue
+		}
+		if shortLetters != "" && strings.ContainsAny(option[1:], shortLetters) {
+			return true
+		}
+	}
+	return false
+}
+
+// getRiskyTarget returns the reason why removing or changing the path
+// recursively is risky, an empty string if it is not
+func getRiskyTarget(operand shellWord) string {
+	switch {
+	case slices.Contains([]string{"/", "/*", "~", "~/", "~/*", "*", ".", "..", "$HOME", "$HOME/"}, operand.value):
+		return "targets " + operand.value
+	case operand.dynamic && strings.HasPrefix(operand.value, "$"):
+		return "targets a path starting with a variable, an empty variable would target /"
+	default:
+		return ""
+	}
+}
+
+//nolint:cyclop,funlen // one case per command
+func classifyCall(call *syntax.CallExpr) []Danger {
+	words := make([]shellWord, 0, len(call.Args))
+	for _, arg := range call.Args {
+		words = append(words, getShellWord(arg))
+	}
+	dangers := []Danger{}
+	command := printNode(call)
+	for _, word := range words {
+		if match := destructiveSQLRegexp.FindString(word.value); match != "" {
+			dangers = append(dangers, Danger{
+				Rule:    DangerRuleDestructiveSQL,
+				Reason:  "runs the destructive SQL statement " + strings.ToUpper(strings.Join(strings.Fields(match), " ")),
+				Command: command,
+			})
+		}
+	}
+	words = getWrappedCommandWords(words)
+	if len(words) == 0 {
+		return dangers
+	}
+	name := path.Base(words[0].value)
+	options, operands := splitOptions(words[1:])
+	newDanger := func(rule string, reason string) Danger {
+		return Danger{Rule: rule, Reason: reason, Command: command}
+	}
+
+	switch {
+	case name == "rm" && hasOption(options, "rR", "--recursive"):
+		for _, operand := range operands {
+			if target := getRiskyTarget(operand); target != "" {
+				return append(dangers, newDanger(DangerRuleRecursiveRemove, "removes files recursively, "+target))
+			}
+		}
+		if hasOption(options, "f", "--force") {
+			dangers = append(dangers, newDanger(DangerRuleRecursiveRemove, "removes files recursively without confirmation"))
+		}
+	case name == "dd":
+		for _, operand := range operands {
+			if device, ok := strings.CutPrefix(operand.value, "of="); ok && isDevice(device) {
+				dangers = append(dangers, newDanger(DangerRuleDeviceOverwrite, "overwrites the device "+device))
+			}
+		}
+	case name == "mkfs" || strings.HasPrefix(name, "mkfs."):
+		dangers = append(dangers, newDanger(DangerRuleDiskFormat, "formats a filesystem, erasing its data"))
+	case slices.Contains([]string{"wipefs", "fdisk", "sfdisk", "parted"}, name):
+		dangers = append(dangers, newDanger(DangerRuleDiskFormat, "changes the partitions or the signatures of a disk"))
+	case name == "shred":
+		dangers = append(dangers, newDanger(DangerRuleDiskFormat, "overwrites files to make them unrecoverable"))
+	case slices.Contains([]string{"chmod", "chown", "chgrp"}, name) && hasOption(options, "R", "--recursive"):
+		for _, operand := range operands {
+			if target := getRiskyTarget(operand); target != "" {
+				return append(dangers, newDanger(DangerRuleRecursivePermission, "changes permissions recursively, "+target))
+			}
+		}
+	case name == "git":
+		dangers = append(dangers, classifyGitCall(words[1:], newDanger)...)
+	case name == "kubectl" && len(operands) > 0 && operands[0].value == "delete":
+		dangers = append(dangers, newDanger(DangerRuleResourceDeletion, "deletes kubernetes resources"))
+	case name == "terraform" && len(operands) > 0 && operands[0].value == "destroy":
+		dangers = append(dangers, newDanger(DangerRuleResourceDeletion, "destroys the infrastructure managed by terraform"))
+	case name == "docker" && slices.ContainsFunc(operands, func(operand shellWord) bool { return operand.value == "prune" }):
+		dangers = append(dangers, newDanger(DangerRuleResourceDeletion, "deletes docker data"))
+	case slices.Contains([]string{"shutdown", "reboot", "halt", "poweroff"}, name):
+		dangers = append(dangers, newDanger(DangerRuleShutdown, "stops or restarts the machine"))
+	}
+	return dangers
+}
+
+// classifyGitCall detects the git commands rewriting the remote history
+// or discarding the local changes
+func classifyGitCall(args []shellWord, newDanger func(rule string, reason string) Danger) []Danger {
+	// skip the global options, -C and -c have a value
+	for len(args) > 0 && strings.HasPrefix(args[0].value, "-") {
+		if (args[0].value == "-C" || args[0].value == "-c") && len(args) > 1 {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	options, operands := splitOptions(args[1:])
+	switch args[0].value {
+	case "push":
+		forcedRefspec := slices.ContainsFunc(operands, func(operand shellWord) bool {
+			return strings.HasPrefix(operand.value, "+")
+		})
+		if forcedRefspec || hasOption(options, "f", "--force", "--force-with-lease", "--mirror") {
+			return []Danger{newDanger(DangerRuleGitForcePush, "rewrites the history of the remote repository")}
+		}
+	case "reset":
+		if hasOption(options, "", "--hard") {
+			return []Danger{newDanger(DangerRuleGitDiscard, "discards the uncommitted changes")}
+		}
+	case "clean":
+		if hasOption(options, "f", "--force") && !hasOption(options, "n", "--dry-run") {
+			return []Danger{newDanger(DangerRuleGitDiscard, "deletes the untracked files")}
+		}
+	}
+	return nil
+}
+
+// classifyRedirect detects the outputs written to a device and the
+// destructive SQL statements of the here-documents
+func classifyRedirect(redirect *syntax.Redirect) []Danger {
+	dangers := []Danger{}
+	switch redirect.Op {
+	case syntax.RdrOut, syntax.AppOut, syntax.ClbOut, syntax.RdrAll, syntax.AppAll:
+		if target := getShellWord(redirect.Word); isDevice(target.value) {
+			dangers = append(dangers, Danger{
+				Rule:    DangerRuleDeviceOverwrite,
+				Reason:  "writes directly to the device " + target.value,
+				Command: printNode(redirect),
+			})
+		}
+	case syntax.Hdoc, syntax.DashHdoc, syntax.WordHdoc:
+		body := redirect.Hdoc
+		if redirect.Op == syntax.WordHdoc {
+			body = redirect.Word
+		}
+		if body == nil {
+			break
+		}
+		if match := destructiveSQLRegexp.FindString(getShellWord(body).value); match != "" {
+			dangers = append(dangers, Danger{
+				Rule:    DangerRuleDestructiveSQL,
+				Reason:  "runs the destructive SQL statement " + strings.ToUpper(strings.Join(strings.Fields(match), " ")),
+				Command: printNode(redirect.Word),
+			})
+		}
+	default:
+	}
+	return dangers
+}
+
+// isDevice returns true if the path is a block device, the pseudo devices
+// like /dev/null are not considered
+func isDevice(devicePath string) bool {
+	if !strings.HasPrefix(devicePath, "/dev/") {
+		return false
+	}
+	pseudoDevices := []string{"null", "zero", "stdout", "stderr", "stdin", "tty", "random", "urandom", "fd/", "shm/"}
+	name := strings.TrimPrefix(devicePath, "/dev/")
+	for _, pseudoDevice := range pseudoDevices {
+		if name == pseudoDevice || (strings.HasSuffix(pseudoDevice, "/") && strings.HasPrefix(name, pseudoDevice)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDangerService_Classify(t *testing.T) {
+	service := NewDangerService()
+	tests := []struct {
+		name    string
+		script  string
+		dialect models.ShellDialect
+		rules   []string
+	}{
+		{"Safe command", "ls -al /tmp", models.ShellDialectBash, []string{}},
+		{"Forced recursive remove", "rm -rf build", models.ShellDialectBash, []string{DangerRuleRecursiveRemove}},
+		{"Recursive remove of root", "sudo rm -r --one-file-system /", models.ShellDialectSh, []string{DangerRuleRecursiveRemove}},
+		{"Recursive remove of variable path", `rm -R "$DIR/"*`, models.ShellDialectBash, []string{DangerRuleRecursiveRemove}},
+		{"Remove without recursion", "rm -f file.txt", models.ShellDialectBash, []string{}},
+		{"Remove mentioned in a string", `echo "rm -rf /"`, models.ShellDialectBash, []string{}},
+		{"Device overwrite", "dd if=image.iso of=/dev/sdb bs=4M", models.ShellDialectBash, []string{DangerRuleDeviceOverwrite}},
+		{"Write to /dev/null", "dd if=/dev/zero of=/dev/null count=1", models.ShellDialectBash, []string{}},
+		{"Redirect to a device", "cat image > /dev/sda", models.ShellDialectBash, []string{DangerRuleDeviceOverwrite}},
+		{"Redirect to /dev/null", "make 2>/dev/null >&2", models.ShellDialectBash, []string{}},
+		{"Disk format", "mkfs.ext4 /dev/sdb1", models.ShellDialectBash, []string{DangerRuleDiskFormat}},
+		{"Git force push", "git push --force origin main", models.ShellDialectBash, []string{DangerRuleGitForcePush}},
+		{"Git forced refspec", "git -C repo push origin +main", models.ShellDialectBash, []string{DangerRuleGitForcePush}},
+		{"Git push", "git push origin main", models.ShellDialectBash, []string{}},
+		{"Git hard reset", "git reset --hard HEAD~1", models.ShellDialectBash, []string{DangerRuleGitDiscard}},
+		{"Git clean dry run", "git clean -fdn", models.ShellDialectBash, []string{}},
+		{"Recursive chmod of home", "chmod -R 777 ~", models.ShellDialectBash, []string{DangerRuleRecursivePermission}},
+		{"Recursive chmod of folder", "chmod -R 755 public", models.ShellDialectBash, []string{}},
+		{"Drop table", `psql -c "DROP TABLE users"`, models.ShellDialectBash, []string{DangerRuleDestructiveSQL}},
+		{"Delete without where", "mysql db <<EOF\ndelete from users;\nEOF", models.ShellDialectBash, []string{DangerRuleDestructiveSQL}},
+		{"Delete with where", `mysql -e "DELETE FROM users WHERE id=1"`, models.ShellDialectBash, []string{}},
+		{"Kubernetes deletion", "kubectl delete pod web-1", models.ShellDialectBash, []string{DangerRuleResourceDeletion}},
+		{"Reboot in a pipeline", "sync && reboot", models.ShellDialectBash, []string{DangerRuleShutdown}},
+		{"Zsh script parsed as bash", "for f in *.log; do rm -rf $f; done", models.ShellDialectZsh, []string{DangerRuleRecursiveRemove}},
+		{"Several dangers", "git reset --hard\nrm -rf ~", models.ShellDialectBash, []string{DangerRuleGitDiscard, DangerRuleRecursiveRemove}},
+		{"Invalid script", "ls (", models.ShellDialectBash, []string{DangerRuleUnverified}},
+		{
+			"Invalid script with a parsable line", "rm -rf build\nls (", models.ShellDialectBash,
+			[]string{DangerRuleUnverified, DangerRuleRecursiveRemove},
+		},
+		{"Placeholders", "kubectl logs -n <ns> <pod>", models.ShellDialectBash, []string{}},
+		{"Placeholder path", "rm -rf <dir> {{ other }}", models.ShellDialectBash, []string{DangerRuleRecursiveRemove}},
+		{
+			"Zsh specific syntax", "for f (*.log) rm -rf $f", models.ShellDialectZsh,
+			[]string{DangerRuleUnverified},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []string{}
+			for _, danger := range service.Classify(tt.script, tt.dialect) {
+				rules = append(rules, danger.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func TestDangerService_ClassifyDescribesTheCommand(t *testing.T) {
+	service := NewDangerService()
+	dangers := service.Classify("cd /tmp && sudo rm -rf \"$TARGET\"/", models.ShellDialectBash)
+	require.Len(t, dangers, 1)
+	assert.Equal(t, `sudo rm -rf "$TARGET"/`, dangers[0].Command)
+	assert.Equal(t,
+		`sudo rm -rf "$TARGET"/: removes files recursively, `+
+			"targets a path starting with a variable, an empty variable would target /",
+		DescribeDangers(dangers),
+	)
+	assert.Equal(t, dangers, service.Classify("cd /tmp && sudo rm -rf \"$TARGET\"/", models.ShellDialectBash))
+}
+
+func TestDangerService_ClassifyRestoresThePlaceholders(t *testing.T) {
+	dangers := NewDangerService().Classify("git push --force <remote> {{ branch }}", models.ShellDialectBash)
+	require.Len(t, dangers, 1)
+	assert.Equal(t, "git push --force <remote> {{ branch }}", dangers[0].Command)
+}
+
+func TestDangerService_ClassifyBoundsTheCache(t *testing.T) {
+	service := NewDangerService()
+	for i := range maxClassifiedScripts + 1 {
+		service.Classify(fmt.Sprintf("echo %d", i), models.ShellDialectBash)
+	}
+	assert.Len(t, service.cache, maxClassifiedScripts)
+	assert.Len(t, service.cacheOrder, maxClassifiedScripts)
+	assert.NotContains(t, service.cache, sha256.Sum256([]byte(string(models.ShellDialectBash)+"\x1fecho 0")))
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
@@ -16,21 +18,369 @@ type DBService struct {
 	dbAdapter  db.Adapter
 	dbPath     string
 	schemaPath string
+	// defaultShell is the shell of the commands stored before the shell
+	// column was added whose script has no shebang
+	defaultShell models.ShellDialect
+	// selectedCommandColumns is commandColumns, the missing columns of a
+	// read-only database having an older schema being replaced by their default
+	selectedCommandColumns string
+	readOnly               bool
 }
 
 func NewDBService(
 	dbPath string,
 	schemaPath string,
+	defaultShell models.ShellDialect,
 ) *DBService {
 	return &DBService{
-		dbAdapter:  db.NewSQLiteAdapter(dbPath, schemaPath),
-		dbPath:     dbPath,
-		schemaPath: schemaPath,
+		dbAdapter:              db.NewSQLiteAdapter(dbPath, schemaPath),
+		dbPath:                 dbPath,
+		schemaPath:             schemaPath,
+		defaultShell:           defaultShell,
+		selectedCommandColumns: commandColumns,
+		readOnly:               false,
+	}
+}
+
+// NewReadOnlyDBService creates a service reading an existing database without
+// modifying it, the schema of the database is not migrated
+func NewReadOnlyDBService(dbPath string) *DBService {
+	return &DBService{
+		dbAdapter:              db.NewReadOnlySQLiteAdapter(dbPath),
+		dbPath:                 dbPath,
+		schemaPath:             "",
+		defaultShell:           models.DefaultShellDialect,
+		selectedCommandColumns: commandColumns,
+		readOnly:               true,
+	}
+}
+
+// commandColumns is the list of columns read by scanCommand
+const commandColumns = `id, uuid, content_hash, title, description, script, status,
+	lint_issues, lint_status, lint_rules, elapsed, shell, placeholder_sources, short_name,
+	creation_datetime, modification_datetime,
+	(SELECT group_concat(tag.title, char(31)) FROM command_has_tag
+		JOIN tag ON tag.id = command_has_tag.tag_id
+		WHERE command_has_tag.command_id = command.id) AS tags,
+	(WITH RECURSIVE path(id, parent_id, title) AS (
+		SELECT id, parent_id, title FROM folder WHERE id = command.folder_id
+		UNION ALL
+		SELECT folder.id, folder.parent_id, folder.title || '/' || path.title
+		FROM folder JOIN path ON folder.id = path.parent_id
+	) SELECT title FROM path WHERE parent_id IS NULL) AS folder`
+
+// columnDefaultRegexp matches the default value of a column definition
+//
+//nolint:gochecknoglobals // compiled once
+var columnDefaultRegexp = regexp.MustCompile(`DEFAULT ('(?:[^']|'')*'|[^\s,)]+)`)
+
+// tagsSeparator is the separator used by group_concat in commandColumns
+const tagsSeparator = "\x1f"
+
+// folderSeparator separates the folder titles in the path of the folder of a command
+const folderSeparator = "/"
+
+// columnMigration describes a column added to the schema after its
+// initial release, so that existing databases can be upgraded.
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+	// backfill sets the value of the existing rows once the column is
+	// added, nil if the default value of the column is right
+	backfill func(s *DBService) error
+}
+
+func getColumnMigrations() []columnMigration {
+	return []columnMigration{
+		{
+			table:      "command",
+			column:     "shell",
+			definition: "TEXT NOT NULL DEFAULT 'bash' CHECK(shell IN ('bash', 'zsh', 'sh', 'dash', 'ksh'))",
+			backfill:   (*DBService).setDetectedCommandShells,
+		},
+		{
+			table:      "command",
+			column:     "lint_rules",
+			definition: "TEXT NOT NULL DEFAULT ''",
+			backfill:   nil,
+		},
+		{
+			table:      "command",
+			column:     "placeholder_sources",
+			definition: "TEXT NOT NULL DEFAULT ''",
+			backfill:   nil,
+		},
+		{
+			table:      "command",
+			column:     "uuid",
+			definition: "TEXT NOT NULL DEFAULT ''",
+			backfill:   nil,
+		},
+		{
+			table:      "command",
+			column:     "content_hash",
+			definition: "TEXT NOT NULL DEFAULT ''",
+			backfill:   nil,
+		},
+		{
+			table:      "command",
+			column:     "short_name",
+			definition: "TEXT NOT NULL DEFAULT ''",
+			backfill:   nil,
+		},
+	}
+}
+
+// getIndexMigrations returns the statements creating the indexes of the
+// columns added after the initial release of the schema
+func getIndexMigrations() []string {
+	return []string{
+		`CREATE INDEX IF NOT EXISTS idx_command_uuid ON command(uuid)`,
+	}
+}
+
+// getTableMigrations returns the statements creating the tables added
+// to the schema after its initial release
+func getTableMigrations() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS lint_cache (
+			cache_key TEXT PRIMARY KEY,
+			lint_issues TEXT NOT NULL,
+			lint_status TEXT NOT NULL,
+			creation_datetime TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		`CREATE TABLE IF NOT EXISTS placeholder_value (
+			name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			last_used_datetime TEXT NOT NULL DEFAULT (datetime('now')),
+			PRIMARY KEY (name, value)
+		)`,
+		`CREATE TABLE IF NOT EXISTS execution (
+			id INTEGER PRIMARY KEY,
+			command_id INTEGER NOT NULL,
+			shell TEXT NOT NULL,
+			script TEXT NOT NULL,
+			cwd TEXT NOT NULL DEFAULT '',
+			environment TEXT NOT NULL DEFAULT '{}',
+			status TEXT NOT NULL,
+			exit_code INTEGER NOT NULL DEFAULT -1,
+			error TEXT NOT NULL DEFAULT '',
+			output TEXT NOT NULL DEFAULT '[]',
+			dropped_lines INTEGER NOT NULL DEFAULT 0,
+			start_datetime TEXT NOT NULL,
+			end_datetime TEXT
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_execution_command ON execution(command_id)`,
 	}
 }
 
 func (s *DBService) Open() error {
-	return s.dbAdapter.Open()
+	if err := s.dbAdapter.Open(); err != nil {
+		return err
+	}
+	if s.readOnly {
+		return s.setReadOnlyCommandColumns()
+	}
+	return s.migrateSchema()
+}
+
+// setReadOnlyCommandColumns selects the default value of the columns missing
+// from a read-only database, as its schema cannot be migrated
+func (s *DBService) setReadOnlyCommandColumns() error {
+	columns := commandColumns
+	for _, migration := range getColumnMigrations() {
+		if migration.table != "command" {
+			continue
+		}
+		exists, err := s.columnExists(migration.table, migration.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		defaultValue := "''"
+		if match := columnDefaultRegexp.FindStringSubmatch(migration.definition); match != nil {
+			defaultValue = match[1]
+		}
+		slog.Debug("Reading the default value of a missing column", "column", migration.column)
+		location := regexp.MustCompile(`\b` + migration.column + `\b`).FindStringIndex(columns)
+		columns = columns[:location[0]] + defaultValue + " AS " + migration.column + columns[location[1]:]
+	}
+	s.selectedCommandColumns = columns
+	return nil
+}
+
+// migrateSchema adds the tables and columns missing from databases created
+// with an older version of the schema
+func (s *DBService) migrateSchema() error {
+	for _, statement := range getTableMigrations() {
+		if _, err := s.dbAdapter.GetDB().Exec(statement); err != nil {
+			slog.Error("Error creating missing table", "statement", statement, "error", err)
+			return err
+		}
+	}
+	for _, migration := range getColumnMigrations() {
+		exists, err := s.columnExists(migration.table, migration.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		slog.Info("Adding missing column", "table", migration.table, "column", migration.column)
+		_, err = s.dbAdapter.GetDB().Exec(
+			"ALTER TABLE " + migration.table + " ADD COLUMN " + migration.column + " " + migration.definition,
+		)
+		if err != nil {
+			slog.Error("Error adding missing column", "table", migration.table, "column", migration.column, "error", err)
+			return err
+		}
+		if migration.backfill != nil {
+			if err := migration.backfill(s); err != nil {
+				slog.Error("Error setting the values of the added column",
+					"table", migration.table, "column", migration.column, "error", err)
+				return err
+			}
+		}
+	}
+	for _, statement := range getIndexMigrations() {
+		if _, err := s.dbAdapter.GetDB().Exec(statement); err != nil {
+			slog.Error("Error creating missing index", "statement", statement, "error", err)
+			return err
+		}
+	}
+	return s.setMissingCommandIdentities()
+}
+
+// setDetectedCommandShells sets the shell of the commands stored before the
+// shell column was added, deduced from the shebang of their script or the
+// default shell of the service
+func (s *DBService) setDetectedCommandShells() error {
+	rows, err := s.dbAdapter.GetDB().Query("SELECT id, script FROM command")
+	if err != nil {
+		return err
+	}
+	shells := map[resource.ID]models.ShellDialect{}
+	for rows.Next() {
+		var id resource.ID
+		var script string
+		if err := rows.Scan(&id, &script); err != nil {
+			rows.Close()
+			return err
+		}
+		if shell := models.DetectShellDialectFromShebang(script, s.defaultShell); shell != models.DefaultShellDialect {
+			shells[id] = shell
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, shell := range shells {
+		if _, err := s.dbAdapter.GetDB().Exec(
+			"UPDATE command SET shell = ? WHERE id = ?", string(shell), id,
+		); err != nil {
+			return err
+		}
+	}
+	slog.Info("Shell of the existing commands set", "defaultShell", s.defaultShell, "count", len(shells))
+	return nil
+}
+
+// setMissingCommandIdentities generates the uuid and computes the content hash
+// of the commands created before these columns were added, the obsolete
+// copies get the uuid of their command (see getObsoleteCopyUUID)
+func (s *DBService) setMissingCommandIdentities() error {
+	// the commands get their uuid before their obsolete copies
+	rows, err := s.dbAdapter.GetDB().Query(
+		"SELECT "+s.selectedCommandColumns+" FROM command WHERE uuid = '' OR content_hash = '' ORDER BY status = ?, id",
+		string(models.CommandStatusObsolete),
+	)
+	if err != nil {
+		return err
+	}
+	var commands []*models.Command
+	for rows.Next() {
+		command, err := s.scanCommand(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		commands = append(commands, command)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, command := range commands {
+		if command.UUID == "" && command.Status == models.CommandStatusObsolete {
+			command.UUID, err = s.getObsoleteCopyUUID(command.ID)
+			if err != nil {
+				return err
+			}
+		}
+		if command.UUID == "" {
+			command.UUID = resource.NewUUID()
+		}
+		if _, err := s.dbAdapter.GetDB().Exec(
+			"UPDATE command SET uuid = ?, content_hash = ? WHERE id = ?",
+			string(command.UUID), command.GetContentHash(), command.ID,
+		); err != nil {
+			slog.Error("Error setting command uuid", "id", command.ID, "error", err)
+			return err
+		}
+	}
+	if len(commands) > 0 {
+		slog.Info("Missing command uuids and content hashes set", "count", len(commands))
+	}
+	return nil
+}
+
+// getObsoleteCopyUUID returns the uuid of the command an obsolete copy was
+// made from, ie: the only other command having its creation date as the
+// copies keep it, empty if the command cannot be identified
+func (s *DBService) getObsoleteCopyUUID(copyID resource.ID) (resource.UUID, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT uuid FROM command WHERE status != ? AND uuid != ''
+		AND creation_datetime = (SELECT creation_datetime FROM command WHERE id = ?) LIMIT 2`,
+		string(models.CommandStatusObsolete), copyID,
+	)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var uuids []resource.UUID
+	for rows.Next() {
+		var uuid string
+		if err := rows.Scan(&uuid); err != nil {
+			return "", err
+		}
+		uuids = append(uuids, resource.UUID(uuid))
+	}
+	if err := rows.Err(); err != nil || len(uuids) != 1 {
+		return "", err
+	}
+	return uuids[0], nil
+}
+
+func (s *DBService) columnExists(table string, column string) (bool, error) {
+	rows, err := s.dbAdapter.GetDB().Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
 }
 
 func (s *DBService) Close() error {
@@ -41,16 +391,41 @@ func (s *DBService) GetDBAdapter() db.Adapter {
 	return s.dbAdapter
 }
 
+// RunInTransaction calls fn with a store running its queries in a
+// transaction, the transaction is committed if fn succeeds and rolled back
+// otherwise
+func (s *DBService) RunInTransaction(fn func(store CatalogStoreInterface) error) error {
+	tx, err := s.dbAdapter.BeginTx()
+	if err != nil {
+		return err
+	}
+	txService := *s
+	txService.dbAdapter = db.NewTransactionAdapter(tx)
+	if err := fn(&txService); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			slog.Error("Failed to roll back the transaction", "error", rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
 func (s *DBService) SaveCommand(command *models.Command) error {
+	if command.UUID == "" {
+		command.UUID = resource.NewUUID()
+	}
+	command.ContentHash = command.GetContentHash()
 	// Use Exec instead of Query for INSERT statements
 	result, err := s.dbAdapter.GetDB().Exec(
 		`INSERT INTO command (
-			title, description, script, status,
-			lint_issues, lint_status, elapsed,
+			uuid, content_hash, title, description, script, status,
+			lint_issues, lint_status, lint_rules, elapsed, shell, placeholder_sources, short_name,
 			creation_datetime, modification_datetime
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		string(command.UUID), command.ContentHash,
 		command.Title, command.Description, command.Script, string(command.Status),
-		command.LintIssues, string(command.LintStatus), command.Elapsed,
+		command.LintIssues, string(command.LintStatus), command.LintRules, command.Elapsed, string(command.Shell),
+		command.PlaceholderSources, command.ShortName,
 		command.CreationDatetime.Format(time.DateTime), command.ModificationDatetime.Format(time.DateTime),
 	)
 	if err != nil {
@@ -69,12 +444,12 @@ func (s *DBService) DuplicateCommand(commandID resource.ID, status models.Comman
 	// Use Exec instead of Query for INSERT statements
 	result, err := s.dbAdapter.GetDB().Exec(
 		`INSERT INTO command (
-			title, description, script, status,
-			lint_issues, lint_status, elapsed,
+			uuid, content_hash, title, description, script, status,
+			lint_issues, lint_status, lint_rules, elapsed, shell, placeholder_sources, short_name,
 			creation_datetime, modification_datetime
 		) SELECT
-			title, description, script, ?,
-			lint_issues, lint_status, elapsed,
+			uuid, content_hash, title, description, script, ?,
+			lint_issues, lint_status, lint_rules, elapsed, shell, placeholder_sources, short_name,
 			creation_datetime, ?
 		FROM command WHERE id = ?`,
 		status,
@@ -97,10 +472,7 @@ func (s *DBService) GetCommandByID(id resource.ID) (*models.Command, error) {
 	slog.Debug("Retrieving command by id from database", "id", id)
 	// Use QueryRow for single row retrieval
 	row := s.dbAdapter.GetDB().QueryRow(
-		`SELECT id, title, description, script, status,
-			lint_issues, lint_status, elapsed,
-			creation_datetime, modification_datetime
-			FROM command WHERE id = ? LIMIT 1`,
+		"SELECT "+s.selectedCommandColumns+" FROM command WHERE id = ? LIMIT 1",
 		id,
 	)
 	if row == nil {
@@ -114,10 +486,7 @@ func (s *DBService) GetCommandByScript(script string) (*models.Command, error) {
 	slog.Debug("Retrieving command by script from database", "script", script)
 	// Use QueryRow for single row retrieval
 	row := s.dbAdapter.GetDB().QueryRow(
-		`SELECT id, title, description, script, status,
-			lint_issues, lint_status, elapsed,
-			creation_datetime, modification_datetime
-			FROM command WHERE script = ? LIMIT 1`,
+		"SELECT "+s.selectedCommandColumns+" FROM command WHERE script = ? LIMIT 1",
 		script,
 	)
 	if row == nil {
@@ -128,31 +497,70 @@ func (s *DBService) GetCommandByScript(script string) (*models.Command, error) {
 	return s.getCommandFromRow(row)
 }
 
-func (*DBService) getCommandFromRow(row *sql.Row) (*models.Command, error) {
+// GetCommandByUUID retrieves the command having the given uuid, the obsolete
+// copies of the command are only returned if the command has no other copy
+func (s *DBService) GetCommandByUUID(uuid resource.UUID) (*models.Command, error) {
+	row := s.dbAdapter.GetDB().QueryRow(
+		"SELECT "+s.selectedCommandColumns+" FROM command WHERE uuid = ? ORDER BY status = ?, id DESC LIMIT 1",
+		string(uuid), string(models.CommandStatusObsolete),
+	)
+	return s.getCommandFromRow(row)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *DBService) getCommandFromRow(row *sql.Row) (*models.Command, error) {
+	command, err := s.scanCommand(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		// Handle other scan errors
+		slog.Error("Error scanning command from database", "error", err)
+		return nil, err
+	}
+	return command, nil
+}
+
+// scanCommand reads a command selected using commandColumns
+func (*DBService) scanCommand(row rowScanner) (*models.Command, error) {
 	var command models.Command
 	var creationDateStr string
 	var modificationDateStr string
+	var tags sql.NullString
+	var folder sql.NullString
 
 	err := row.Scan(
 		&command.ID,
+		&command.UUID,
+		&command.ContentHash,
 		&command.Title,
 		&command.Description,
 		&command.Script,
 		&command.Status,
 		&command.LintIssues,
 		&command.LintStatus,
+		&command.LintRules,
 		&command.Elapsed,
+		&command.Shell,
+		&command.PlaceholderSources,
+		&command.ShortName,
 		&creationDateStr,
 		&modificationDateStr,
+		&tags,
+		&folder,
 	)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		// Handle other scan errors
-		slog.Error("Error scanning command from database", "error", err)
 		return nil, err
 	}
+	command.Folder = folder.String
+	command.Tags = []string{}
+	if tags.Valid && tags.String != "" {
+		command.Tags = strings.Split(tags.String, tagsSeparator)
+	}
 
 	command.CreationDatetime, err = time.Parse(time.DateTime, creationDateStr)
 	if err != nil {
@@ -194,16 +602,11 @@ func (s *DBService) GetMaxCommandTimestamp() (time.Time, error) {
 // GetCommands retrieves commands from the database, optionally filtered by status
 func (s *DBService) GetCommands(statuses ...models.CommandStatus) ([]*models.Command, error) {
 	var commands []*models.Command
-	var creationDateStr string
-	var modificationDateStr string
 	var query string
 	var args []interface{}
 
 	// Base query
-	query = `SELECT id, title, description, script, status,
-		lint_issues, lint_status, elapsed,
-		creation_datetime, modification_datetime
-		FROM command`
+	query = "SELECT " + s.selectedCommandColumns + " FROM command"
 
 	// Add status filter if provided
 	if len(statuses) > 0 {
@@ -224,45 +627,11 @@ func (s *DBService) GetCommands(statuses ...models.CommandStatus) ([]*models.Com
 	defer rows.Close()
 
 	for rows.Next() {
-		command := models.Command{
-			ID:                   0,
-			Title:                "",
-			Description:          "",
-			Script:               "",
-			Status:               "",
-			LintIssues:           "",
-			LintStatus:           "",
-			Elapsed:              0,
-			CreationDatetime:     time.Time{},
-			ModificationDatetime: time.Time{},
-			FilterScore:          0,
-		}
-		err := rows.Scan(
-			&command.ID,
-			&command.Title,
-			&command.Description,
-			&command.Script,
-			&command.Status,
-			&command.LintIssues,
-			&command.LintStatus,
-			&command.Elapsed,
-			&creationDateStr,
-			&modificationDateStr,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		command.CreationDatetime, err = time.Parse(time.DateTime, creationDateStr)
+		command, err := s.scanCommand(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		command.ModificationDatetime, err = time.Parse(time.DateTime, modificationDateStr)
-		if err != nil {
-			return nil, err
-		}
-		commands = append(commands, &command)
+		commands = append(commands, command)
 	}
 	return commands, nil
 }
@@ -270,15 +639,17 @@ func (s *DBService) GetCommands(statuses ...models.CommandStatus) ([]*models.Com
 // UpdateCommand updates an existing command in the database
 func (s *DBService) UpdateCommand(command *models.Command) error {
 	slog.Debug("Updating command in database", "command", command)
-	// Use Exec for UPDATE statements
+	command.ContentHash = command.GetContentHash()
+	// Use Exec for UPDATE statements, the uuid is kept
 	_, err := s.dbAdapter.GetDB().Exec(`UPDATE command
-		SET title = ?, description = ?, script = ?,
-		status = ?, lint_issues = ?, lint_status = ?,
-		elapsed = ?, modification_datetime = ?
+		SET content_hash = ?, title = ?, description = ?, script = ?,
+		status = ?, lint_issues = ?, lint_status = ?, lint_rules = ?,
+		elapsed = ?, shell = ?, placeholder_sources = ?, short_name = ?, modification_datetime = ?
 		WHERE id = ?`,
-		command.Title, command.Description, command.Script,
-		string(command.Status), command.LintIssues, string(command.LintStatus),
-		command.Elapsed, time.Now().Format(time.DateTime), command.ID,
+		command.ContentHash, command.Title, command.Description, command.Script,
+		string(command.Status), command.LintIssues, string(command.LintStatus), command.LintRules,
+		command.Elapsed, string(command.Shell), command.PlaceholderSources, command.ShortName,
+		time.Now().Format(time.DateTime), command.ID,
 	)
 	if err != nil {
 		slog.Error("Error updating command in database", "id", command.ID, "error", err)
@@ -288,6 +659,193 @@ func (s *DBService) UpdateCommand(command *models.Command) error {
 	return nil
 }
 
+// UpdateCommandLint stores the lint result of a command, the result is
+// ignored if the script or the shell of the command changed meanwhile
+func (s *DBService) UpdateCommandLint(command *models.Command) error {
+	_, err := s.dbAdapter.GetDB().Exec(`UPDATE command
+		SET lint_issues = ?, lint_status = ?, lint_rules = ?
+		WHERE id = ? AND script = ? AND shell = ?`,
+		command.LintIssues, string(command.LintStatus), command.LintRules,
+		command.ID, command.Script, string(command.Shell),
+	)
+	if err != nil {
+		slog.Error("Error updating command lint in database", "id", command.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ReplaceCommand updates all the fields of an existing command, including
+// its uuid and its timestamps, eg: with the fields of an imported command
+func (s *DBService) ReplaceCommand(command *models.Command) error {
+	command.ContentHash = command.GetContentHash()
+	_, err := s.dbAdapter.GetDB().Exec(`UPDATE command
+		SET uuid = ?, content_hash = ?, title = ?, description = ?, script = ?,
+		status = ?, lint_issues = ?, lint_status = ?, lint_rules = ?,
+		elapsed = ?, shell = ?, placeholder_sources = ?, short_name = ?,
+		creation_datetime = ?, modification_datetime = ?
+		WHERE id = ?`,
+		string(command.UUID), command.ContentHash, command.Title, command.Description, command.Script,
+		string(command.Status), command.LintIssues, string(command.LintStatus), command.LintRules,
+		command.Elapsed, string(command.Shell), command.PlaceholderSources, command.ShortName,
+		command.CreationDatetime.Format(time.DateTime), command.ModificationDatetime.Format(time.DateTime),
+		command.ID,
+	)
+	if err != nil {
+		slog.Error("Error replacing command in database", "id", command.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// SetCommandTags replaces the tags of the command, the missing tags are created
+func (s *DBService) SetCommandTags(commandID resource.ID, tags []string) error {
+	if _, err := s.dbAdapter.GetDB().Exec(
+		"DELETE FROM command_has_tag WHERE command_id = ?", commandID,
+	); err != nil {
+		slog.Error("Error removing command tags", "id", commandID, "error", err)
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := s.dbAdapter.GetDB().Exec(
+			"INSERT OR IGNORE INTO tag (title) VALUES (?)", tag,
+		); err != nil {
+			slog.Error("Error creating tag", "tag", tag, "error", err)
+			return err
+		}
+		if _, err := s.dbAdapter.GetDB().Exec(
+			`INSERT OR IGNORE INTO command_has_tag (command_id, tag_id)
+			SELECT ?, id FROM tag WHERE title = ?`,
+			commandID, tag,
+		); err != nil {
+			slog.Error("Error adding command tag", "id", commandID, "tag", tag, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCommandFolder moves the command to the folder having the given path
+// (eg: ops/db), the missing folders are created, an empty path removes the
+// command from its folder
+func (s *DBService) SetCommandFolder(commandID resource.ID, path string) error {
+	var folderID sql.NullInt64
+	for _, title := range strings.Split(path, folderSeparator) {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			continue
+		}
+		var id int64
+		err := s.dbAdapter.GetDB().QueryRow(
+			"SELECT id FROM folder WHERE title = ? AND parent_id IS ?", title, folderID,
+		).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			var result sql.Result
+			result, err = s.dbAdapter.GetDB().Exec(
+				"INSERT INTO folder (parent_id, title) VALUES (?, ?)", folderID, title,
+			)
+			if err == nil {
+				id, err = result.LastInsertId()
+			}
+		}
+		if err != nil {
+			slog.Error("Error creating folder", "path", path, "title", title, "error", err)
+			return err
+		}
+		folderID = sql.NullInt64{Int64: id, Valid: true}
+	}
+	if _, err := s.dbAdapter.GetDB().Exec(
+		"UPDATE command SET folder_id = ? WHERE id = ?", folderID, commandID,
+	); err != nil {
+		slog.Error("Error moving command to folder", "id", commandID, "path", path, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetLintCache retrieves the lint result stored for the given cache key,
+// found is false if no result has been stored yet
+func (s *DBService) GetLintCache(cacheKey string) (
+	lintStatus models.LintStatus, lintIssues string, found bool, err error,
+) {
+	row := s.dbAdapter.GetDB().QueryRow(
+		"SELECT lint_status, lint_issues FROM lint_cache WHERE cache_key = ?",
+		cacheKey,
+	)
+	err = row.Scan(&lintStatus, &lintIssues)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", false, nil
+	}
+	if err != nil {
+		slog.Error("Error reading lint cache", "cacheKey", cacheKey, "error", err)
+		return "", "", false, err
+	}
+	return lintStatus, lintIssues, true, nil
+}
+
+// SaveLintCache stores the lint result for the given cache key
+func (s *DBService) SaveLintCache(cacheKey string, lintStatus models.LintStatus, lintIssues string) error {
+	_, err := s.dbAdapter.GetDB().Exec(
+		`INSERT OR REPLACE INTO lint_cache (cache_key, lint_status, lint_issues, creation_datetime)
+		VALUES (?, ?, ?, ?)`,
+		cacheKey, string(lintStatus), lintIssues, time.Now().Format(time.DateTime),
+	)
+	if err != nil {
+		slog.Error("Error saving lint cache", "cacheKey", cacheKey, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetPlaceholderValues returns the values recently used for the placeholder,
+// the most recent first
+func (s *DBService) GetPlaceholderValues(name string, limit int) ([]string, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT value FROM placeholder_value WHERE name = ?
+		ORDER BY last_used_datetime DESC, rowid DESC LIMIT ?`,
+		name, limit,
+	)
+	if err != nil {
+		slog.Error("Error reading placeholder values", "name", name, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// SavePlaceholderValue stores a value used for the placeholder,
+// only the keep most recent values of the placeholder are kept
+func (s *DBService) SavePlaceholderValue(name string, value string, keep int) error {
+	_, err := s.dbAdapter.GetDB().Exec(
+		`INSERT OR REPLACE INTO placeholder_value (name, value, last_used_datetime) VALUES (?, ?, ?)`,
+		name, value, time.Now().Format(time.DateTime),
+	)
+	if err != nil {
+		slog.Error("Error saving placeholder value", "name", name, "error", err)
+		return err
+	}
+	_, err = s.dbAdapter.GetDB().Exec(
+		`DELETE FROM placeholder_value WHERE name = ? AND rowid NOT IN (
+			SELECT rowid FROM placeholder_value WHERE name = ?
+			ORDER BY last_used_datetime DESC, rowid DESC LIMIT ?
+		)`,
+		name, name, keep,
+	)
+	if err != nil {
+		slog.Error("Error removing old placeholder values", "name", name, "error", err)
+		return err
+	}
+	return nil
+}
+
 // GetCommandCountsByStatus retrieves a count of commands grouped by status directly from the database
 func (s *DBService) GetCommandCountsByStatus() (map[models.CommandStatus]int, error) {
 	// Use SQL GROUP BY to count by status directly in the database
@@ -322,3 +880,160 @@ func (s *DBService) GetCommandCountsByStatus() (map[models.CommandStatus]int, er
 
 	return counts, nil
 }
+
+// executionDatetimeLayout keeps the milliseconds to compute the elapsed time of the executions
+const executionDatetimeLayout = "2006-01-02 15:04:05.000"
+
+// executionColumns is the list of columns read by scanExecution
+const executionColumns = `id, command_id, shell, script, cwd, environment, status,
+	exit_code, error, output, dropped_lines, start_datetime, end_datetime`
+
+// SaveExecution stores a new execution and sets its id
+func (s *DBService) SaveExecution(execution *models.Execution) error {
+	environment, output, err := encodeExecution(execution)
+	if err != nil {
+		return err
+	}
+	result, err := s.dbAdapter.GetDB().Exec(
+		`INSERT INTO execution (
+			command_id, shell, script, cwd, environment, status,
+			exit_code, error, output, dropped_lines, start_datetime, end_datetime
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		execution.CommandID, execution.Shell, execution.Script, execution.Cwd, environment,
+		string(execution.Status), execution.ExitCode, execution.Error, output, execution.DroppedLines,
+		execution.StartDatetime.Format(executionDatetimeLayout), formatEndDatetime(execution.EndDatetime),
+	)
+	if err != nil {
+		slog.Error("Error saving execution", "command", execution.CommandID, "error", err)
+		return err
+	}
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		slog.Error("Error retrieving last insert ID", "error", err)
+		return err
+	}
+	execution.ID = resource.ID(lastInsertID)
+	return nil
+}
+
+// UpdateExecution stores the result of a finished execution
+func (s *DBService) UpdateExecution(execution *models.Execution) error {
+	_, output, err := encodeExecution(execution)
+	if err != nil {
+		return err
+	}
+	_, err = s.dbAdapter.GetDB().Exec(
+		`UPDATE execution SET
+			status = ?, exit_code = ?, error = ?, output = ?, dropped_lines = ?, end_datetime = ?
+		WHERE id = ?`,
+		string(execution.Status), execution.ExitCode, execution.Error, output, execution.DroppedLines,
+		formatEndDatetime(execution.EndDatetime), execution.ID,
+	)
+	if err != nil {
+		slog.Error("Error updating execution", "id", execution.ID, "error", err)
+	}
+	return err
+}
+
+// GetExecutionByID returns the execution having the given id, nil if not found
+func (s *DBService) GetExecutionByID(id resource.ID) (*models.Execution, error) {
+	row := s.dbAdapter.GetDB().QueryRow(
+		"SELECT "+executionColumns+" FROM execution WHERE id = ?",
+		id,
+	)
+	execution, err := scanExecution(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		slog.Error("Error scanning execution from database", "id", id, "error", err)
+		return nil, err
+	}
+	return execution, nil
+}
+
+// GetExecutions returns the limit most recent executions, the most recent first
+func (s *DBService) GetExecutions(limit int) ([]*models.Execution, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		"SELECT "+executionColumns+" FROM execution ORDER BY id DESC LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		slog.Error("Error querying executions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	executions := []*models.Execution{}
+	for rows.Next() {
+		execution, err := scanExecution(rows)
+		if err != nil {
+			slog.Error("Error scanning execution from database", "error", err)
+			return nil, err
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+func encodeExecution(execution *models.Execution) (environment string, output string, err error) {
+	environmentJSON, err := json.Marshal(execution.Environment)
+	if err != nil {
+		return "", "", err
+	}
+	outputJSON, err := json.Marshal(execution.Output)
+	if err != nil {
+		return "", "", err
+	}
+	return string(environmentJSON), string(outputJSON), nil
+}
+
+func formatEndDatetime(endDatetime time.Time) sql.NullString {
+	if endDatetime.IsZero() {
+		return sql.NullString{String: "", Valid: false}
+	}
+	return sql.NullString{String: endDatetime.Format(executionDatetimeLayout), Valid: true}
+}
+
+// scanExecution reads an execution selected using executionColumns
+func scanExecution(row rowScanner) (*models.Execution, error) {
+	var execution models.Execution
+	var environment string
+	var output string
+	var startDatetime string
+	var endDatetime sql.NullString
+	err := row.Scan(
+		&execution.ID,
+		&execution.CommandID,
+		&execution.Shell,
+		&execution.Script,
+		&execution.Cwd,
+		&environment,
+		&execution.Status,
+		&execution.ExitCode,
+		&execution.Error,
+		&output,
+		&execution.DroppedLines,
+		&startDatetime,
+		&endDatetime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(environment), &execution.Environment); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(output), &execution.Output); err != nil {
+		return nil, err
+	}
+	execution.StartDatetime, err = time.ParseInLocation(executionDatetimeLayout, startDatetime, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endDatetime.Valid {
+		execution.EndDatetime, err = time.ParseInLocation(executionDatetimeLayout, endDatetime.String, time.Local)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &execution, nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyTestSchema is the schema of the first release, before the columns of
+// getColumnMigrations were added
+const legacyTestSchema = `
+CREATE TABLE folder (
+	id INTEGER PRIMARY KEY,
+	parent_id INTEGER REFERENCES folder(id) ON DELETE CASCADE,
+	title TEXT NOT NULL CHECK(length(title) <= 30)
+);
+CREATE TABLE tag (
+	id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL UNIQUE CHECK(length(title) <= 30)
+);
+CREATE TABLE command (
+	id INTEGER PRIMARY KEY,
+	creation_datetime TEXT NOT NULL DEFAULT (datetime('now')),
+	modification_datetime TEXT NOT NULL DEFAULT (datetime('now')),
+	title TEXT NOT NULL CHECK(length(title) <= 50),
+	description TEXT,
+	script TEXT NOT NULL,
+	lint_issues TEXT,
+	lint_status TEXT NOT NULL,
+	elapsed INTEGER,
+	status TEXT NOT NULL,
+	folder_id INTEGER
+);
+CREATE TABLE command_has_tag (
+	command_id INTEGER NOT NULL,
+	tag_id INTEGER NOT NULL,
+	PRIMARY KEY (command_id, tag_id)
+);`
+
+// newLegacyTestDB creates a database having the schema of the first release
+// and the given commands, status then script
+func newLegacyTestDB(t *testing.T, commands ...[2]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	legacyDB, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer legacyDB.Close()
+	_, err = legacyDB.Exec(legacyTestSchema)
+	require.NoError(t, err)
+	for _, cmd := range commands {
+		_, err = legacyDB.Exec(
+			"INSERT INTO command (title, description, script, lint_issues, lint_status, elapsed, status) "+
+				"VALUES ('', '', ?, '[]', 'NOT_AVAILABLE', 0, ?)", cmd[1], cmd[0],
+		)
+		require.NoError(t, err)
+	}
+	return path
+}
+
+func openTestDBService(t *testing.T, path string, defaultShell models.ShellDialect) *DBService {
+	t.Helper()
+	dbService := NewDBService(path, "", defaultShell)
+	require.NoError(t, dbService.Open())
+	t.Cleanup(func() {
+		assert.NoError(t, dbService.Close())
+	})
+	return dbService
+}
+
+func TestDBService_MigrateShell(t *testing.T) {
+	path := newLegacyTestDB(t,
+		[2]string{"SAVED", "print -l *(.)"},
+		[2]string{"SAVED", "#!/usr/bin/env bash\necho $BASH_VERSION"},
+	)
+	dbService := openTestDBService(t, path, models.ShellDialectZsh)
+
+	commands, err := dbService.GetCommands()
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	assert.Equal(t, models.ShellDialectZsh, commands[0].Shell, "the commands default to the shell of the history")
+	assert.Equal(t, models.ShellDialectBash, commands[1].Shell, "the shebang is used if any")
+}
+
+func TestDBService_ReadOnlyLegacySchema(t *testing.T) {
+	legacyPath := newLegacyTestDB(t, [2]string{"SAVED", "ls -al"})
+	path := filepath.Join(t.TempDir(), "team #1?", "legacy.db")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.Rename(legacyPath, path))
+
+	dbService := NewReadOnlyDBService(path)
+	require.NoError(t, dbService.Open())
+	defer dbService.Close()
+
+	commands, err := dbService.GetCommands()
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	assert.Equal(t, "ls -al", commands[0].Script)
+	assert.Equal(t, models.ShellDialectBash, commands[0].Shell, "the missing columns have their default value")
+	assert.Empty(t, commands[0].UUID)
+
+	exists, err := dbService.columnExists("command", "uuid")
+	require.NoError(t, err)
+	assert.False(t, exists, "the database is not migrated")
+}
+
+func TestDBService_MigrateUUIDOfObsoleteCopies(t *testing.T) {
+	path := newLegacyTestDB(t)
+	legacyDB, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	for _, cmd := range [][3]string{
+		{"OBSOLETE", "make deploy", "2025-01-01 10:00:00"},
+		{"SAVED", "make deploy ENV=prod", "2025-01-01 10:00:00"},
+		{"SAVED", "ls", "2025-01-02 10:00:00"},
+		{"OBSOLETE", "ls -l", "2025-01-03 10:00:00"},
+	} {
+		_, err = legacyDB.Exec(
+			"INSERT INTO command (title, description, script, lint_issues, lint_status, elapsed, status, "+
+				"creation_datetime) VALUES ('', '', ?, '[]', 'NOT_AVAILABLE', 0, ?, ?)", cmd[1], cmd[0], cmd[2],
+		)
+		require.NoError(t, err)
+	}
+	require.NoError(t, legacyDB.Close())
+	dbService := openTestDBService(t, path, models.ShellDialectBash)
+
+	commands, err := dbService.GetCommands()
+	require.NoError(t, err)
+	require.Len(t, commands, 4)
+	uuids := map[string]string{}
+	for _, cmd := range commands {
+		require.NotEmpty(t, cmd.UUID)
+		uuids[cmd.Script] = string(cmd.UUID)
+	}
+	assert.Equal(t, uuids["make deploy ENV=prod"], uuids["make deploy"],
+		"the obsolete copy shares the uuid of the command having its creation date")
+	assert.NotEqual(t, uuids["ls"], uuids["ls -l"], "the command of the obsolete copy is unknown")
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"cmp"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/diff"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+)
+
+const (
+	// maxStoredOutputLines is the number of output lines stored with an execution, the oldest lines are dropped
+	maxStoredOutputLines = 1000
+	// maxListedExecutions is the number of executions displayed in the history
+	maxListedExecutions = 500
+)
+
+// ExecutionService runs the commands in background and records their executions
+type ExecutionService struct {
+	store     ExecutionStoreInterface
+	runner    *executors.CommandRunner
+	runConfig *RunConfig
+	// started are the executions started by the application, indexed by id
+	started map[resource.ID]models.Execution
+	// recording tracks the runs whose result is not stored yet
+	recording sync.WaitGroup
+	mu        sync.Mutex
+}
+
+func NewExecutionService(
+	store ExecutionStoreInterface,
+	runner *executors.CommandRunner,
+	runConfig *RunConfig,
+) *ExecutionService {
+	return &ExecutionService{
+		store:     store,
+		runner:    runner,
+		runConfig: runConfig,
+		started:   make(map[resource.ID]models.Execution),
+		recording: sync.WaitGroup{},
+		mu:        sync.Mutex{},
+	}
+}
+
+// GetShell returns the shell running the command, the configured shell
+// if any or the shell of the command
+func (s *ExecutionService) GetShell(command *models.Command) string {
+	return cmp.Or(s.runConfig.Shell, string(command.Shell), string(models.ShellDialectBash))
+}
+
+// Run starts the script of the command, script is the command script
+// with its placeholders filled
+func (s *ExecutionService) Run(command *models.Command, script string) (*executors.CommandRun, error) {
+	return s.start(command.ID, s.GetShell(command), script)
+}
+
+// Rerun starts again the script of an execution using the same shell
+func (s *ExecutionService) Rerun(id resource.ID) (*executors.CommandRun, error) {
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.start(execution.CommandID, execution.Shell, execution.Script)
+}
+
+// start stores the execution then runs the script, the result of
+// the execution is stored when the run is finished
+func (s *ExecutionService) start(commandID resource.ID, shell string, script string) (*executors.CommandRun, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		slog.Warn("Unable to get the current directory", "error", err)
+	}
+	execution := models.Execution{
+		StartDatetime: time.Now(),
+		EndDatetime:   time.Time{},
+		Environment:   getEnvironmentSummary(),
+		Shell:         shell,
+		Script:        script,
+		Cwd:           cwd,
+		Status:        task.Running,
+		Error:         "",
+		Output:        []models.OutputLine{},
+		DroppedLines:  0,
+		ID:            0,
+		CommandID:     commandID,
+		ExitCode:      -1,
+	}
+	if err := s.store.SaveExecution(&execution); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.started[execution.ID] = execution
+	s.mu.Unlock()
+
+	run := s.runner.Start(execution.ID, commandID, shell, script)
+	s.recording.Add(1)
+	go s.record(execution, run)
+	return run, nil
+}
+
+// record stores the result of the run when it is finished, only the last
+// output lines are kept
+func (s *ExecutionService) record(execution models.Execution, run *executors.CommandRun) {
+	defer s.recording.Done()
+	run.Wait()
+	result := getRunExecution(execution, run)
+	if len(result.Output) > maxStoredOutputLines {
+		result.DroppedLines += len(result.Output) - maxStoredOutputLines
+		result.Output = result.Output[len(result.Output)-maxStoredOutputLines:]
+	}
+	if err := s.store.UpdateExecution(result); err != nil {
+		slog.Error("Error storing the execution result", "execution", execution.ID, "error", err)
+	}
+}
+
+// getRunExecution returns the execution updated with the current state of its run
+func getRunExecution(execution models.Execution, run *executors.CommandRun) *models.Execution {
+	execution.Status = run.GetStatus()
+	execution.ExitCode = run.GetExitCode()
+	if err := run.GetError(); err != nil {
+		execution.Error = err.Error()
+	}
+	execution.StartDatetime = run.GetStartTime()
+	execution.EndDatetime = run.GetEndTime()
+	execution.Output, execution.DroppedLines = run.GetOutput()
+	return &execution
+}
+
+// GetRun returns the run having the given id
+func (s *ExecutionService) GetRun(id resource.ID) (*executors.CommandRun, bool) {
+	return s.runner.Get(id)
+}
+
+// GetExecution returns the execution having the given id, the executions
+// started by the application are read from their run
+func (s *ExecutionService) GetExecution(id resource.ID) (*models.Execution, error) {
+	if execution, ok := s.getStartedExecution(id); ok {
+		return execution, nil
+	}
+	execution, err := s.store.GetExecutionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if execution == nil {
+		return nil, &ExecutionNotFoundError{ID: id}
+	}
+	return execution, nil
+}
+
+// GetExecutions returns the most recent executions, the most recent first
+func (s *ExecutionService) GetExecutions() ([]*models.Execution, error) {
+	executions, err := s.store.GetExecutions(maxListedExecutions)
+	if err != nil {
+		return nil, err
+	}
+	for i, execution := range executions {
+		if started, ok := s.getStartedExecution(execution.ID); ok {
+			executions[i] = started
+		}
+	}
+	return executions, nil
+}
+
+func (s *ExecutionService) getStartedExecution(id resource.ID) (*models.Execution, bool) {
+	s.mu.Lock()
+	execution, ok := s.started[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	run, ok := s.runner.Get(id)
+	if !ok {
+		return nil, false
+	}
+	return getRunExecution(execution, run), true
+}
+
+// DiffOutputs returns the output lines of both executions with the
+// operation transforming the output of before into the output of after
+func (*ExecutionService) DiffOutputs(before *models.Execution, after *models.Execution) []diff.Line {
+	return diff.Lines(before.GetOutputText(), after.GetOutputText())
+}
+
+// Stop cancels the running commands and waits for their result to be stored
+func (s *ExecutionService) Stop() {
+	s.runner.CancelAll()
+	s.recording.Wait()
+}
+
+// getEnvironmentSummary returns the environment variables describing
+// where a command has been run
+func getEnvironmentSummary() map[string]string {
+	environment := map[string]string{}
+	for _, name := range []string{"USER", "SHELL", "LANG", "TERM"} {
+		if value, ok := os.LookupEnv(name); ok {
+			environment[name] = value
+		}
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		environment["HOSTNAME"] = hostname
+	}
+	return environment
+}
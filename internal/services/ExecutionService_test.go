@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/diff"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockExecutionStore struct {
+	executions map[resource.ID]models.Execution
+	mu         sync.Mutex
+}
+
+func NewMockExecutionStore() *MockExecutionStore {
+	return &MockExecutionStore{executions: map[resource.ID]models.Execution{}, mu: sync.Mutex{}}
+}
+
+func (m *MockExecutionStore) SaveExecution(execution *models.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution.ID = resource.ID(len(m.executions) + 1)
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *MockExecutionStore) UpdateExecution(execution *models.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *MockExecutionStore) GetExecutionByID(id resource.ID) (*models.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, ok := m.executions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &execution, nil
+}
+
+func (m *MockExecutionStore) GetExecutions(limit int) ([]*models.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	executions := []*models.Execution{}
+	for _, execution := range m.executions {
+		executions = append(executions, &execution)
+	}
+	slices.SortFunc(executions, func(a, b *models.Execution) int {
+		return int(b.ID - a.ID)
+	})
+	return executions[:min(limit, len(executions))], nil
+}
+
+func TestExecutionService(t *testing.T) {
+	t.Run("Stores the execution and its truncated output", func(t *testing.T) {
+		store := NewMockExecutionStore()
+		service := NewExecutionService(store, executors.NewCommandRunner(), &RunConfig{Shell: "sh"})
+		command := &models.Command{ID: 7, Shell: models.ShellDialectBash}
+
+		run, err := service.Run(command, fmt.Sprintf("seq %d; exit 2", maxStoredOutputLines+5))
+		require.NoError(t, err)
+		stored, err := store.GetExecutionByID(run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, resource.ID(7), stored.CommandID)
+		assert.Equal(t, "sh", stored.Shell)
+		assert.NotEmpty(t, stored.Cwd)
+
+		run.Wait()
+		service.Stop()
+		stored, err = store.GetExecutionByID(run.ID)
+		require.NoError(t, err)
+		assert.Equal(t, task.Errored, stored.Status)
+		assert.Equal(t, 2, stored.ExitCode)
+		assert.False(t, stored.EndDatetime.IsZero())
+		assert.Len(t, stored.Output, maxStoredOutputLines)
+		assert.Equal(t, 5, stored.DroppedLines)
+		assert.Equal(t, "6", stored.Output[0].Text)
+
+		// the executions started by the application keep their full output
+		execution, err := service.GetExecution(run.ID)
+		require.NoError(t, err)
+		assert.Len(t, execution.Output, maxStoredOutputLines+5)
+	})
+
+	t.Run("Reads the executions of previous sessions from the store", func(t *testing.T) {
+		store := NewMockExecutionStore()
+		previous := &models.Execution{Script: "echo hello", Shell: "sh", Status: task.Exited}
+		require.NoError(t, store.SaveExecution(previous))
+		service := NewExecutionService(store, executors.NewCommandRunner(), &RunConfig{Shell: ""})
+
+		execution, err := service.GetExecution(previous.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "echo hello", execution.Script)
+
+		_, err = service.GetExecution(42)
+		var notFoundErr *ExecutionNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+
+		run, err := service.Rerun(previous.ID)
+		require.NoError(t, err)
+		run.Wait()
+		service.Stop()
+		executions, err := service.GetExecutions()
+		require.NoError(t, err)
+		require.Len(t, executions, 2)
+		assert.Equal(t, run.ID, executions[0].ID)
+		assert.Equal(t, task.Exited, executions[0].Status)
+		assert.Equal(t, "hello", executions[0].GetOutputText())
+	})
+}
+
+func TestExecutionService_DiffOutputs(t *testing.T) {
+	service := NewExecutionService(NewMockExecutionStore(), executors.NewCommandRunner(), &RunConfig{Shell: ""})
+	newExecution := func(lines ...string) *models.Execution {
+		output := []models.OutputLine{}
+		for _, line := range lines {
+			output = append(output, models.OutputLine{Stream: models.OutputStreamStdout, Text: line})
+		}
+		return &models.Execution{Output: output}
+	}
+
+	lines := service.DiffOutputs(newExecution("a", "b", "c"), newExecution("a", "c", "d"))
+	rendered := []string{}
+	for _, line := range lines {
+		rendered = append(rendered, line.Op.Prefix()+line.Text)
+	}
+	assert.Equal(t, "  a\n- b\n  c\n+ d", strings.Join(rendered, "\n"))
+	assert.False(t, diff.HasChanges(service.DiffOutputs(newExecution("a"), newExecution("a"))))
+}
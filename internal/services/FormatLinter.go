@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// formatRule is the rule of the issue reported by the format linter
+const formatRule = "format"
+
+// formatLinter reports the scripts that are not formatted in the shfmt
+// style, the issue provides a fix formatting the whole script
+type formatLinter struct {
+	config FormatConfig
+}
+
+func newFormatLinter(config FormatConfig) *formatLinter {
+	return &formatLinter{
+		config: config,
+	}
+}
+
+func (*formatLinter) GetName() string {
+	return LinterFormat
+}
+
+func (*formatLinter) IsAvailable() bool {
+	return true
+}
+
+func (*formatLinter) IsDialectSupported(dialect models.ShellDialect) bool {
+	_, ok := getLangVariant(dialect)
+	return ok
+}
+
+func (l *formatLinter) GetSignature(dialect models.ShellDialect, _ models.LintRules) string {
+	variant, _ := getLangVariant(dialect)
+	return fmt.Sprintf("%s %+v", variant, l.config)
+}
+
+// Format returns the script formatted in the shfmt style, a trailing
+// newline is only added if the script had one
+func (l *formatLinter) Format(script string, dialect models.ShellDialect) (string, error) {
+	variant, ok := getLangVariant(dialect)
+	if !ok {
+		return script, ErrShellDialectNotSupported
+	}
+	file, err := syntax.NewParser(syntax.Variant(variant), syntax.KeepComments(true)).
+		Parse(strings.NewReader(script), "")
+	if err != nil {
+		return script, err
+	}
+	formatted := &strings.Builder{}
+	printer := syntax.NewPrinter(
+		syntax.Indent(l.config.Indent),
+		syntax.BinaryNextLine(l.config.BinaryNextLine),
+		syntax.SwitchCaseIndent(l.config.SwitchCaseIndent),
+		syntax.SpaceRedirects(l.config.SpaceRedirects),
+	)
+	if err := printer.Print(formatted, file); err != nil {
+		return script, err
+	}
+	if strings.HasSuffix(script, "\n") {
+		return formatted.String(), nil
+	}
+	return strings.TrimSuffix(formatted.String(), "\n"), nil
+}
+
+// Lint does not report syntax errors, they are reported by shellcheck or by the syntax linter
+func (l *formatLinter) Lint(script string, dialect models.ShellDialect, _ models.LintRules) ([]ShellCheckIssue, error) {
+	formatted, err := l.Format(script, dialect)
+	if err != nil || formatted == script {
+		return []ShellCheckIssue{}, nil //nolint:nilerr // syntax errors are reported by the other linters
+	}
+	line := getFirstDifferentLine(script, formatted)
+	endLine, endColumn := getIssuePosition(script, len(script))
+	return []ShellCheckIssue{
+		{
+			Fix: &ShellCheckFix{
+				Replacements: []ShellCheckReplacement{
+					{
+						InsertionPoint: "afterEnd",
+						Replacement:    formatted,
+						Line:           1,
+						Column:         1,
+						EndLine:        endLine,
+						EndColumn:      endColumn,
+					},
+				},
+			},
+			File:      "-",
+			Level:     string(models.LintLevelStyle),
+			Message:   "Script is not formatted (shfmt style)",
+			Linter:    LinterFormat,
+			Rule:      formatRule,
+			Line:      line,
+			EndLine:   line,
+			Column:    1,
+			EndColumn: 1,
+			Code:      0,
+		},
+	}, nil
+}
+
+// getFirstDifferentLine returns the number of the first line that differs
+func getFirstDifferentLine(script, formatted string) int {
+	scriptLines := strings.Split(script, "\n")
+	formattedLines := strings.Split(formatted, "\n")
+	for i, line := range scriptLines {
+		if i >= len(formattedLines) || line != formattedLines[i] {
+			return i + 1
+		}
+	}
+	return len(scriptLines)
+}
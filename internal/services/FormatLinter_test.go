@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatLinter(t *testing.T) {
+	rules := models.GetDefaultLintRules()
+	linter := newFormatLinter(FormatConfig{Indent: 2, BinaryNextLine: false, SwitchCaseIndent: false, SpaceRedirects: false})
+
+	t.Run("Formatted script", func(t *testing.T) {
+		issues, err := linter.Lint("if true; then\n  echo ok # comment\nfi", models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("Syntax errors ignored", func(t *testing.T) {
+		issues, err := linter.Lint("echo (", models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("Fix formats the whole script", func(t *testing.T) {
+		script := "ls  -al\nif true;then\n\techo   ok # comment\nfi"
+		issues, err := linter.Lint(script, models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, formatRule, issues[0].Rule)
+		assert.Equal(t, "style", issues[0].Level)
+		assert.Equal(t, 1, issues[0].Line)
+
+		fixed, err := ApplyFixes(script, issues)
+		require.NoError(t, err)
+		assert.Equal(t, "ls -al\nif true; then\n  echo ok # comment\nfi", fixed)
+	})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -44,11 +45,23 @@ const (
 	CommandCategoryAll CommandCategory = "all"
 )
 
+// SnippetReport counts the files scanned by IngestSnippets and the commands
+// extracted by outcome, the ignored commands being filtered out
+type SnippetReport struct {
+	Files       int
+	Failed      int
+	Added       int
+	Existing    int
+	FilteredOut int
+}
+
 type HistoryService struct {
 	ingestor          HistoryIngestor
 	homeDir           string
+	historyDialect    models.ShellDialect
 	dbService         *DBService
 	lintService       *LintService
+	taskExecutor      TaskExecutorInterface
 	scriptRegexp      *regexp.Regexp
 	ignoreLinesRegexp []*regexp.Regexp
 }
@@ -57,12 +70,15 @@ func NewHistoryService(
 	ingestor HistoryIngestor,
 	dbService *DBService,
 	lintService *LintService,
+	taskExecutor TaskExecutorInterface,
 ) *HistoryService {
 	return &HistoryService{
 		ingestor:          ingestor,
 		dbService:         dbService,
 		lintService:       lintService,
+		taskExecutor:      taskExecutor,
 		homeDir:           "",
+		historyDialect:    models.DefaultShellDialect,
 		scriptRegexp:      nil,
 		ignoreLinesRegexp: nil,
 	}
@@ -205,6 +221,9 @@ func (s *HistoryService) IngestHistory() error {
 	}
 	slog.Debug("Max command timestamp", "timestamp", maxCommandTimestamp)
 
+	s.historyDialect = getHistoryFileShellDialect(historyFilePath)
+	slog.Debug("History file shell dialect", "file", historyFilePath, "shell", s.historyDialect)
+
 	if err := s.ingestor.ParseBashHistory(historyFilePath, maxCommandTimestamp, s.processCmd); err != nil {
 		slog.Error("Error ingesting history", "file", historyFilePath, "error", err)
 		return err
@@ -213,6 +232,31 @@ func (s *HistoryService) IngestHistory() error {
 	return nil
 }
 
+// getHistoryShellDialect returns the shell dialect of the commands of the
+// history: the dialect of the shell that launched the application, else the
+// dialect of the HISTFILE history file as this variable is rarely exported
+func getHistoryShellDialect(currentShell ShellType) models.ShellDialect {
+	if dialect, ok := models.ParseShellDialect(string(currentShell)); ok {
+		return dialect
+	}
+	return getHistoryFileShellDialect(os.Getenv("HISTFILE"))
+}
+
+// getHistoryFileShellDialect deduces the shell dialect of the commands
+// from the history file name (eg: ~/.zsh_history, ~/.bash_history)
+func getHistoryFileShellDialect(historyFilePath string) models.ShellDialect {
+	fileName := filepath.Base(historyFilePath)
+	switch {
+	case strings.Contains(fileName, "zsh"):
+		return models.ShellDialectZsh
+	case strings.Contains(fileName, "ksh"), fileName == ".sh_history":
+		// .sh_history is the default history file of ksh
+		return models.ShellDialectKsh
+	default:
+		return models.DefaultShellDialect
+	}
+}
+
 func (s *HistoryService) processCmd(historyCmd processors.HistoryCommand) (processors.CommandImportedStatus, error) {
 	if importStatus, err := s.checkIfCommandShouldBeSaved(historyCmd); err != nil {
 		return processors.CommandImportedStatusError, err
@@ -225,16 +269,185 @@ func (s *HistoryService) processCmd(historyCmd processors.HistoryCommand) (proce
 		historyCmd.Elapsed,
 		historyCmd.Timestamp,
 	)
+	cmd.Shell = models.DetectShellDialectFromShebang(cmd.Script, s.historyDialect)
+	return s.saveIngestedCommand(cmd)
+}
 
-	s.lintService.LintCommand(cmd)
+// saveIngestedCommand saves a new imported command and lints it in background
+func (s *HistoryService) saveIngestedCommand(cmd *models.Command) (processors.CommandImportedStatus, error) {
 	if err := s.dbService.SaveCommand(cmd); err != nil {
 		slog.Error("Error saving command to database", "command", cmd, "error", err)
 		return processors.CommandImportedStatusError, err
 	}
 	slog.Info("Command saved successfully", "command", cmd)
+	s.submitLint(cmd)
 	return processors.CommandImportedStatusNew, nil
 }
 
+// IngestSnippets extracts the commands of the markdown files and of the
+// shell scripts of the given paths, the directories being scanned
+// recursively, and imports them like the commands of the history: the
+// commands already existing or matching the ignore rules are skipped and the
+// new commands are linted in background.
+func (s *HistoryService) IngestSnippets(paths []string) (*SnippetReport, error) {
+	report := &SnippetReport{Files: 0, Failed: 0, Added: 0, Existing: 0, FilteredOut: 0}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if filePath == path && !entry.IsDir() {
+				// a file provided explicitly is read even without a known extension
+				return s.ingestSnippetFile(filePath, report)
+			}
+			if entry.IsDir() && filePath != path && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if entry.IsDir() || !IsSnippetFile(filePath) {
+				return nil
+			}
+			return s.ingestSnippetFile(filePath, report)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	slog.Info("Snippets ingested", "paths", paths, "report", report)
+	return report, nil
+}
+
+// ingestSnippetFile imports the commands of a file, a file that cannot be
+// parsed is counted as failed but does not stop the ingestion
+func (s *HistoryService) ingestSnippetFile(path string, report *SnippetReport) error {
+	report.Files++
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	snippets, err := ExtractFileSnippets(path)
+	if err != nil {
+		slog.Warn("Cannot extract the commands of the file", "file", path, "error", err)
+		report.Failed++
+		return nil
+	}
+	for _, snippet := range snippets {
+		status, err := s.processSnippet(snippet, info.ModTime())
+		if err != nil {
+			return err
+		}
+		switch status {
+		case processors.CommandImportedStatusNew:
+			report.Added++
+		case processors.CommandImportedStatusAlreadyExists:
+			report.Existing++
+		default:
+			report.FilteredOut++
+		}
+	}
+	return nil
+}
+
+func (s *HistoryService) processSnippet(
+	snippet *Snippet, timestamp time.Time,
+) (processors.CommandImportedStatus, error) {
+	historyCmd := processors.HistoryCommand{
+		Timestamp:     timestamp,
+		Command:       snippet.Script,
+		Elapsed:       0,
+		ParseFinished: true,
+	}
+	if importStatus, err := s.checkIfCommandShouldBeSaved(historyCmd); err != nil {
+		return processors.CommandImportedStatusError, err
+	} else if importStatus != processors.CommandImportedStatusNew {
+		slog.Debug("Snippet already exists in database or is ignored", "snippet", snippet, "status", importStatus)
+		return importStatus, nil
+	}
+	cmd := models.NewCommand(snippet.Script, 0, timestamp)
+	cmd.Title = truncateTitle(snippet.Title)
+	cmd.Description = snippet.Description
+	cmd.Shell = snippet.Shell
+	return s.saveIngestedCommand(cmd)
+}
+
+// ImportRcFiles saves a command for each alias and each function defined by
+// the given rc files, the shell of the commands is deduced from the file name
+// (eg: ~/.zshrc). The commands already existing are skipped, the name of an
+// alias or of a function is its short name if it is a valid short name.
+func (s *HistoryService) ImportRcFiles(paths []string) (*SnippetReport, error) {
+	report := &SnippetReport{Files: 0, Failed: 0, Added: 0, Existing: 0, FilteredOut: 0}
+	for _, path := range paths {
+		report.Files++
+		info, err := os.Stat(path)
+		if err != nil {
+			return report, err
+		}
+		content, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			return report, err
+		}
+		snippets, err := ExtractRcFileSnippets(string(content), getHistoryFileShellDialect(path))
+		if err != nil {
+			slog.Warn("Cannot extract the aliases and the functions of the file", "file", path, "error", err)
+			report.Failed++
+			continue
+		}
+		for _, snippet := range snippets {
+			added, err := s.saveRcFileSnippet(snippet, info.ModTime())
+			if err != nil {
+				return report, err
+			}
+			if added {
+				report.Added++
+			} else {
+				report.Existing++
+			}
+		}
+	}
+	slog.Info("Rc files imported", "paths", paths, "report", report)
+	return report, nil
+}
+
+func (s *HistoryService) saveRcFileSnippet(snippet *Snippet, timestamp time.Time) (added bool, err error) {
+	existingCmd, err := s.dbService.GetCommandByScript(snippet.Script)
+	if err != nil {
+		return false, err
+	}
+	if existingCmd != nil {
+		slog.Debug("Command already exists in database", "snippet", snippet)
+		return false, nil
+	}
+	cmd := models.NewCommand(snippet.Script, 0, timestamp)
+	cmd.Title = truncateTitle(snippet.Title)
+	cmd.Description = snippet.Description
+	cmd.Shell = snippet.Shell
+	cmd.Status = models.CommandStatusSaved
+	if err := models.ValidateShortName(snippet.ShortName); err == nil {
+		cmd.ShortName = snippet.ShortName
+	}
+	if _, err := s.saveIngestedCommand(cmd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SaveCommand stores a new command along with its tags and its folder, and
+// lints it in background
+func (s *HistoryService) SaveCommand(command *models.Command) error {
+	if err := s.dbService.SaveCommand(command); err != nil {
+		slog.Error("Error saving command to database", "command", command, "error", err)
+		return err
+	}
+	if err := s.dbService.SetCommandTags(command.ID, command.Tags); err != nil {
+		return err
+	}
+	if err := s.dbService.SetCommandFolder(command.ID, command.Folder); err != nil {
+		return err
+	}
+	slog.Info("Command saved successfully", "command", command)
+	s.submitLint(command)
+	return nil
+}
+
 func (s *HistoryService) UpdateCommand(command *models.Command) (newCommand *models.Command, err error) {
 	slog.Debug("Updating command", "id", command.ID, "status", command.Status)
 
@@ -249,23 +462,38 @@ func (s *HistoryService) UpdateCommand(command *models.Command) (newCommand *mod
 	// For non-IMPORTED commands, just update directly
 	command.ModificationDatetime = time.Now()
 	command.Status = models.CommandStatusSaved
-	// Lint the new command
-	s.lintCommand(command)
+	// the previous lint result does not match the new script
+	command.LintStatus = models.LintStatusNotAvailable
+	command.LintIssues = "[]"
+	command.LintRules = ""
 	err = s.dbService.UpdateCommand(command)
 	if err != nil {
 		slog.Error("Error updating command in database", "id", command.ID, "error", err)
 		return nil, err
 	}
+	// Lint the new command
+	s.submitLint(command)
 	return command, nil
 }
 
-func (s *HistoryService) lintCommand(command *models.Command) {
-	if command.Status != models.CommandStatusSaved {
-		slog.Warn("Command is not in a state that can be linted", "id", command.ID, "status", command.Status)
+// submitLint lints the command in background and stores the result in database.
+// A copy of the command is linted so that the caller can keep using it.
+func (s *HistoryService) submitLint(command *models.Command) {
+	if !s.lintService.IsLintingAvailable() {
+		command.LintStatus = models.LintStatusNotAvailable
 		return
 	}
-
-	s.lintService.LintCommand(command)
+	linted := *command
+	s.taskExecutor.Submit(fmt.Sprintf("lint command #%d", command.ID), func() error {
+		s.lintService.LintCommand(&linted)
+		if err := s.dbService.UpdateCommandLint(&linted); err != nil {
+			return &LintTaskError{CommandID: linted.ID, Err: err}
+		}
+		if linted.LintStatus == models.LintStatusShellcheckFailed {
+			return &LintTaskError{CommandID: linted.ID, Err: ErrShellCheckFailed}
+		}
+		return nil
+	})
 }
 
 func (s *HistoryService) duplicateCommandAsObsolete(commandID resource.ID) error {
@@ -295,39 +523,79 @@ func (s *HistoryService) RestoreCommand(commands []*models.Command) error {
 	for _, cmd := range commands {
 		cmd.Status = models.CommandStatusSaved
 		cmd.ModificationDatetime = time.Now()
-		s.lintService.LintCommand(cmd)
 		err := s.dbService.UpdateCommand(cmd)
 		if err != nil {
 			slog.Error("Error restoring command", "id", cmd.ID, "error", err)
 			return err
 		}
+		s.submitLint(cmd)
 	}
 	return nil
 }
 
-func (s *HistoryService) ComposeCommand(commands []*models.Command) (*models.Command, error) {
-	if len(commands) < 1 {
+// ComposeCommand saves a new command running the steps of the composition
+func (s *HistoryService) ComposeCommand(composition *models.Composition) (*models.Command, error) {
+	if len(composition.Steps) < 1 {
 		return nil, &ComposeInsufficientCommandsProvidedError{nil}
 	}
-	newCommand := models.NewCommand(
-		s.generateComposeCommandScript(commands),
-		0,
-		time.Now(),
-	)
-	s.lintService.LintCommand(newCommand)
+	newCommand := models.NewCommand(ComposeScript(composition), 0, time.Now())
+	newCommand.Title = composition.Title
+	newCommand.Status = models.CommandStatusSaved
 
-	err := s.dbService.SaveCommand(newCommand)
-	return newCommand, err
+	if err := s.dbService.SaveCommand(newCommand); err != nil {
+		return newCommand, err
+	}
+	s.submitLint(newCommand)
+	return newCommand, nil
 }
 
-func (s *HistoryService) generateComposeCommandScript(commands []*models.Command) string {
-	var script strings.Builder
-	script.WriteString("#!/usr/bin/env bash\n")
-	script.WriteString("set -e -o pipefail -o errexit\n")
+// RelintCommands lints again the given commands in background, the lint
+// cache avoids running shellcheck on scripts that have already been linted
+func (s *HistoryService) RelintCommands(commands []*models.Command) int {
 	for _, cmd := range commands {
-		script.WriteString(fmt.Sprintf("echo '%s'\n", cmd.Script))
+		s.submitLint(cmd)
+	}
+	slog.Info("Commands submitted for lint", "count", len(commands))
+	return len(commands)
+}
+
+// RelintCategory lints again all the commands of the given category
+func (s *HistoryService) RelintCategory(category CommandCategory) (int, error) {
+	commands, err := s.GetCommandsByStatus(s.GetCommandStatusesByCategory(category)...)
+	if err != nil {
+		return 0, err
+	}
+	return s.RelintCommands(commands), nil
+}
+
+// GetAllCommandCategories returns the categories in the order they are displayed
+func (*HistoryService) GetAllCommandCategories() []CommandCategory {
+	return []CommandCategory{
+		CommandCategoryAvailable,
+		CommandCategorySaved,
+		CommandCategoryNew,
+		CommandCategoryDeleted,
+		CommandCategoryAll,
+	}
+}
+
+// GetCommandStatusesByCategory returns the statuses of the commands of a category,
+// no status means all the commands
+func (*HistoryService) GetCommandStatusesByCategory(category CommandCategory) []models.CommandStatus {
+	switch category {
+	case CommandCategoryAvailable:
+		return []models.CommandStatus{models.CommandStatusImported, models.CommandStatusSaved}
+	case CommandCategorySaved:
+		return []models.CommandStatus{models.CommandStatusSaved}
+	case CommandCategoryNew:
+		return []models.CommandStatus{models.CommandStatusImported}
+	case CommandCategoryDeleted:
+		return []models.CommandStatus{models.CommandStatusDeleted}
+	case CommandCategoryAll:
+		return []models.CommandStatus{}
+	default:
+		return []models.CommandStatus{}
 	}
-	return script.String()
 }
 
 // CreateCommandsString creates a concatenated string of all commands
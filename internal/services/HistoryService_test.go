@@ -54,3 +54,32 @@ func TestHistoryService_CreateCommandsString(t *testing.T) {
 		})
 	}
 }
+
+func TestGetHistoryFileShellDialect(t *testing.T) {
+	tests := []struct {
+		historyFilePath string
+		want            models.ShellDialect
+	}{
+		{"/home/user/.bash_history", models.ShellDialectBash},
+		{"/home/user/.zsh_history", models.ShellDialectZsh},
+		{"/home/user/.zhistory_zsh", models.ShellDialectZsh},
+		{"/home/user/.sh_history", models.ShellDialectKsh},
+		{"/home/user/.custom_history", models.DefaultShellDialect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.historyFilePath, func(t *testing.T) {
+			assert.Equal(t, tt.want, getHistoryFileShellDialect(tt.historyFilePath))
+		})
+	}
+}
+
+func TestGetHistoryShellDialect(t *testing.T) {
+	t.Setenv("HISTFILE", "/home/user/.zsh_history")
+	assert.Equal(t, models.ShellDialectBash, getHistoryShellDialect(ShellTypeBash), "the detected shell is used first")
+	assert.Equal(t, models.ShellDialectZsh, getHistoryShellDialect(ShellTypeUnknown))
+
+	t.Setenv("HISTFILE", "")
+	assert.Equal(t, models.ShellDialectZsh, getHistoryShellDialect(ShellTypeZsh))
+	assert.Equal(t, models.DefaultShellDialect, getHistoryShellDialect(ShellTypeUnknown))
+}
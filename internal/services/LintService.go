@@ -1,51 +1,144 @@
 package services
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 )
 
-// ErrShellCheckNotFound indicates that the shellcheck command was not found in the system's PATH.
-var ErrShellCheckNotFound = errors.New("shellcheck command not found")
+var (
+	// ErrShellCheckNotFound indicates that the shellcheck command was not found in the system's PATH.
+	ErrShellCheckNotFound = errors.New("shellcheck command not found")
+	// ErrNoLinterAvailable indicates that all the configured linters are unavailable.
+	ErrNoLinterAvailable = errors.New("no linter available, check the linters configuration")
+	// ErrShellDialectNotSupported indicates that shellcheck cannot lint the command's shell dialect.
+	ErrShellDialectNotSupported = errors.New("shell dialect not supported by shellcheck")
+	// ErrShellCheckFailed indicates that shellcheck could not lint the script.
+	ErrShellCheckFailed = errors.New("shellcheck failed")
+	// ErrInvalidFixPosition indicates that a shellcheck fix does not match the script content.
+	ErrInvalidFixPosition = errors.New("fix position outside of the script")
+	// ErrMissingRuleName indicates that a custom lint rule has no name.
+	ErrMissingRuleName = errors.New("missing rule name")
+	// ErrMissingRulePattern indicates that a custom lint rule has no pattern.
+	ErrMissingRulePattern = errors.New("missing rule pattern")
+)
+
+// Names of the linters, used in the configuration and in the issues
+const (
+	LinterShellcheck = "shellcheck"
+	LinterSyntax     = "syntax"
+	LinterRules      = "rules"
+	LinterFormat     = "format"
+)
+
+// GetLinterNames returns the names of the linters in the order they are run
+func GetLinterNames() []string {
+	return []string{LinterShellcheck, LinterSyntax, LinterRules, LinterFormat}
+}
+
+// shellcheckTabStop is the tab width used by shellcheck to compute columns
+const shellcheckTabStop = 8
 
 // ShellCheckIssue represents a single issue reported by shellcheck.
-// Fields correspond to the JSON output format of shellcheck.
+// Fields correspond to the JSON output format of shellcheck, the other
+// linters use the same format and identify their issues using Rule.
 type ShellCheckIssue struct {
-	Fix *struct { // Optional fix information
-		Replacements []struct {
-			InsertionPoint string `json:"insertionPoint"` // "beginning", "end", etc.
-			Replacement    string `json:"replacement"`
-			Line           int    `json:"line"`
-			Column         int    `json:"column"`
-			EndLine        int    `json:"endLine"`
-			EndColumn      int    `json:"endColumn"`
-		} `json:"replacements"`
-	} `json:"fix"`
-	File      string `json:"file"`
-	Level     string `json:"level"` // e.g., "error", "warning", "info", "style"
-	Message   string `json:"message"`
-	Line      int    `json:"line"`
-	EndLine   int    `json:"endLine"`
-	Column    int    `json:"column"`
-	EndColumn int    `json:"endColumn"`
-	Code      int    `json:"code"` // e.g., SC2086
-}
-
-// LintService provides functionality to lint shell scripts using shellcheck.
+	Fix       *ShellCheckFix `json:"fix"` // Optional fix information
+	File      string         `json:"file"`
+	Level     string         `json:"level"` // e.g., "error", "warning", "info", "style"
+	Message   string         `json:"message"`
+	Linter    string         `json:"linter,omitempty"` // e.g., shellcheck, syntax, rules, format
+	Rule      string         `json:"rule,omitempty"`   // e.g., no-sudo-rm-rf, empty for shellcheck issues
+	Line      int            `json:"line"`
+	EndLine   int            `json:"endLine"`
+	Column    int            `json:"column"`
+	EndColumn int            `json:"endColumn"`
+	Code      int            `json:"code"` // e.g., SC2086
+}
+
+// GetCode returns the shellcheck code (eg: SC2086) or the rule of the issue
+func (i *ShellCheckIssue) GetCode() string {
+	if i.Rule != "" {
+		return i.Rule
+	}
+	return fmt.Sprintf("SC%d", i.Code)
+}
+
+// ShellCheckFix is the automatic fix proposed by shellcheck for an issue
+type ShellCheckFix struct {
+	Replacements []ShellCheckReplacement `json:"replacements"`
+}
+
+// ShellCheckReplacement replaces the text between (Line, Column) and
+// (EndLine, EndColumn) excluded by Replacement.
+// Lines and columns start at 1, columns are computed with tab stops of 8.
+type ShellCheckReplacement struct {
+	InsertionPoint string `json:"insertionPoint"` // "beforeStart", "afterEnd"
+	Replacement    string `json:"replacement"`
+	Line           int    `json:"line"`
+	Column         int    `json:"column"`
+	EndLine        int    `json:"endLine"`
+	EndColumn      int    `json:"endColumn"`
+}
+
+// LintService lints shell scripts by running several linters in sequence,
+// shellcheck being the main one.
 type LintService struct {
-	commandExecutor CommandExecutorInterface
-	lookupExecutor  LookupExecutorInterface
-	shellCheckPath  string
+	commandExecutor   CommandExecutorInterface
+	lookupExecutor    LookupExecutorInterface
+	lintCache         LintCacheInterface
+	lintConfig        *LintConfig
+	baseRules         models.LintRules
+	rcPaths           []string
+	linters           []Linter
+	shellCheckPath    string
+	shellCheckVersion string
 }
 
 type LintServiceOption func(*LintService)
 
+// WithLintConfig applies the lint section of the configuration file
+func WithLintConfig(lintConfig *LintConfig) LintServiceOption {
+	return func(s *LintService) {
+		s.lintConfig = lintConfig
+	}
+}
+
+// WithShellcheckRCPaths overrides the .shellcheckrc files looked up during Init
+func WithShellcheckRCPaths(rcPaths []string) LintServiceOption {
+	return func(s *LintService) {
+		s.rcPaths = rcPaths
+	}
+}
+
+// WithLinters replaces the default linters
+func WithLinters(linters ...Linter) LintServiceOption {
+	return func(s *LintService) {
+		s.linters = linters
+	}
+}
+
+// WithLintCache stores the lint results so that unchanged scripts are not linted twice
+func WithLintCache(lintCache LintCacheInterface) LintServiceOption {
+	return func(s *LintService) {
+		s.lintCache = lintCache
+	}
+}
+
 func getLogMappingForLintStatus(lintStatus models.LintStatus) slog.Level {
 	switch lintStatus {
 	case models.LintStatusWarning:
@@ -68,22 +161,135 @@ func NewLintService(options ...LintServiceOption) *LintService {
 	defaultCommandExecutor := &executors.DefaultCommandExecutor{}
 	lookupExecutor := &executors.DefaultLookupExecutor{}
 	service := &LintService{
-		shellCheckPath:  "",
-		commandExecutor: defaultCommandExecutor,
-		lookupExecutor:  lookupExecutor,
+		shellCheckPath:    "",
+		shellCheckVersion: "",
+		commandExecutor:   defaultCommandExecutor,
+		lookupExecutor:    lookupExecutor,
+		lintCache:         nil,
+		lintConfig:        nil,
+		baseRules:         models.GetDefaultLintRules(),
+		rcPaths:           getShellcheckRCPaths(),
+		linters:           nil,
 	}
 	for _, option := range options {
 		option(service)
 	}
+	if service.linters == nil {
+		service.linters = service.getDefaultLinters()
+	}
 
 	return service
 }
 
+// getDefaultLinters returns the linters selected in the configuration, all
+// the linters but format if none is selected. The syntax check is only used
+// if shellcheck cannot lint the script.
+func (s *LintService) getDefaultLinters() []Linter {
+	lintConfig := s.lintConfig
+	if lintConfig == nil {
+		lintConfig = &newDefaultConfig().Lint
+	}
+	shellcheck := newShellcheckLinter(s)
+	linters := []Linter{
+		shellcheck,
+		newSyntaxLinter(shellcheck),
+		newRulesLinter(lintConfig.CustomRules),
+		newFormatLinter(lintConfig.Format),
+	}
+	if len(lintConfig.Linters) == 0 {
+		// the format is opt-in, most of the commands of the history are
+		// one-liners that were not written in the shfmt style
+		return slices.DeleteFunc(linters, func(linter Linter) bool {
+			return linter.GetName() == LinterFormat
+		})
+	}
+	return slices.DeleteFunc(linters, func(linter Linter) bool {
+		return !slices.Contains(lintConfig.Linters, linter.GetName())
+	})
+}
+
+// getShellcheckRCPaths returns the user .shellcheckrc files, in the order
+// shellcheck looks them up
+func getShellcheckRCPaths() []string {
+	rcPaths := []string{}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		rcPaths = append(rcPaths, filepath.Join(homeDir, ".shellcheckrc"))
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		rcPaths = append(rcPaths, filepath.Join(configDir, "shellcheckrc"))
+	}
+	return rcPaths
+}
+
+// loadShellcheckRC parses the first existing .shellcheckrc file,
+// only the disable and enable directives are supported
+func loadShellcheckRC(rcPaths []string) (rules models.LintRules, rcPath string, found bool) {
+	rules = models.LintRules{
+		Severity:     "",
+		WarningLevel: "",
+		ErrorLevel:   "",
+		Exclude:      []string{},
+		Enable:       []string{},
+		Sources:      []string{},
+	}
+	for _, rcPath := range rcPaths {
+		content, err := os.ReadFile(rcPath)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			name, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok {
+				continue
+			}
+			values := strings.Split(value, ",")
+			switch strings.TrimSpace(name) {
+			case "disable":
+				rules.Exclude = append(rules.Exclude, values...)
+			case "enable":
+				rules.Enable = append(rules.Enable, values...)
+			}
+		}
+		return rules, rcPath, true
+	}
+	return rules, "", false
+}
+
+// initBaseRules merges the .shellcheckrc rules and the configured rules
+func (s *LintService) initBaseRules() {
+	s.baseRules = models.GetDefaultLintRules()
+	if rcRules, rcPath, found := loadShellcheckRC(s.rcPaths); found {
+		slog.Info("Lint rules loaded from shellcheckrc", "file", rcPath)
+		s.baseRules = s.baseRules.Merge(rcRules, filepath.Base(rcPath))
+	}
+	if s.lintConfig != nil {
+		s.baseRules = s.baseRules.Merge(s.lintConfig.LintRules, "config")
+	}
+}
+
+// GetEffectiveRules returns the rules applied to a command having the given tags
+func (s *LintService) GetEffectiveRules(tags []string) models.LintRules {
+	rules := s.baseRules
+	if s.lintConfig == nil {
+		return rules
+	}
+	sortedTags := slices.Clone(tags)
+	slices.Sort(sortedTags)
+	for _, tag := range sortedTags {
+		if tagRules, ok := s.lintConfig.Tags[tag]; ok {
+			rules = rules.Merge(tagRules, "tag:"+tag)
+		}
+	}
+	return rules
+}
+
 func (s *LintService) Init() error {
+	s.initBaseRules()
 	path, err := s.lookupExecutor.LookPath("shellcheck")
 	if err != nil {
 		if errors.Is(err, exec.ErrNotFound) {
-			slog.Warn("shellcheck command not found in PATH. Linting will be disabled.", "error", err)
+			slog.Warn("shellcheck command not found in PATH. Only the built-in linters will be used.", "error", err)
 			// Return the service anyway, but LintScript will return ErrShellCheckNotFound
 			return nil
 		}
@@ -92,20 +298,145 @@ func (s *LintService) Init() error {
 	}
 	slog.Info("Found shellcheck executable", "path", path)
 	s.shellCheckPath = path
+	s.shellCheckVersion = s.detectShellCheckVersion()
 	return nil
 }
 
+// detectShellCheckVersion returns the version of shellcheck,
+// or an empty string if it cannot be retrieved
+func (s *LintService) detectShellCheckVersion() string {
+	output, _, err := s.commandExecutor.ExecuteCommandWithStdin(s.shellCheckPath, []string{"--version"}, "")
+	if err != nil {
+		slog.Warn("Unable to retrieve shellcheck version, lint cache disabled", "error", err)
+		return ""
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if version, found := strings.CutPrefix(line, "version:"); found {
+			slog.Info("Shellcheck version detected", "version", strings.TrimSpace(version))
+			return strings.TrimSpace(version)
+		}
+	}
+	slog.Warn("Unable to parse shellcheck version, lint cache disabled", "output", output)
+	return ""
+}
+
+// GetShellCheckVersion returns the detected shellcheck version
+func (s *LintService) GetShellCheckVersion() string {
+	return s.shellCheckVersion
+}
+
+// getLintArgs returns the shellcheck arguments used to lint a script of the given dialect.
+// .shellcheckrc files are ignored by shellcheck as they are part of the rules.
+func (*LintService) getLintArgs(dialect models.ShellDialect, rules models.LintRules) []string {
+	args := []string{"--norc", "-s", string(dialect), "-f", "json", "-x"}
+	excludedCodes := slices.DeleteFunc(slices.Clone(rules.Exclude), func(code string) bool {
+		return !models.IsShellcheckCode(code)
+	})
+	if len(excludedCodes) > 0 {
+		args = append(args, "-e", strings.Join(excludedCodes, ","))
+	}
+	if len(rules.Enable) > 0 {
+		args = append(args, "-o", strings.Join(rules.Enable, ","))
+	}
+	if rules.Severity != "" && rules.Severity != models.LintLevelStyle {
+		args = append(args, "-S", string(rules.Severity))
+	}
+	// Use "--" to indicate end of options and treat subsequent args as filenames (or stdin in this case)
+	return append(args, "--", "-")
+}
+
+// getCacheKey returns the lint cache key of the script linted by the given
+// linters with the given rules, or an empty string if the cache cannot be used
+func (s *LintService) getCacheKey(
+	script string, dialect models.ShellDialect, linters []Linter, rules models.LintRules,
+) string {
+	if s.lintCache == nil {
+		return ""
+	}
+	signatures := make([]string, 0, len(linters))
+	for _, linter := range linters {
+		signature := linter.GetSignature(dialect, rules)
+		if signature == "" {
+			return ""
+		}
+		signatures = append(signatures, linter.GetName()+":"+signature)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(strings.Join(signatures, "\n") + "\n" +
+		rules.ToJSON() + "\n" + script))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// lintFromCache sets the cached lint result on the command, ok is false on cache miss
+func (s *LintService) lintFromCache(cacheKey string, cmd *models.Command) (issues []ShellCheckIssue, ok bool) {
+	if cacheKey == "" {
+		return nil, false
+	}
+	lintStatus, lintIssues, found, err := s.lintCache.GetLintCache(cacheKey)
+	if err != nil || !found {
+		return nil, false
+	}
+	issues, err = ParseLintIssues(lintIssues)
+	if err != nil {
+		slog.Warn("Invalid lint cache entry ignored", "cacheKey", cacheKey, "error", err)
+		return nil, false
+	}
+	cmd.LintStatus = lintStatus
+	cmd.LintIssues = lintIssues
+	slog.Debug("Lint result retrieved from cache", "id", cmd.ID, "lintStatus", lintStatus)
+	return issues, true
+}
+
+// IsDialectSupported returns true if at least one linter is able to lint
+// scripts written in the given shell dialect
+func (s *LintService) IsDialectSupported(dialect models.ShellDialect) bool {
+	return len(s.getLinters(dialect)) > 0
+}
+
+// getLinters returns the available linters able to lint the given dialect
+func (s *LintService) getLinters(dialect models.ShellDialect) []Linter {
+	linters := []Linter{}
+	for _, linter := range s.linters {
+		if linter.IsAvailable() && linter.IsDialectSupported(dialect) {
+			linters = append(linters, linter)
+		}
+	}
+	return linters
+}
+
+// isShellcheckDialect returns true if shellcheck is able to lint scripts
+// written in the given shell dialect (zsh is not supported by shellcheck).
+func isShellcheckDialect(dialect models.ShellDialect) bool {
+	switch dialect {
+	case models.ShellDialectBash, models.ShellDialectSh, models.ShellDialectDash, models.ShellDialectKsh:
+		return true
+	case models.ShellDialectZsh:
+		return false
+	default:
+		return false
+	}
+}
+
 // LintScript runs shellcheck on the provided script content and returns the issues found.
-// It returns ErrShellCheckNotFound if shellcheck was not found during service initialization.
-func (s *LintService) LintScript(scriptContent string) ([]ShellCheckIssue, error) {
+// It returns ErrShellCheckNotFound if shellcheck was not found during service initialization
+// and ErrShellDialectNotSupported if shellcheck cannot lint the given dialect.
+func (s *LintService) LintScript(scriptContent string, dialect models.ShellDialect) ([]ShellCheckIssue, error) {
+	return s.lintScriptWithRules(scriptContent, dialect, s.GetEffectiveRules(nil))
+}
+
+func (s *LintService) lintScriptWithRules(
+	scriptContent string, dialect models.ShellDialect, rules models.LintRules,
+) ([]ShellCheckIssue, error) {
 	if s.shellCheckPath == "" {
 		return nil, ErrShellCheckNotFound
 	}
+	if !isShellcheckDialect(dialect) {
+		return nil, ErrShellDialectNotSupported
+	}
 
-	// Use "--" to indicate end of options and treat subsequent args as filenames (or stdin in this case)
 	output, outputErr, err := s.commandExecutor.ExecuteCommandWithStdin(
 		s.shellCheckPath,
-		[]string{"-s", "bash", "-f", "json", "-x", "--", "-"},
+		s.getLintArgs(dialect, rules),
 		scriptContent,
 	)
 
@@ -126,37 +457,54 @@ func (s *LintService) LintScript(scriptContent string) ([]ShellCheckIssue, error
 		return []ShellCheckIssue{}, nil // No issues found or reported
 	}
 
-	var issues []ShellCheckIssue
-	if err := json.Unmarshal([]byte(output), &issues); err != nil {
+	issues, err := ParseLintIssues(output)
+	if err != nil {
 		slog.Error("Failed to parse shellcheck JSON output", "error", err, "output", output)
-		return nil, &ShellcheckParseError{
-			Err:    err,
-			Output: output,
-		}
+		return nil, err
 	}
 
 	slog.Debug("Shellcheck analysis complete", "issueCount", len(issues))
 	return issues, nil
 }
 
-// IsLintingAvailable checks if the shellcheck tool is available.
+// IsLintingAvailable checks if at least one linter is available.
 func (s *LintService) IsLintingAvailable() bool {
-	return s.shellCheckPath != ""
+	for _, linter := range s.linters {
+		if linter.IsAvailable() {
+			return true
+		}
+	}
+	return false
 }
 
+// LintCommand runs the linters supporting the command dialect in sequence
+// and stores the merged issues, the status and the rules used in the command
 func (s *LintService) LintCommand(cmd *models.Command) []ShellCheckIssue {
-	if !s.IsLintingAvailable() {
+	linters := s.getLinters(cmd.Shell)
+	if len(linters) == 0 {
+		slog.Info("Lint skipped, no linter available for the shell dialect", "id", cmd.ID, "shell", cmd.Shell)
 		cmd.LintStatus = models.LintStatusNotAvailable
+		cmd.LintIssues = "[]"
+		cmd.LintRules = ""
 		return nil
 	}
-	issues, err := s.LintScript(cmd.Script)
-	if err != nil && len(issues) == 0 {
-		slog.Error("Error linting command", "command", cmd, "error", err)
+	rules := s.GetEffectiveRules(cmd.Tags)
+	cmd.LintRules = rules.ToJSON()
+	cacheKey := s.getCacheKey(cmd.Script, cmd.Shell, linters, rules)
+	if issues, ok := s.lintFromCache(cacheKey, cmd); ok {
+		return issues
+	}
+	issues, failed := s.runLinters(linters, cmd, rules)
+	if failed && len(issues) == 0 {
 		cmd.LintStatus = models.LintStatusShellcheckFailed
 		cmd.LintIssues = "[]"
 	} else {
-		cmd.LintStatus = s.GetLintResultingStatus(issues)
+		cmd.LintStatus = s.GetLintResultingStatus(issues, rules)
 		cmd.LintIssues = s.FormatLintIssuesAsJSON(issues)
+		if cacheKey != "" && !failed {
+			// cache errors are not blocking, the command is just linted again next time
+			_ = s.lintCache.SaveLintCache(cacheKey, cmd.LintStatus, cmd.LintIssues)
+		}
 	}
 	slog.Log(context.Background(),
 		getLogMappingForLintStatus(cmd.LintStatus),
@@ -168,6 +516,49 @@ func (s *LintService) LintCommand(cmd *models.Command) []ShellCheckIssue {
 	return issues
 }
 
+// runLinters merges the issues of the linters, failed is true if a linter
+// could not lint the script. Shellcheck applies the severity and the
+// exclusions by itself, they are applied here on the issues of the other linters.
+func (*LintService) runLinters(
+	linters []Linter, cmd *models.Command, rules models.LintRules,
+) (issues []ShellCheckIssue, failed bool) {
+	issues = []ShellCheckIssue{}
+	// the placeholders are not shell syntax, eg: <pod> would be a redirection
+	script, placeholders := models.MaskPlaceholders(cmd.Script)
+	for _, linter := range linters {
+		linterIssues, err := linter.Lint(script, cmd.Shell, rules)
+		if err != nil {
+			slog.Error("Error linting command", "id", cmd.ID, "linter", linter.GetName(), "error", err)
+			failed = true
+		}
+		for _, issue := range linterIssues {
+			if issue.Linter == "" {
+				issue.Linter = linter.GetName()
+			}
+			if issue.Rule != "" && (slices.Contains(rules.Exclude, issue.Rule) ||
+				!models.LintLevel(issue.Level).IsAtLeast(cmp.Or(rules.Severity, models.LintLevelStyle))) {
+				continue
+			}
+			issues = append(issues, unmaskIssuePlaceholders(issue, placeholders))
+		}
+	}
+	return issues, failed
+}
+
+// unmaskIssuePlaceholders restores the placeholders in the message and in the
+// fix of an issue found in the masked script
+func unmaskIssuePlaceholders(issue ShellCheckIssue, placeholders map[string]string) ShellCheckIssue {
+	issue.Message = models.UnmaskPlaceholders(issue.Message, placeholders)
+	if issue.Fix != nil {
+		replacements := slices.Clone(issue.Fix.Replacements)
+		for i := range replacements {
+			replacements[i].Replacement = models.UnmaskPlaceholders(replacements[i].Replacement, placeholders)
+		}
+		issue.Fix = &ShellCheckFix{Replacements: replacements}
+	}
+	return issue
+}
+
 func (*LintService) FormatLintIssuesAsJSON(issues []ShellCheckIssue) string {
 	str, err := json.Marshal(issues)
 	if err != nil {
@@ -177,21 +568,166 @@ func (*LintService) FormatLintIssuesAsJSON(issues []ShellCheckIssue) string {
 	return string(str)
 }
 
-func (*LintService) GetLintResultingStatus(issues []ShellCheckIssue) models.LintStatus {
-	if len(issues) == 0 {
-		return models.LintStatusOK
+// GetLintResultingStatus returns the lint status of the issues, the levels
+// leading to warning and error statuses are defined by the rules
+func (*LintService) GetLintResultingStatus(issues []ShellCheckIssue, rules models.LintRules) models.LintStatus {
+	levels := make([]models.LintLevel, 0, len(issues))
+	for _, issue := range issues {
+		levels = append(levels, models.LintLevel(issue.Level))
 	}
-	allInfo := true
+	return rules.GetStatus(levels)
+}
+
+// ParseLintIssues parses the issues stored in the shellcheck JSON format
+// (see Command.LintIssues)
+func ParseLintIssues(lintIssues string) ([]ShellCheckIssue, error) {
+	issues := []ShellCheckIssue{}
+	if lintIssues == "" {
+		return issues, nil
+	}
+	if err := json.Unmarshal([]byte(lintIssues), &issues); err != nil {
+		return nil, &ShellcheckParseError{
+			Err:    err,
+			Output: lintIssues,
+		}
+	}
+	return issues, nil
+}
+
+// GetFixableIssues returns the issues providing an automatic fix
+func GetFixableIssues(issues []ShellCheckIssue) []ShellCheckIssue {
+	fixable := []ShellCheckIssue{}
 	for _, issue := range issues {
-		if issue.Level == "error" {
-			return models.LintStatusError
+		if issue.Fix != nil && len(issue.Fix.Replacements) > 0 {
+			fixable = append(fixable, issue)
 		}
-		if issue.Level != "info" && issue.Level != "style" {
-			allInfo = false
+	}
+	return fixable
+}
+
+// fixRange is a replacement converted to rune offsets in the script
+type fixRange struct {
+	replacement string
+	start       int
+	end         int
+}
+
+// ApplyFixes applies the fixes of the given issues to the script.
+// Fixes are applied as a whole: a fix overlapping a previously accepted
+// fix is skipped, so that the resulting script stays consistent.
+func ApplyFixes(script string, issues []ShellCheckIssue) (string, error) {
+	runes := []rune(script)
+	lineStarts := getLineStarts(runes)
+	accepted := []fixRange{}
+	for _, issue := range issues {
+		if issue.Fix == nil {
+			continue
+		}
+		ranges := make([]fixRange, 0, len(issue.Fix.Replacements))
+		for _, replacement := range issue.Fix.Replacements {
+			start, err := getRuneOffset(runes, lineStarts, replacement.Line, replacement.Column)
+			if err != nil {
+				return script, &ShellcheckFixError{Code: issue.GetCode(), Err: err}
+			}
+			end, err := getRuneOffset(runes, lineStarts, replacement.EndLine, replacement.EndColumn)
+			if err != nil || end < start {
+				return script, &ShellcheckFixError{Code: issue.GetCode(), Err: ErrInvalidFixPosition}
+			}
+			ranges = append(ranges, fixRange{
+				replacement: replacement.Replacement,
+				start:       start,
+				end:         end,
+			})
 		}
+		if fixRangesOverlap(ranges, accepted) {
+			slog.Debug("Fix skipped as it overlaps another fix", "code", issue.Code)
+			continue
+		}
+		accepted = append(accepted, ranges...)
 	}
-	if allInfo {
-		return models.LintStatusOK
+
+	// apply from the end of the script so that offsets stay valid
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].start > accepted[j].start
+	})
+	for _, r := range accepted {
+		runes = append(runes[:r.start], append([]rune(r.replacement), runes[r.end:]...)...)
+	}
+	return string(runes), nil
+}
+
+func fixRangesOverlap(ranges []fixRange, accepted []fixRange) bool {
+	for _, a := range ranges {
+		for _, b := range accepted {
+			if a.overlaps(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlaps returns true if both ranges share characters, an insertion
+// touching the other range is considered as overlapping as the order
+// of the changes would be ambiguous
+func (r fixRange) overlaps(other fixRange) bool {
+	if r.start == r.end || other.start == other.end {
+		return r.start <= other.end && other.start <= r.end
+	}
+	return r.start < other.end && other.start < r.end
+}
+
+// getLineStarts returns the rune offset of the beginning of each line
+func getLineStarts(runes []rune) []int {
+	lineStarts := []int{0}
+	for i, r := range runes {
+		if r == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return lineStarts
+}
+
+// getIssuePosition converts a byte offset in the script to a shellcheck
+// position, columns are computed with tab stops of 8 like getRuneOffset
+func getIssuePosition(script string, byteOffset int) (line, column int) {
+	line, column = 1, 1
+	for _, r := range script[:min(max(byteOffset, 0), len(script))] {
+		switch r {
+		case '\n':
+			line++
+			column = 1
+		case '\t':
+			column = ((column-1)/shellcheckTabStop+1)*shellcheckTabStop + 1
+		default:
+			column++
+		}
+	}
+	return line, column
+}
+
+// getRuneOffset converts a shellcheck position to a rune offset in the script,
+// shellcheck counts columns using tab stops of 8 characters
+func getRuneOffset(runes []rune, lineStarts []int, line, column int) (int, error) {
+	if line < 1 || line > len(lineStarts) || column < 1 {
+		return 0, ErrInvalidFixPosition
+	}
+	offset := lineStarts[line-1]
+	lineEnd := len(runes)
+	if line < len(lineStarts) {
+		lineEnd = lineStarts[line] - 1
+	}
+	virtualColumn := 1
+	for virtualColumn < column {
+		if offset >= lineEnd {
+			return 0, ErrInvalidFixPosition
+		}
+		if runes[offset] == '\t' {
+			virtualColumn = ((virtualColumn-1)/shellcheckTabStop+1)*shellcheckTabStop + 1
+		} else {
+			virtualColumn++
+		}
+		offset++
 	}
-	return models.LintStatusWarning
+	return offset, nil
 }
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MockCommandExecutor struct {
@@ -12,6 +17,8 @@ type MockCommandExecutor struct {
 	stdout string
 	stderr string
 	err    error
+	// Arguments received by the last execution
+	args []string
 }
 
 func WithLookPathExecutor(lookupExecutor LookupExecutorInterface) LintServiceOption {
@@ -20,12 +27,19 @@ func WithLookPathExecutor(lookupExecutor LookupExecutorInterface) LintServiceOpt
 	}
 }
 
-func (m *MockCommandExecutor) ExecuteCommandWithStdin(_ string, _ []string, _ string) (
+func (m *MockCommandExecutor) ExecuteCommandWithStdin(_ string, args []string, _ string) (
 	stdout string, stderr string, err error,
 ) {
+	m.args = args
 	return m.stdout, m.stderr, m.err
 }
 
+func (m *MockCommandExecutor) ExecuteCommandWithTimeout(_ time.Duration, cmd string, args []string, stdin string) (
+	stdout string, stderr string, err error,
+) {
+	return m.ExecuteCommandWithStdin(cmd, args, stdin)
+}
+
 type MockLookupExecutor struct {
 	path string
 	err  error
@@ -69,7 +83,7 @@ func TestNewLintService(t *testing.T) {
 			lookupExecutor:  nil,
 		} // Manually create the state
 		assert.Equal(t, false, service.IsLintingAvailable())
-		_, err := service.LintScript("echo 'hello'")
+		_, err := service.LintScript("echo 'hello'", models.ShellDialectBash)
 		assert.Error(t, err)
 		assert.Equal(t, ErrShellCheckNotFound, err)
 	})
@@ -151,7 +165,7 @@ func TestLintService_LintScript(t *testing.T) {
 					},
 				}
 
-				issues, err := service.LintScript(tc.scriptContent)
+				issues, err := service.LintScript(tc.scriptContent, models.ShellDialectBash)
 				assert.NoError(t, err, "LintScript() error = %v, wantErr false", err)
 				assert.Equal(t, tc.wantIssues, issues)
 			})
@@ -206,10 +220,334 @@ func TestLintService_LintScript(t *testing.T) {
 					},
 				}
 
-				issues, err := service.LintScript(tc.scriptContent)
+				issues, err := service.LintScript(tc.scriptContent, models.ShellDialectBash)
 				assert.Equal(t, tc.wantIssues, issues, "Expected no issues when error occurs")
 				assert.Equal(t, tc.wantErrMsg, err.Error(), "Expected error message to match")
 			})
 		}
 	})
 }
+
+func TestLintService_ShellDialect(t *testing.T) {
+	newService := func(executor *MockCommandExecutor) *LintService {
+		return &LintService{
+			shellCheckPath:  "/fake/path/to/shellcheck",
+			commandExecutor: executor,
+			lookupExecutor:  &MockLookupExecutor{path: "/fake/path/to/shellcheck", err: nil},
+		}
+	}
+
+	t.Run("Dialect passed to shellcheck", func(t *testing.T) {
+		executor := &MockCommandExecutor{stdout: "[]", stderr: "", err: nil, args: nil}
+		service := newService(executor)
+		_, err := service.LintScript("echo 'hello'", models.ShellDialectDash)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"--norc", "-s", "dash", "-f", "json", "-x", "--", "-"}, executor.args)
+	})
+
+	t.Run("Zsh not supported", func(t *testing.T) {
+		executor := &MockCommandExecutor{stdout: "[]", stderr: "", err: nil, args: nil}
+		service := newService(executor)
+		_, err := service.LintScript("print -l *(.)", models.ShellDialectZsh)
+		assert.ErrorIs(t, err, ErrShellDialectNotSupported)
+		assert.Nil(t, executor.args, "shellcheck should not be executed")
+	})
+
+	t.Run("Zsh command lint skipped", func(t *testing.T) {
+		executor := &MockCommandExecutor{stdout: "[]", stderr: "", err: nil, args: nil}
+		service := newService(executor)
+		cmd := models.NewCommand("#!/usr/bin/env zsh\nprint -l *(.)", 0, time.Now())
+		assert.Equal(t, models.ShellDialectZsh, cmd.Shell)
+		issues := service.LintCommand(cmd)
+		assert.Nil(t, issues)
+		assert.Equal(t, models.LintStatusNotAvailable, cmd.LintStatus)
+		assert.Nil(t, executor.args, "shellcheck should not be executed")
+	})
+}
+
+func TestApplyFixes(t *testing.T) {
+	quoteFix := func(line, start, end int) *ShellCheckFix {
+		return &ShellCheckFix{Replacements: []ShellCheckReplacement{
+			{InsertionPoint: "afterEnd", Replacement: `"`, Line: line, Column: start, EndLine: line, EndColumn: start},
+			{InsertionPoint: "beforeStart", Replacement: `"`, Line: line, Column: end, EndLine: line, EndColumn: end},
+		}}
+	}
+	sc2086 := `[{"file":"-","line":1,"endLine":1,"column":6,"endColumn":8,"level":"info","code":2086,` +
+		`"message":"Double quote to prevent globbing and word splitting.","fix":{"replacements":[` +
+		`{"line":1,"endLine":1,"column":6,"endColumn":6,"insertionPoint":"afterEnd","replacement":"\""},` +
+		`{"line":1,"endLine":1,"column":8,"endColumn":8,"insertionPoint":"beforeStart","replacement":"\""}]}}]`
+
+	t.Run("Fix parsed from shellcheck output", func(t *testing.T) {
+		issues, err := ParseLintIssues(sc2086)
+		assert.NoError(t, err)
+		assert.Len(t, GetFixableIssues(issues), 1)
+		fixed, err := ApplyFixes("echo $1", issues)
+		assert.NoError(t, err)
+		assert.Equal(t, `echo "$1"`, fixed)
+	})
+
+	t.Run("Multiple fixes on several lines", func(t *testing.T) {
+		issues := []ShellCheckIssue{
+			{Code: 2086, Fix: quoteFix(1, 6, 8)},
+			{Code: 2086, Fix: quoteFix(2, 4, 8)},
+			{Code: 2034, Fix: nil},
+		}
+		fixed, err := ApplyFixes("echo $1\nls $dir", issues)
+		assert.NoError(t, err)
+		assert.Equal(t, "echo \"$1\"\nls \"$dir\"", fixed)
+	})
+
+	t.Run("Columns use tab stops", func(t *testing.T) {
+		issues := []ShellCheckIssue{{Code: 2086, Fix: quoteFix(1, 9, 11)}}
+		fixed, err := ApplyFixes("\t$1", issues)
+		assert.NoError(t, err)
+		assert.Equal(t, "\t\"$1\"", fixed)
+	})
+
+	t.Run("Overlapping fix skipped", func(t *testing.T) {
+		replace := &ShellCheckFix{Replacements: []ShellCheckReplacement{
+			{InsertionPoint: "", Replacement: "${1}", Line: 1, Column: 6, EndLine: 1, EndColumn: 8},
+		}}
+		issues := []ShellCheckIssue{
+			{Code: 2086, Fix: quoteFix(1, 6, 8)},
+			{Code: 9999, Fix: replace},
+		}
+		fixed, err := ApplyFixes("echo $1", issues)
+		assert.NoError(t, err)
+		assert.Equal(t, `echo "$1"`, fixed)
+	})
+
+	t.Run("Invalid position", func(t *testing.T) {
+		issues := []ShellCheckIssue{{Code: 2086, Fix: quoteFix(3, 1, 2)}}
+		fixed, err := ApplyFixes("echo $1", issues)
+		assert.ErrorIs(t, err, ErrInvalidFixPosition)
+		assert.Equal(t, "echo $1", fixed)
+	})
+}
+
+type MockLintCache struct {
+	entries map[string][2]string
+}
+
+func (m *MockLintCache) GetLintCache(cacheKey string) (models.LintStatus, string, bool, error) {
+	entry, found := m.entries[cacheKey]
+	return models.LintStatus(entry[0]), entry[1], found, nil
+}
+
+func (m *MockLintCache) SaveLintCache(cacheKey string, lintStatus models.LintStatus, lintIssues string) error {
+	m.entries[cacheKey] = [2]string{string(lintStatus), lintIssues}
+	return nil
+}
+
+// MockCountingExecutor counts the shellcheck executions
+type MockCountingExecutor struct {
+	MockCommandExecutor
+	calls int
+}
+
+func (m *MockCountingExecutor) ExecuteCommandWithStdin(cmd string, args []string, stdin string) (
+	stdout string, stderr string, err error,
+) {
+	m.calls++
+	return m.MockCommandExecutor.ExecuteCommandWithStdin(cmd, args, stdin)
+}
+
+func TestLintService_Cache(t *testing.T) {
+	issues := `[{"file":"-","line":1,"endLine":1,"column":6,"endColumn":8,"level":"info","code":2086,` +
+		`"message":"Double quote to prevent globbing and word splitting.","fix":null}]`
+	newService := func(version string) (*LintService, *MockCountingExecutor) {
+		executor := &MockCountingExecutor{
+			MockCommandExecutor: MockCommandExecutor{stdout: issues, stderr: "", err: nil, args: nil},
+			calls:               0,
+		}
+		service := NewLintService(WithLintCache(&MockLintCache{entries: map[string][2]string{}}))
+		service.commandExecutor = executor
+		service.shellCheckPath = "/fake/path/to/shellcheck"
+		service.shellCheckVersion = version
+		return service, executor
+	}
+
+	t.Run("Unchanged script linted once", func(t *testing.T) {
+		service, executor := newService("0.10.0")
+		first := models.NewCommand("echo $1", 0, time.Now())
+		second := models.NewCommand("echo $1", 0, time.Now())
+		service.LintCommand(first)
+		service.LintCommand(second)
+		assert.Equal(t, 1, executor.calls)
+		assert.Equal(t, first.LintIssues, second.LintIssues)
+		assert.Equal(t, models.LintStatusOK, second.LintStatus)
+	})
+
+	t.Run("Dialect is part of the key", func(t *testing.T) {
+		service, executor := newService("0.10.0")
+		first := models.NewCommand("echo $1", 0, time.Now())
+		second := models.NewCommand("echo $1", 0, time.Now())
+		second.Shell = models.ShellDialectSh
+		service.LintCommand(first)
+		service.LintCommand(second)
+		assert.Equal(t, 2, executor.calls)
+	})
+
+	t.Run("Cache disabled without shellcheck version", func(t *testing.T) {
+		service, executor := newService("")
+		service.LintCommand(models.NewCommand("echo $1", 0, time.Now()))
+		service.LintCommand(models.NewCommand("echo $1", 0, time.Now()))
+		assert.Equal(t, 2, executor.calls)
+	})
+}
+
+func TestLintService_DetectShellCheckVersion(t *testing.T) {
+	service := &LintService{
+		shellCheckPath: "/fake/path/to/shellcheck",
+		commandExecutor: &MockCommandExecutor{
+			stdout: "ShellCheck - shell script analysis tool\nversion: 0.10.0\nlicense: GNU General Public License, version 3\n",
+			stderr: "",
+			err:    nil,
+			args:   nil,
+		},
+		lookupExecutor:    nil,
+		lintCache:         nil,
+		lintConfig:        nil,
+		baseRules:         models.GetDefaultLintRules(),
+		rcPaths:           []string{},
+		shellCheckVersion: "",
+	}
+	assert.Equal(t, "0.10.0", service.detectShellCheckVersion())
+}
+
+func TestLintService_Rules(t *testing.T) {
+	rcPath := filepath.Join(t.TempDir(), ".shellcheckrc")
+	rcContent := "# comment\ndisable=SC2086,SC2016 # inline comment\nenable=require-variable-braces\nshell=bash\n"
+	require.NoError(t, os.WriteFile(rcPath, []byte(rcContent), 0o600))
+
+	lintConfig := &LintConfig{
+		LintRules: models.LintRules{
+			Severity:     models.LintLevelInfo,
+			WarningLevel: models.LintLevelInfo,
+			ErrorLevel:   "",
+			Exclude:      []string{"2034"},
+			Enable:       []string{},
+			Sources:      []string{},
+		},
+		Tags: map[string]models.LintRules{
+			"legacy": {
+				Severity:     "",
+				WarningLevel: "",
+				ErrorLevel:   models.LintLevelWarning,
+				Exclude:      []string{"SC2006"},
+				Enable:       []string{},
+				Sources:      []string{},
+			},
+		},
+	}
+	executor := &MockCommandExecutor{stdout: "[]", stderr: "", err: nil, args: nil}
+	service := NewLintService(
+		WithLookPathExecutor(&MockLookupExecutor{path: "/fake/path/to/shellcheck", err: nil}),
+		WithLintConfig(lintConfig),
+		WithShellcheckRCPaths([]string{filepath.Join(t.TempDir(), "missing"), rcPath}),
+	)
+	service.commandExecutor = executor
+	require.NoError(t, service.Init())
+
+	t.Run("Rules merged from shellcheckrc and config", func(t *testing.T) {
+		rules := service.GetEffectiveRules(nil)
+		assert.Equal(t, []string{"SC2086", "SC2016", "SC2034"}, rules.Exclude)
+		assert.Equal(t, []string{"require-variable-braces"}, rules.Enable)
+		assert.Equal(t, models.LintLevelInfo, rules.Severity)
+		assert.Equal(t, models.LintLevelError, rules.ErrorLevel)
+		assert.Equal(t, []string{".shellcheckrc", "config"}, rules.Sources)
+	})
+
+	t.Run("Tag overrides", func(t *testing.T) {
+		rules := service.GetEffectiveRules([]string{"unknown", "legacy"})
+		assert.Equal(t, []string{"SC2086", "SC2016", "SC2034", "SC2006"}, rules.Exclude)
+		assert.Equal(t, models.LintLevelWarning, rules.ErrorLevel)
+		assert.Equal(t, []string{".shellcheckrc", "config", "tag:legacy"}, rules.Sources)
+	})
+
+	t.Run("Rules passed to shellcheck and stored", func(t *testing.T) {
+		cmd := models.NewCommand("echo $1", 0, time.Now())
+		cmd.Tags = []string{"legacy"}
+		service.LintCommand(cmd)
+		assert.Equal(t, []string{
+			"--norc", "-s", "bash", "-f", "json", "-x",
+			"-e", "SC2086,SC2016,SC2034,SC2006",
+			"-o", "require-variable-braces",
+			"-S", "info",
+			"--", "-",
+		}, executor.args)
+		rules, err := models.ParseLintRules(cmd.LintRules)
+		require.NoError(t, err)
+		assert.Equal(t, service.GetEffectiveRules(cmd.Tags), rules)
+	})
+}
+
+func TestLintService_Linters(t *testing.T) {
+	newService := func(lintConfig *LintConfig) *LintService {
+		service := NewLintService(
+			WithLookPathExecutor(&MockLookupExecutor{path: "", err: exec.ErrNotFound}),
+			WithShellcheckRCPaths([]string{}),
+			WithLintConfig(lintConfig),
+		)
+		require.NoError(t, service.Init())
+		return service
+	}
+
+	t.Run("Built-in linters without shellcheck", func(t *testing.T) {
+		service := newService(nil)
+		assert.True(t, service.IsLintingAvailable())
+		cmd := models.NewCommand("sudo rm -rf /tmp/x\nif true; then", 0, time.Now())
+		issues := service.LintCommand(cmd)
+		require.Len(t, issues, 2)
+		assert.Equal(t, LinterSyntax, issues[0].Linter)
+		assert.Equal(t, LinterRules, issues[1].Linter)
+		assert.Equal(t, models.LintStatusError, cmd.LintStatus)
+		assert.Contains(t, cmd.LintIssues, `"rule":"no-sudo-rm-rf"`)
+	})
+
+	t.Run("Placeholders linted as words", func(t *testing.T) {
+		lintConfig := &newDefaultConfig().Lint
+		lintConfig.Linters = []string{LinterSyntax, LinterFormat}
+		service := newService(lintConfig)
+		cmd := models.NewCommand("kubectl logs -n <ns>  {{ pod }}", 0, time.Now())
+		issues := service.LintCommand(cmd)
+		require.Len(t, issues, 1)
+		assert.Equal(t, LinterFormat, issues[0].Linter)
+		require.NotNil(t, issues[0].Fix)
+		assert.Equal(t, "kubectl logs -n <ns> {{ pod }}", issues[0].Fix.Replacements[0].Replacement)
+		assert.Equal(t, models.LintStatusOK, cmd.LintStatus)
+	})
+
+	t.Run("Zsh linted by custom rules only", func(t *testing.T) {
+		service := newService(nil)
+		cmd := models.NewCommand("#!/usr/bin/env zsh\nsudo rm -rf /tmp/x", 0, time.Now())
+		issues := service.LintCommand(cmd)
+		require.Len(t, issues, 1)
+		assert.Equal(t, models.LintStatusWarning, cmd.LintStatus)
+	})
+
+	t.Run("Excluded rules, severity and selected linters", func(t *testing.T) {
+		lintConfig := &newDefaultConfig().Lint
+		lintConfig.Exclude = []string{"no-sudo-rm-rf"}
+		service := newService(lintConfig)
+		cmd := models.NewCommand("sudo rm -rf /tmp/x;ls  -al", 0, time.Now())
+		assert.Empty(t, service.LintCommand(cmd), "the format linter is not run by default")
+
+		lintConfig.Linters = GetLinterNames()
+		service = newService(lintConfig)
+		issues := service.LintCommand(cmd)
+		require.Len(t, issues, 1)
+		assert.Equal(t, LinterFormat, issues[0].Linter)
+		assert.Equal(t, models.LintStatusOK, cmd.LintStatus)
+
+		lintConfig.Severity = models.LintLevelInfo
+		service = newService(lintConfig)
+		assert.Empty(t, service.LintCommand(cmd))
+
+		lintConfig.Linters = []string{LinterShellcheck}
+		service = newService(lintConfig)
+		assert.False(t, service.IsLintingAvailable())
+		service.LintCommand(cmd)
+		assert.Equal(t, models.LintStatusNotAvailable, cmd.LintStatus)
+	})
+}
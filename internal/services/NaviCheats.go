@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Prefixes of the lines of a navi cheat file, see https://github.com/denisidoro/navi
+const (
+	naviTagsPrefix        = "%"
+	naviDescriptionPrefix = "#"
+	naviVariablePrefix    = "$"
+	naviCommentPrefix     = ";"
+	naviExtendPrefix      = "@"
+	// naviOptionsSeparator separates the command of a variable from its fzf options
+	naviOptionsSeparator = "---"
+)
+
+// naviCheat is a command of a navi cheat file being parsed
+type naviCheat struct {
+	tags        string
+	title       string
+	description []string
+	script      []string
+}
+
+// ReadNaviCheats converts the cheats of a navi file to a catalog. The tags of
+// a cheat are the tags of its % line, the # line is the title and the ; lines
+// before the command are the description. The variables used by a command
+// become placeholder sources, a variable applies to the cheats having the
+// same tags as in navi.
+func ReadNaviCheats(reader io.Reader) (*models.Catalog, error) {
+	cheats := []*naviCheat{}
+	// variables by tags then by name
+	variables := map[string]map[string]string{}
+	tags := ""
+	var current *naviCheat
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			current = nil
+		case strings.HasPrefix(line, naviTagsPrefix):
+			tags = strings.TrimSpace(strings.TrimPrefix(line, naviTagsPrefix))
+			current = nil
+		case strings.HasPrefix(line, naviDescriptionPrefix):
+			current = &naviCheat{
+				tags:        tags,
+				title:       strings.TrimSpace(strings.TrimPrefix(line, naviDescriptionPrefix)),
+				description: []string{},
+				script:      []string{},
+			}
+			cheats = append(cheats, current)
+		case strings.HasPrefix(line, naviVariablePrefix):
+			name, command, ok := strings.Cut(strings.TrimPrefix(line, naviVariablePrefix), ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, &InvalidNaviLineError{Line: lineNumber, Content: line}
+			}
+			command, _, _ = strings.Cut(command, naviOptionsSeparator)
+			if variables[tags] == nil {
+				variables[tags] = map[string]string{}
+			}
+			variables[tags][strings.TrimSpace(name)] = strings.TrimSpace(command)
+			current = nil
+		case strings.HasPrefix(line, naviCommentPrefix):
+			if current != nil && len(current.script) == 0 {
+				current.description = append(current.description,
+					strings.TrimSpace(strings.TrimPrefix(line, naviCommentPrefix)))
+			}
+		case strings.HasPrefix(line, naviExtendPrefix):
+			// the cheats extending other cheats are not supported
+			current = nil
+		default:
+			if current == nil {
+				current = &naviCheat{tags: tags, title: "", description: []string{}, script: []string{}}
+				cheats = append(cheats, current)
+			}
+			current.script = append(current.script, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands:   make([]*models.CatalogCommand, 0, len(cheats)),
+	}
+	for _, cheat := range cheats {
+		if len(cheat.script) > 0 {
+			catalog.Commands = append(catalog.Commands, cheat.toCatalogCommand(variables[cheat.tags]))
+		}
+	}
+	return catalog, nil
+}
+
+func (c *naviCheat) toCatalogCommand(variables map[string]string) *models.CatalogCommand {
+	script := strings.Join(c.script, "\n")
+	title := c.title
+	description := strings.Join(c.description, "\n")
+	if utf8.RuneCountInString(title) > commandTitleMaxLength {
+		// the complete title is kept in the description
+		description = strings.TrimSpace(title + "\n\n" + description)
+		title = truncateTitle(title)
+	}
+	tags := []string{}
+	for _, tag := range strings.Split(c.tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sources := models.PlaceholderSources{}
+	for _, placeholder := range models.GetPlaceholders(script) {
+		if command, ok := variables[placeholder]; ok && command != "" {
+			sources[placeholder] = models.PlaceholderSource{Default: "", Choices: nil, Command: command}
+		}
+	}
+	return &models.CatalogCommand{
+		UUID:               "",
+		ContentHash:        "",
+		Title:              title,
+		Description:        description,
+		Script:             script,
+		Shell:              "",
+		Status:             "",
+		Folder:             "",
+		Tags:               tags,
+		PlaceholderSources: encodePlaceholderSources(sources),
+		ShortName:          "",
+		Elapsed:            0,
+		Created:            time.Time{},
+		Modified:           time.Time{},
+		Lint:               nil,
+	}
+}
+
+// encodePlaceholderSources returns the YAML declaration of the sources, empty if none
+func encodePlaceholderSources(sources models.PlaceholderSources) string {
+	if len(sources) == 0 {
+		return ""
+	}
+	var content strings.Builder
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(catalogIndent)
+	if err := encoder.Encode(sources); err != nil {
+		return ""
+	}
+	return content.String()
+}
+
+// WriteNaviCheats writes the commands of the catalog as navi cheats, each
+// command in its own % section. The blank lines and the comment lines of the
+// scripts are removed as navi would read them as the end of the command, and
+// the {{name}} placeholders are written <name>. The placeholder sources become
+// variables: the command of the source, or a printf of its choices, or an echo
+// of its default value.
+func WriteNaviCheats(writer io.Writer, catalog *models.Catalog) error {
+	var content strings.Builder
+	for i, cmd := range catalog.Commands {
+		if i > 0 {
+			content.WriteString("\n")
+		}
+		writeNaviCheat(&content, cmd)
+	}
+	_, err := io.WriteString(writer, content.String())
+	return err
+}
+
+func writeNaviCheat(content *strings.Builder, cmd *models.CatalogCommand) {
+	content.WriteString(strings.TrimRight(naviTagsPrefix+" "+strings.Join(cmd.Tags, ", "), " ") + "\n\n")
+	lines := []string{}
+	for _, line := range strings.Split(cmd.Script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, naviDescriptionPrefix) {
+			continue
+		}
+		if strings.ContainsAny(line[:1], naviTagsPrefix+naviVariablePrefix+naviCommentPrefix+naviExtendPrefix) {
+			// the leading space avoids the line to be read as a navi directive
+			line = " " + line
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = []string{":"}
+	}
+	placeholders := models.GetPlaceholders(cmd.Script)
+	naviPlaceholders := map[string]string{}
+	for _, placeholder := range placeholders {
+		naviPlaceholders[placeholder] = "<" + placeholder + ">"
+	}
+	script := models.FillPlaceholders(strings.Join(lines, "\n"), naviPlaceholders)
+
+	title, _, _ := strings.Cut(strings.TrimSpace(cmp.Or(cmd.Title, lines[0])), "\n")
+	fmt.Fprintf(content, "%s %s\n", naviDescriptionPrefix, title)
+	for _, line := range strings.Split(strings.TrimSpace(cmd.Description), "\n") {
+		if line != "" {
+			fmt.Fprintf(content, "%s %s\n", naviCommentPrefix, line)
+		}
+	}
+	content.WriteString(script + "\n")
+
+	sources, err := models.ParsePlaceholderSources(cmd.PlaceholderSources)
+	if err != nil {
+		return
+	}
+	variables := []string{}
+	for _, placeholder := range placeholders {
+		command := getNaviVariableCommand(sources[placeholder])
+		// a variable is declared on a single line
+		if command != "" && !strings.Contains(command, "\n") {
+			variables = append(variables, fmt.Sprintf("%s %s: %s", naviVariablePrefix, placeholder, command))
+		}
+	}
+	if len(variables) > 0 {
+		content.WriteString("\n" + strings.Join(variables, "\n") + "\n")
+	}
+}
+
+// getNaviVariableCommand returns the command listing the suggested values of
+// the placeholder, the default value first, empty if there is no suggestion
+func getNaviVariableCommand(source models.PlaceholderSource) string {
+	if source.Command != "" {
+		return source.Command
+	}
+	values := slices.Clone(source.Choices)
+	if source.Default != "" {
+		values = slices.DeleteFunc(values, func(value string) bool { return value == source.Default })
+		values = slices.Insert(values, 0, source.Default)
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, quoteShellValue(value))
+	}
+	if len(quoted) == 1 {
+		return "echo " + quoted[0]
+	}
+	return `printf '%s\n' ` + strings.Join(quoted, " ")
+}
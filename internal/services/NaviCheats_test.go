@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const naviTestCheats = `% git, code
+
+# Change branch
+; Checkout an existing local branch
+git checkout <branch>
+
+# Show the log
+git log --oneline \
+  -n 10
+
+$ branch: git branch --format='%(refname:short)' --- --header "Branch"
+
+% docker
+
+# Run an image
+docker run -it <image>
+`
+
+func TestReadNaviCheats(t *testing.T) {
+	catalog, err := ReadCatalog(strings.NewReader(naviTestCheats), models.CatalogFormatNavi)
+	require.NoError(t, err)
+	require.Len(t, catalog.Commands, 3)
+
+	checkout := catalog.Commands[0]
+	assert.Equal(t, "Change branch", checkout.Title)
+	assert.Equal(t, "Checkout an existing local branch", checkout.Description)
+	assert.Equal(t, "git checkout <branch>", checkout.Script)
+	assert.Equal(t, []string{"git", "code"}, checkout.Tags)
+	assert.Equal(t, "branch:\n  command: git branch --format='%(refname:short)'\n", checkout.PlaceholderSources,
+		"the variable declared after the cheat applies, without its fzf options")
+
+	assert.Equal(t, "git log --oneline \\\n  -n 10", catalog.Commands[1].Script)
+	assert.Empty(t, catalog.Commands[1].PlaceholderSources)
+
+	assert.Equal(t, []string{"docker"}, catalog.Commands[2].Tags)
+	assert.Empty(t, catalog.Commands[2].PlaceholderSources, "the variables are scoped by tags")
+
+	_, err = ReadCatalog(strings.NewReader("$ : ls\n"), models.CatalogFormatNavi)
+	var lineErr *InvalidNaviLineError
+	require.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 1, lineErr.Line)
+}
+
+func TestWriteNaviCheats(t *testing.T) {
+	deploy := newCatalogTestCommand(1, "Deploy", "#!/bin/bash\n# deploy the app\nmake deploy ENV={{env}}\n\n$HOME/bin/notify <channel>")
+	deploy.Description = "Deploy the application\n\nthen notify"
+	deploy.Tags = []string{"ops", "deploy"}
+	deploy.PlaceholderSources = "env:\n  choices: [dev, prod]\n  default: prod\nchannel:\n  command: list-channels\n"
+	var content bytes.Buffer
+	require.NoError(t, WriteCatalog(&content, &models.Catalog{
+		Version:  models.CatalogVersion,
+		Commands: []*models.CatalogCommand{models.NewCatalogCommand(deploy), models.NewCatalogCommand(newCatalogTestCommand(2, "", "ls -la"))},
+	}, models.CatalogFormatNavi))
+	assert.Equal(t, `% ops, deploy
+
+# Deploy
+; Deploy the application
+; then notify
+make deploy ENV=<env>
+ $HOME/bin/notify <channel>
+
+$ env: printf '%s\n' 'prod' 'dev'
+$ channel: list-channels
+
+%
+
+# ls -la
+ls -la
+`, content.String())
+
+	t.Run("Round trip", func(t *testing.T) {
+		catalog, err := ReadCatalog(&content, models.CatalogFormatNavi)
+		require.NoError(t, err)
+		require.Len(t, catalog.Commands, 2)
+		assert.Equal(t, "Deploy", catalog.Commands[0].Title)
+		assert.Equal(t, []string{"ops", "deploy"}, catalog.Commands[0].Tags)
+		assert.Equal(t, "ls -la", catalog.Commands[1].Script)
+		assert.Empty(t, catalog.Commands[1].Tags)
+	})
+}
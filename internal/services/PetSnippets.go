@@ -0,0 +1,193 @@
+package services
+
+import (
+	"cmp"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+// petParameterRegexp matches the <name=default> and <name=|_a_||_b_|>
+// parameters of a pet command, see https://github.com/knqyf263/pet
+//
+//nolint:gochecknoglobals // compiled once
+var petParameterRegexp = regexp.MustCompile(`<([A-Za-z_][\w-]*)=([^<>]*)>`)
+
+const (
+	// petOutputHeading starts the section of the description holding the
+	// example output of a pet snippet
+	petOutputHeading = "Output:"
+	// petChoicePrefix and petChoiceSuffix surround each choice of a parameter
+	petChoicePrefix = "|_"
+	petChoiceSuffix = "_|"
+)
+
+// petSnippets is the content of the snippet.toml file of pet
+type petSnippets struct {
+	Snippets []petSnippet `toml:"snippets"`
+}
+
+type petSnippet struct {
+	Description string   `toml:"description"`
+	Command     string   `toml:"command"`
+	Tag         []string `toml:"tag"`
+	Output      string   `toml:"output"`
+}
+
+// ReadPetSnippets converts the snippets of a pet snippet.toml file to a
+// catalog. The description of a snippet is the title of the command, the
+// complete description is kept if longer than a title, and the example output
+// is appended to the description. The <name=default> and <name=|_a_||_b_|>
+// parameters become <name> placeholders with a default value or choices.
+func ReadPetSnippets(reader io.Reader) (*models.Catalog, error) {
+	var snippets petSnippets
+	if _, err := toml.NewDecoder(reader).Decode(&snippets); err != nil {
+		return nil, err
+	}
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands:   make([]*models.CatalogCommand, 0, len(snippets.Snippets)),
+	}
+	for _, snippet := range snippets.Snippets {
+		if strings.TrimSpace(snippet.Command) != "" {
+			catalog.Commands = append(catalog.Commands, snippet.toCatalogCommand())
+		}
+	}
+	return catalog, nil
+}
+
+func (s *petSnippet) toCatalogCommand() *models.CatalogCommand {
+	sources := models.PlaceholderSources{}
+	script := petParameterRegexp.ReplaceAllStringFunc(s.Command, func(parameter string) string {
+		submatches := petParameterRegexp.FindStringSubmatch(parameter)
+		name, value := submatches[1], submatches[2]
+		if _, ok := sources[name]; !ok && value != "" {
+			sources[name] = parsePetParameterValue(value)
+		}
+		return "<" + name + ">"
+	})
+
+	title := strings.TrimSpace(s.Description)
+	description := ""
+	if utf8.RuneCountInString(title) > commandTitleMaxLength || strings.Contains(title, "\n") {
+		// the complete description is kept
+		description = title
+		title, _, _ = strings.Cut(title, "\n")
+		title = truncateTitle(title)
+	}
+	if output := strings.TrimRight(s.Output, "\n"); strings.TrimSpace(output) != "" {
+		description = strings.TrimSpace(description + "\n\n" + petOutputHeading + "\n" + output)
+	}
+	tags := []string{}
+	for _, tag := range s.Tag {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return &models.CatalogCommand{
+		UUID:               "",
+		ContentHash:        "",
+		Title:              title,
+		Description:        description,
+		Script:             strings.TrimRight(script, "\n"),
+		Shell:              "",
+		Status:             "",
+		Folder:             "",
+		Tags:               tags,
+		PlaceholderSources: encodePlaceholderSources(sources),
+		ShortName:          "",
+		Elapsed:            0,
+		Created:            time.Time{},
+		Modified:           time.Time{},
+		Lint:               nil,
+	}
+}
+
+// parsePetParameterValue returns the source of a parameter, its default value
+// or its choices, the first choice being the default value
+func parsePetParameterValue(value string) models.PlaceholderSource {
+	if !strings.HasPrefix(value, petChoicePrefix) || !strings.HasSuffix(value, petChoiceSuffix) {
+		return models.PlaceholderSource{Default: value, Choices: nil, Command: ""}
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, petChoicePrefix), petChoiceSuffix)
+	choices := strings.Split(value, petChoiceSuffix+petChoicePrefix)
+	return models.PlaceholderSource{Default: choices[0], Choices: choices, Command: ""}
+}
+
+// WritePetSnippets writes the commands of the catalog as a pet snippet.toml
+// file. The title of a command is the description of its snippet, followed by
+// its description, and the output section of the description is the output of
+// the snippet. The placeholders having a default value or choices are written
+// as pet parameters, the command sources are not supported by pet.
+func WritePetSnippets(writer io.Writer, catalog *models.Catalog) error {
+	snippets := petSnippets{Snippets: make([]petSnippet, 0, len(catalog.Commands))}
+	for _, cmd := range catalog.Commands {
+		snippets.Snippets = append(snippets.Snippets, newPetSnippet(cmd))
+	}
+	encoder := toml.NewEncoder(writer)
+	encoder.Indent = strings.Repeat(" ", catalogIndent)
+	return encoder.Encode(snippets)
+}
+
+func newPetSnippet(cmd *models.CatalogCommand) petSnippet {
+	description := strings.TrimSpace(cmd.Description)
+	output := ""
+	if description == petOutputHeading || strings.HasPrefix(description, petOutputHeading+"\n") {
+		description, output = "", strings.TrimPrefix(description, petOutputHeading+"\n")
+	} else if before, after, ok := strings.Cut(description, "\n\n"+petOutputHeading+"\n"); ok {
+		description, output = strings.TrimSpace(before), after
+	}
+	title := strings.TrimSpace(cmd.Title)
+	firstLine, _, _ := strings.Cut(description, "\n")
+	// the description of an imported snippet already starts with its title,
+	// truncated if the first line is longer than a title
+	if firstLine != title && (utf8.RuneCountInString(firstLine) <= commandTitleMaxLength || !strings.HasPrefix(firstLine, title)) {
+		description = strings.TrimSpace(title + "\n\n" + description)
+	}
+	heading, _, _ := strings.Cut(strings.TrimSpace(getStepBody(cmd.Script)), "\n")
+
+	sources, err := models.ParsePlaceholderSources(cmd.PlaceholderSources)
+	if err != nil {
+		sources = models.PlaceholderSources{}
+	}
+	parameters := map[string]string{}
+	for _, placeholder := range models.GetPlaceholders(cmd.Script) {
+		parameters[placeholder] = "<" + getPetParameter(placeholder, sources[placeholder]) + ">"
+	}
+	tags := cmd.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return petSnippet{
+		Description: cmp.Or(description, heading),
+		Command:     models.FillPlaceholders(cmd.Script, parameters),
+		Tag:         tags,
+		Output:      output,
+	}
+}
+
+// getPetParameter returns the pet parameter of the placeholder, name=default
+// or name=|_a_||_b_| with the default value as first choice
+func getPetParameter(name string, source models.PlaceholderSource) string {
+	values := slices.Clone(source.Choices)
+	if source.Default != "" {
+		values = slices.DeleteFunc(values, func(value string) bool { return value == source.Default })
+		values = slices.Insert(values, 0, source.Default)
+	}
+	if len(values) == 0 || slices.ContainsFunc(values, func(value string) bool {
+		return strings.ContainsAny(value, "<>")
+	}) {
+		return name
+	}
+	if len(values) == 1 && len(source.Choices) == 0 {
+		return name + "=" + values[0]
+	}
+	return name + "=" + petChoicePrefix + strings.Join(values, petChoiceSuffix+petChoicePrefix) + petChoiceSuffix
+}
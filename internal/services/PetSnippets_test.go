@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petTestSnippets = `[[snippets]]
+  description = "Change branch"
+  command = "git checkout <branch=main> && git pull <remote=|_origin_||_upstream_|>"
+  tag = ["git", "code"]
+  output = ""
+
+[[snippets]]
+  description = "List the pods of every namespace of the current cluster, including the completed jobs"
+  command = "kubectl get pods -A"
+  tag = []
+  output = """NAMESPACE NAME
+default   web-1
+"""
+`
+
+func TestReadPetSnippets(t *testing.T) {
+	catalog, err := ReadCatalog(strings.NewReader(petTestSnippets), models.CatalogFormatPet)
+	require.NoError(t, err)
+	require.Len(t, catalog.Commands, 2)
+
+	checkout := catalog.Commands[0]
+	assert.Equal(t, "Change branch", checkout.Title)
+	assert.Empty(t, checkout.Description)
+	assert.Equal(t, "git checkout <branch> && git pull <remote>", checkout.Script)
+	assert.Equal(t, []string{"git", "code"}, checkout.Tags)
+	sources, err := models.ParsePlaceholderSources(checkout.PlaceholderSources)
+	require.NoError(t, err)
+	assert.Equal(t, "main", sources["branch"].Default)
+	assert.Equal(t, []string{"origin", "upstream"}, sources["remote"].Choices)
+	assert.Equal(t, "origin", sources["remote"].Default)
+
+	pods := catalog.Commands[1]
+	assert.Equal(t, "List the pods of every namespace of the current cl", pods.Title)
+	assert.Equal(t, "List the pods of every namespace of the current cluster, including the completed jobs\n\n"+
+		"Output:\nNAMESPACE NAME\ndefault   web-1", pods.Description)
+	assert.Empty(t, pods.Tags)
+
+	_, err = ReadCatalog(strings.NewReader("[[snippets]\n"), models.CatalogFormatPet)
+	var parseErr *CatalogParseError
+	require.ErrorAs(t, err, &parseErr)
+}
+
+func TestWritePetSnippets(t *testing.T) {
+	deploy := newCatalogTestCommand(1, "Deploy", "make deploy ENV={{env}} CHANNEL=<channel> USER=<user>")
+	deploy.Description = "Deploy the application\n\nOutput:\ndone"
+	deploy.Tags = []string{"ops"}
+	deploy.PlaceholderSources = "env:\n  choices: [dev, prod]\n  default: prod\nchannel:\n  default: '#ops'\n" +
+		"user:\n  command: whoami\n"
+	var content bytes.Buffer
+	require.NoError(t, WriteCatalog(&content, &models.Catalog{
+		Version:  models.CatalogVersion,
+		Commands: []*models.CatalogCommand{models.NewCatalogCommand(deploy), models.NewCatalogCommand(newCatalogTestCommand(2, "", "ls -la"))},
+	}, models.CatalogFormatPet))
+	assert.Equal(t, `[[snippets]]
+  description = "Deploy\n\nDeploy the application"
+  command = "make deploy ENV=<env=|_prod_||_dev_|> CHANNEL=<channel=#ops> USER=<user>"
+  tag = ["ops"]
+  output = "done"
+
+[[snippets]]
+  description = "ls -la"
+  command = "ls -la"
+  tag = []
+  output = ""
+`, content.String())
+
+	t.Run("Round trip", func(t *testing.T) {
+		catalog, err := ReadCatalog(&content, models.CatalogFormatPet)
+		require.NoError(t, err)
+		require.Len(t, catalog.Commands, 2)
+		assert.Equal(t, "Deploy", catalog.Commands[0].Title)
+		assert.Equal(t, "Deploy\n\nDeploy the application\n\nOutput:\ndone", catalog.Commands[0].Description)
+		assert.Equal(t, "make deploy ENV=<env> CHANNEL=<channel> USER=<user>", catalog.Commands[0].Script)
+		assert.Equal(t, "ls -la", catalog.Commands[1].Title)
+	})
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+const (
+	// maxRecentPlaceholderValues is the number of values remembered per placeholder
+	maxRecentPlaceholderValues = 10
+	// placeholderCommandTimeout is the maximum duration of a suggestions command
+	placeholderCommandTimeout = 5 * time.Second
+)
+
+// PlaceholderService fills the placeholders of the commands and remembers
+// the values recently used for each placeholder
+type PlaceholderService struct {
+	store           PlaceholderStoreInterface
+	commandExecutor CommandExecutorInterface
+}
+
+// PlaceholderSuggestions are the values proposed for a placeholder
+type PlaceholderSuggestions struct {
+	// Default is the value proposed first
+	Default string
+	// Choices are the values to select from, the value is free text if empty
+	Choices []string
+	// Recent are the values recently used, the most recent first
+	Recent []string
+}
+
+func NewPlaceholderService(
+	store PlaceholderStoreInterface,
+	commandExecutor CommandExecutorInterface,
+) *PlaceholderService {
+	return &PlaceholderService{
+		store:           store,
+		commandExecutor: commandExecutor,
+	}
+}
+
+// GetPlaceholders returns the names of the placeholders of the commands,
+// in the order of their first occurrence
+func (*PlaceholderService) GetPlaceholders(commands []*models.Command) []string {
+	names := []string{}
+	for _, cmd := range commands {
+		for _, name := range cmd.GetPlaceholders() {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// GetRecentValues returns the values recently used for the placeholder,
+// the most recent first
+func (s *PlaceholderService) GetRecentValues(name string) []string {
+	values, err := s.store.GetPlaceholderValues(name, maxRecentPlaceholderValues)
+	if err != nil {
+		slog.Warn("Unable to retrieve placeholder recent values", "name", name, "error", err)
+		return []string{}
+	}
+	return values
+}
+
+// GetSuggestions returns the values proposed for the placeholder, using the
+// source declared by the first command declaring one and the recent values.
+// The most recent value is proposed first, then the declared default value.
+func (s *PlaceholderService) GetSuggestions(commands []*models.Command, name string) (PlaceholderSuggestions, error) {
+	suggestions := PlaceholderSuggestions{
+		Default: "",
+		Choices: []string{},
+		Recent:  s.GetRecentValues(name),
+	}
+	var err error
+	for _, cmd := range commands {
+		source, ok := cmd.GetPlaceholderSource(name)
+		if !ok {
+			continue
+		}
+		suggestions.Default = source.Default
+		suggestions.Choices = slices.Clone(source.Choices)
+		if source.Command != "" {
+			suggestions.Choices, err = s.runSuggestionsCommand(cmd.Shell, source.Command)
+		}
+		break
+	}
+	if len(suggestions.Choices) > 0 {
+		// recent values first, then the other choices
+		choices := slices.Clone(suggestions.Recent)
+		for _, choice := range suggestions.Choices {
+			if !slices.Contains(choices, choice) {
+				choices = append(choices, choice)
+			}
+		}
+		suggestions.Choices = choices
+	}
+	if len(suggestions.Recent) > 0 {
+		suggestions.Default = suggestions.Recent[0]
+	} else if suggestions.Default == "" && len(suggestions.Choices) > 0 {
+		suggestions.Default = suggestions.Choices[0]
+	}
+	return suggestions, err
+}
+
+// runSuggestionsCommand returns the non empty output lines of the command
+func (s *PlaceholderService) runSuggestionsCommand(shell models.ShellDialect, command string) ([]string, error) {
+	output, errOutput, err := s.commandExecutor.ExecuteCommandWithTimeout(
+		placeholderCommandTimeout, string(cmp.Or(shell, models.ShellDialectBash)), []string{"-c", command}, "",
+	)
+	if err != nil {
+		slog.Warn("Placeholder suggestions command failed", "command", command, "error", err, "stderr", errOutput)
+		return []string{}, &PlaceholderCommandError{Command: command, Err: err}
+	}
+	choices := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !slices.Contains(choices, line) {
+			choices = append(choices, line)
+		}
+	}
+	return choices, nil
+}
+
+// FillCommands replaces the placeholders of the commands scripts and
+// remembers the values used, the resulting scripts are returned
+func (s *PlaceholderService) FillCommands(commands []*models.Command, values map[string]string) []string {
+	scripts := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		scripts = append(scripts, models.FillPlaceholders(cmd.Script, values))
+	}
+	for name, value := range values {
+		if value == "" {
+			continue
+		}
+		// not blocking, the value is just not proposed next time
+		_ = s.store.SavePlaceholderValue(name, value, maxRecentPlaceholderValues)
+	}
+	return scripts
+}
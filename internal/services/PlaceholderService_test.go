@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPlaceholderStore struct {
+	values map[string][]string
+}
+
+func (m *MockPlaceholderStore) GetPlaceholderValues(name string, limit int) ([]string, error) {
+	values := m.values[name]
+	return values[:min(limit, len(values))], nil
+}
+
+func (m *MockPlaceholderStore) SavePlaceholderValue(name string, value string, keep int) error {
+	values := []string{value}
+	for _, v := range m.values[name] {
+		if v != value {
+			values = append(values, v)
+		}
+	}
+	m.values[name] = values[:min(keep, len(values))]
+	return nil
+}
+
+func TestPlaceholderService(t *testing.T) {
+	store := &MockPlaceholderStore{values: map[string][]string{"pod": {"coredns"}}}
+	service := NewPlaceholderService(store, &MockCommandExecutor{stdout: "", stderr: "", err: nil, args: nil})
+	commands := []*models.Command{
+		models.NewCommand("kubectl logs -n {{namespace}} <pod>", 0, time.Now()),
+		models.NewCommand("kubectl get pods -n {{namespace}}", 0, time.Now()),
+	}
+
+	assert.Equal(t, []string{"namespace", "pod"}, service.GetPlaceholders(commands))
+	assert.Equal(t, []string{"coredns"}, service.GetRecentValues("pod"))
+
+	scripts := service.FillCommands(commands, map[string]string{"namespace": "kube-system", "pod": "etcd"})
+	assert.Equal(t, []string{"kubectl logs -n kube-system etcd", "kubectl get pods -n kube-system"}, scripts)
+	assert.Equal(t, []string{"etcd", "coredns"}, service.GetRecentValues("pod"))
+	assert.Equal(t, []string{"kube-system"}, service.GetRecentValues("namespace"))
+}
+
+func TestPlaceholderService_GetSuggestions(t *testing.T) {
+	command := models.NewCommand("git checkout {{branch}} && deploy {{env}} <version>", 0, time.Now())
+	command.PlaceholderSources = `
+branch:
+  command: git branch --format=%(refname:short)
+env:
+  choices: [dev, staging, prod]
+  default: staging
+version:
+  default: latest
+`
+	executor := &MockCommandExecutor{stdout: "main\nfeature\n\nmain\n", stderr: "", err: nil, args: nil}
+	store := &MockPlaceholderStore{values: map[string][]string{"branch": {"old", "feature"}}}
+	service := NewPlaceholderService(store, executor)
+	commands := []*models.Command{command}
+
+	suggestions, err := service.GetSuggestions(commands, "branch")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-c", "git branch --format=%(refname:short)"}, executor.args)
+	assert.Equal(t, "old", suggestions.Default)
+	assert.Equal(t, []string{"old", "feature", "main"}, suggestions.Choices)
+
+	suggestions, err = service.GetSuggestions(commands, "env")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", suggestions.Default)
+	assert.Equal(t, []string{"dev", "staging", "prod"}, suggestions.Choices)
+
+	suggestions, err = service.GetSuggestions(commands, "version")
+	require.NoError(t, err)
+	assert.Equal(t, "latest", suggestions.Default)
+	assert.Empty(t, suggestions.Choices)
+
+	executor.err = errors.New("exit status 128")
+	suggestions, err = service.GetSuggestions(commands, "branch")
+	var commandErr *PlaceholderCommandError
+	require.ErrorAs(t, err, &commandErr)
+	assert.Equal(t, "old", suggestions.Default)
+	assert.Empty(t, suggestions.Choices)
+}
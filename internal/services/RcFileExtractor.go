@@ -0,0 +1,123 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// aliasBuiltin is the builtin defining the aliases
+const aliasBuiltin = "alias"
+
+// ExtractRcFileSnippets returns the aliases and the functions defined by a
+// shell rc file (eg: ~/.bashrc), or by the output of alias -p. The name of an
+// alias or of a function is the title and the short name of its command, the
+// comment lines just above its definition are its description. The script of
+// a function is its body. The definitions of the functions are not searched
+// for aliases.
+func ExtractRcFileSnippets(content string, dialect models.ShellDialect) ([]*Snippet, error) {
+	variant, ok := getLangVariant(dialect)
+	if !ok {
+		variant = syntax.LangBash
+	}
+	file, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(content), "")
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(content, "\n")
+	snippets := []*Snippet{}
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.FuncDecl:
+			if snippet := newFunctionSnippet(content, lines, node, dialect); snippet != nil {
+				snippets = append(snippets, snippet)
+			}
+			return false
+		case *syntax.CallExpr:
+			snippets = append(snippets, newAliasSnippets(content, lines, node, dialect)...)
+		}
+		return true
+	})
+	return snippets, nil
+}
+
+func newFunctionSnippet(
+	content string, lines []string, function *syntax.FuncDecl, dialect models.ShellDialect,
+) *Snippet {
+	script := getStatementsSource(content, function.Body)
+	if block, ok := function.Body.Cmd.(*syntax.Block); ok {
+		if len(block.Stmts) == 0 {
+			return nil
+		}
+		// the braces are not part of the script
+		script = getStatementsSource(content, block.Stmts...)
+	}
+	return newRcFileSnippet(function.Name.Value, script, lines, int(function.Pos().Line()), dialect)
+}
+
+// newAliasSnippets returns the aliases defined by an alias call, the other
+// calls and the arguments of the call printing aliases are ignored
+func newAliasSnippets(content string, lines []string, call *syntax.CallExpr, dialect models.ShellDialect) []*Snippet {
+	if len(call.Args) < 2 || call.Args[0].Lit() != aliasBuiltin {
+		return nil
+	}
+	snippets := []*Snippet{}
+	for _, arg := range call.Args[1:] {
+		definition := unquoteWord(content, arg)
+		name, script, ok := strings.Cut(definition, "=")
+		if !ok || strings.HasPrefix(name, "-") || strings.TrimSpace(script) == "" {
+			continue
+		}
+		snippets = append(snippets,
+			newRcFileSnippet(name, strings.TrimSpace(script), lines, int(call.Pos().Line()), dialect))
+	}
+	return snippets
+}
+
+func newRcFileSnippet(name string, script string, lines []string, line int, dialect models.ShellDialect) *Snippet {
+	return &Snippet{
+		Title:       name,
+		Description: strings.Join(getLeadingComments(lines, line), "\n"),
+		Script:      script,
+		Shell:       dialect,
+		ShortName:   name,
+		Line:        line,
+	}
+}
+
+// unquoteWord returns the value of a word without its quotes, the expansions
+// are kept as written
+func unquoteWord(content string, word *syntax.Word) string {
+	var value strings.Builder
+	for _, part := range word.Parts {
+		switch part := part.(type) {
+		case *syntax.Lit:
+			value.WriteString(unescapeShellValue(part.Value))
+		case *syntax.SglQuoted:
+			value.WriteString(part.Value)
+		case *syntax.DblQuoted:
+			quoted := content[part.Pos().Offset():part.End().Offset()]
+			quoted = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(quoted, "$"), `"`), `"`)
+			value.WriteString(strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(quoted))
+		default:
+			value.WriteString(content[part.Pos().Offset():part.End().Offset()])
+		}
+	}
+	return value.String()
+}
+
+// unescapeShellValue removes the backslashes of an unquoted value
+func unescapeShellValue(value string) string {
+	var unescaped strings.Builder
+	escaped := false
+	for _, char := range value {
+		if char == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		unescaped.WriteRune(char)
+	}
+	return unescaped.String()
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rcFileTestContent = `# ~/.bashrc
+export PATH="$HOME/bin:$PATH"
+
+# list the files
+alias ll='ls -la' gs="git status \"--short\"" la=ls\ -A
+alias
+alias -p
+
+if command -v kubectl >/dev/null; then
+  alias k=kubectl
+fi
+
+# Create a directory
+# and enter it
+mkcd() {
+  mkdir -p "$1"
+  cd "$1" || return
+}
+
+function greet {
+  alias hi='echo hi'
+  echo "Hello ${1:-world}"
+}
+
+empty() { :; }
+`
+
+func TestExtractRcFileSnippets(t *testing.T) {
+	snippets, err := ExtractRcFileSnippets(rcFileTestContent, models.ShellDialectBash)
+	require.NoError(t, err)
+
+	scripts := map[string]string{}
+	for _, snippet := range snippets {
+		assert.Equal(t, snippet.Title, snippet.ShortName)
+		assert.Equal(t, models.ShellDialectBash, snippet.Shell)
+		scripts[snippet.ShortName] = snippet.Script
+	}
+	assert.Equal(t, map[string]string{
+		"ll":    "ls -la",
+		"gs":    `git status "--short"`,
+		"la":    "ls -A",
+		"k":     "kubectl",
+		"mkcd":  "mkdir -p \"$1\"\n  cd \"$1\" || return",
+		"greet": "alias hi='echo hi'\n  echo \"Hello ${1:-world}\"",
+		"empty": ":",
+	}, scripts, "the aliases defined in functions are ignored")
+
+	assert.Equal(t, "list the files", snippets[0].Description)
+	assert.Equal(t, "Create a directory\nand enter it", snippets[4].Description)
+	assert.Empty(t, snippets[5].Description)
+
+	_, err = ExtractRcFileSnippets("mkcd() {\n", models.ShellDialectBash)
+	assert.Error(t, err)
+}
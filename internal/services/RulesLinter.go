@@ -0,0 +1,121 @@
+package services
+
+import (
+	"cmp"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+// compiledLintRule is a custom lint rule with its compiled pattern
+type compiledLintRule struct {
+	pattern *regexp.Regexp
+	rule    CustomLintRule
+}
+
+// rulesLinter checks the team conventions defined by the custom rules
+type rulesLinter struct {
+	signature string
+	rules     []compiledLintRule
+}
+
+// getBuiltinLintRules returns the rules checked when nothing is configured
+func getBuiltinLintRules() []CustomLintRule {
+	return []CustomLintRule{
+		{
+			Name:     "no-sudo-rm-rf",
+			Pattern:  `\bsudo\s+rm\s+(-\w*[rR]\w*f|-\w*f\w*[rR])\b`,
+			Message:  "sudo rm -rf can remove system files, check the path or avoid sudo",
+			Level:    models.LintLevelWarning,
+			Disabled: false,
+		},
+		{
+			Name:     "no-curl-pipe-shell",
+			Pattern:  `\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|k|da)?sh\b`,
+			Message:  "Piping a download to a shell runs unverified code, download and review the script first",
+			Level:    models.LintLevelWarning,
+			Disabled: false,
+		},
+	}
+}
+
+// newRulesLinter compiles the built-in rules overridden by the custom rules
+func newRulesLinter(customRules []CustomLintRule) *rulesLinter {
+	rules := getBuiltinLintRules()
+	for _, customRule := range customRules {
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == customRule.Name {
+				rules[i] = customRule
+				replaced = true
+			}
+		}
+		if !replaced {
+			rules = append(rules, customRule)
+		}
+	}
+
+	linter := &rulesLinter{
+		signature: "",
+		rules:     []compiledLintRule{},
+	}
+	signatures := []string{}
+	for _, rule := range rules {
+		if rule.Disabled {
+			continue
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			slog.Error("Invalid custom lint rule ignored", "rule", rule.Name, "error", err)
+			continue
+		}
+		linter.rules = append(linter.rules, compiledLintRule{pattern: pattern, rule: rule})
+		signatures = append(signatures, rule.Name+"="+string(rule.Level)+":"+rule.Pattern)
+	}
+	linter.signature = strings.Join(signatures, "\x1f")
+	return linter
+}
+
+func (*rulesLinter) GetName() string {
+	return LinterRules
+}
+
+func (*rulesLinter) IsAvailable() bool {
+	return true
+}
+
+func (*rulesLinter) IsDialectSupported(_ models.ShellDialect) bool {
+	return true
+}
+
+// GetSignature returns the rules, the signature is never empty
+// so that the cache can be used without rules
+func (l *rulesLinter) GetSignature(_ models.ShellDialect, _ models.LintRules) string {
+	return "[" + l.signature + "]"
+}
+
+func (l *rulesLinter) Lint(script string, _ models.ShellDialect, _ models.LintRules) ([]ShellCheckIssue, error) {
+	issues := []ShellCheckIssue{}
+	for _, compiled := range l.rules {
+		for _, match := range compiled.pattern.FindAllStringIndex(script, -1) {
+			line, column := getIssuePosition(script, match[0])
+			endLine, endColumn := getIssuePosition(script, match[1])
+			issues = append(issues, ShellCheckIssue{
+				Fix:       nil,
+				File:      "-",
+				Level:     string(cmp.Or(compiled.rule.Level, models.LintLevelWarning)),
+				Message:   cmp.Or(compiled.rule.Message, "Forbidden by rule "+compiled.rule.Name),
+				Linter:    LinterRules,
+				Rule:      compiled.rule.Name,
+				Line:      line,
+				EndLine:   endLine,
+				Column:    column,
+				EndColumn: endColumn,
+				Code:      0,
+			})
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesLinter(t *testing.T) {
+	rules := models.GetDefaultLintRules()
+
+	t.Run("Built-in rules", func(t *testing.T) {
+		linter := newRulesLinter(nil)
+		issues, err := linter.Lint("cd /tmp\nsudo rm -rf \"$dir\"", models.ShellDialectZsh, rules)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "no-sudo-rm-rf", issues[0].Rule)
+		assert.Equal(t, "warning", issues[0].Level)
+		assert.Equal(t, 2, issues[0].Line)
+		assert.Equal(t, 1, issues[0].Column)
+		assert.Equal(t, 12, issues[0].EndColumn)
+
+		issues, err = linter.Lint("curl -sSL https://example.com/install | sudo bash", models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "no-curl-pipe-shell", issues[0].Rule)
+	})
+
+	t.Run("Custom rules", func(t *testing.T) {
+		linter := newRulesLinter([]CustomLintRule{
+			{Name: "no-sudo-rm-rf", Pattern: "", Message: "", Level: "", Disabled: true},
+			{Name: "no-latest", Pattern: `:latest\b`, Message: "Pin the image version", Level: models.LintLevelError, Disabled: false},
+		})
+		issues, err := linter.Lint("sudo rm -rf /tmp/x\ndocker run alpine:latest", models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "no-latest", issues[0].Rule)
+		assert.Equal(t, "error", issues[0].Level)
+		assert.Equal(t, "Pin the image version", issues[0].Message)
+	})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+const (
+	// composedFunctionNameMaxLength is the maximum length of the function
+	// names derived from the step labels
+	composedFunctionNameMaxLength = 40
+	// composedStepVariable is the variable of the composed script containing
+	// the label of the running step, used to report the failing step
+	composedStepVariable = "current_step"
+	// composedStatusVariable is the variable of the composed script containing
+	// the exit status of the script, status is a read-only variable of zsh
+	composedStatusVariable = "composed_status"
+)
+
+// nonIdentifierRegexp matches the characters that cannot be used in a function name
+//
+//nolint:gochecknoglobals // compiled once
+var nonIdentifierRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ComposeScript generates the script running the scripts of the steps in
+// order, with a comment above each step and the requested error handling
+func ComposeScript(composition *models.Composition) string {
+	shell := composition.GetShell()
+	var script strings.Builder
+	fmt.Fprintf(&script, "#!/usr/bin/env %s\n", shell)
+	if composition.Title != "" {
+		writeComment(&script, composition.Title)
+	}
+	writeErrorHandling(&script, composition.ErrorHandling, shell)
+	for i, step := range composition.Steps {
+		label := getStepLabel(step)
+		script.WriteString("\n")
+		writeComment(&script, fmt.Sprintf("Step %d: %s", i+1, cmp.Or(strings.TrimSpace(step.Comment), label)))
+		body := getStepBody(step.Command.Script)
+		if composition.ErrorHandling == models.ErrorHandlingReport {
+			fmt.Fprintf(&script, "%s=%s\n", composedStepVariable, quoteShellValue(fmt.Sprintf("%d (%s)", i+1, label)))
+		}
+		if !step.AsFunction {
+			script.WriteString(body + "\n")
+			continue
+		}
+		functionName := getStepFunctionName(i, label)
+		if canIndentScript(body, shell) {
+			body = "\t" + strings.ReplaceAll(body, "\n", "\n\t")
+		}
+		fmt.Fprintf(&script, "%s() {\n%s\n}\n%s\n", functionName, body, functionName)
+	}
+	return script.String()
+}
+
+func writeComment(script *strings.Builder, comment string) {
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		script.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+}
+
+// writeErrorHandling writes the options stopping the script at the first
+// failing command, pipefail is not available in posix shells
+func writeErrorHandling(script *strings.Builder, errorHandling models.ErrorHandling, shell models.ShellDialect) {
+	if errorHandling == models.ErrorHandlingNone {
+		return
+	}
+	if shell == models.ShellDialectSh || shell == models.ShellDialectDash {
+		script.WriteString("set -o errexit\n")
+	} else {
+		script.WriteString("set -o errexit -o pipefail\n")
+	}
+	if errorHandling == models.ErrorHandlingReport {
+		fmt.Fprintf(script, "%s=''\n", composedStepVariable)
+		fmt.Fprintf(script,
+			`trap '%s=$?; if [ "${%s}" -ne 0 ]; then `+
+				`echo "Step ${%s} failed with status ${%s}" >&2; fi' EXIT`+"\n",
+			composedStatusVariable, composedStatusVariable, composedStepVariable, composedStatusVariable,
+		)
+	}
+}
+
+// getStepLabel returns the first line of the comment of the step, or the
+// title of its command
+func getStepLabel(step *models.CompositionStep) string {
+	label := strings.TrimSpace(step.Comment)
+	if label == "" {
+		label = strings.TrimSpace(step.Command.Title)
+	}
+	if label == "" {
+		return fmt.Sprintf("command #%d", step.Command.ID)
+	}
+	firstLine, _, _ := strings.Cut(label, "\n")
+	return firstLine
+}
+
+// getStepBody removes the shebang and the trailing new lines of the script
+func getStepBody(script string) string {
+	if strings.HasPrefix(script, "#!") {
+		_, script, _ = strings.Cut(script, "\n")
+	}
+	script = strings.TrimRight(script, "\n")
+	if strings.TrimSpace(script) == "" {
+		return ":"
+	}
+	return script
+}
+
+// getStepFunctionName derives the name of the function of the step from its label
+func getStepFunctionName(index int, label string) string {
+	name := fmt.Sprintf("step_%d", index+1)
+	slug := strings.Trim(nonIdentifierRegexp.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	if slug == "" {
+		return name
+	}
+	name += "_" + slug
+	if len(name) > composedFunctionNameMaxLength {
+		name = strings.TrimRight(name[:composedFunctionNameMaxLength], "_")
+	}
+	return name
+}
+
+// quoteShellValue quotes the value with single quotes
+func quoteShellValue(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// canIndentScript returns false if indenting the lines of the script would
+// change its behavior, ie: if the script has here-documents or multi-line
+// strings, or if the script cannot be parsed
+func canIndentScript(script string, shell models.ShellDialect) bool {
+	variant, ok := getLangVariant(shell)
+	if !ok {
+		variant = syntax.LangBash
+	}
+	file, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(script), "")
+	if err != nil {
+		return false
+	}
+	indentable := true
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.Redirect:
+			if node.Hdoc != nil {
+				indentable = false
+			}
+		case *syntax.Lit, *syntax.SglQuoted, *syntax.DblQuoted:
+			if node.Pos().Line() != node.End().Line() {
+				indentable = false
+			}
+		}
+		return indentable
+	})
+	return indentable
+}
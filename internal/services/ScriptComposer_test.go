@@ -0,0 +1,86 @@
+package services
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeScript(t *testing.T) {
+	newCommand := func(id int, title string, script string, shell models.ShellDialect) *models.Command {
+		cmd := models.NewCommand(script, 0, time.Now())
+		cmd.ID = resource.ID(id)
+		cmd.Title = title
+		cmd.Shell = shell
+		return cmd
+	}
+
+	t.Run("Steps with comments and quotes", func(t *testing.T) {
+		composition := models.NewComposition([]*models.Command{
+			newCommand(1, "Greet", "#!/bin/bash\necho 'hello'\n", models.ShellDialectBash),
+			newCommand(2, "", "ls | wc -l", models.ShellDialectBash),
+		})
+		composition.Title = "Deploy"
+		composition.Steps[1].Comment = "Count files\nin the folder"
+		assert.Equal(t, "#!/usr/bin/env bash\n"+
+			"# Deploy\n"+
+			"set -o errexit -o pipefail\n"+
+			"\n"+
+			"# Step 1: Greet\n"+
+			"echo 'hello'\n"+
+			"\n"+
+			"# Step 2: Count files\n"+
+			"# in the folder\n"+
+			"ls | wc -l\n",
+			ComposeScript(composition),
+		)
+	})
+
+	t.Run("Steps wrapped into functions", func(t *testing.T) {
+		composition := models.NewComposition([]*models.Command{
+			newCommand(1, "Build image!", "docker build .\ndocker push", models.ShellDialectSh),
+			newCommand(2, "", "cat <<EOF\n  text\nEOF", models.ShellDialectSh),
+		})
+		composition.ErrorHandling = models.ErrorHandlingNone
+		for _, step := range composition.Steps {
+			step.AsFunction = true
+		}
+		assert.Equal(t, "#!/usr/bin/env sh\n"+
+			"\n"+
+			"# Step 1: Build image!\n"+
+			"step_1_build_image() {\n\tdocker build .\n\tdocker push\n}\n"+
+			"step_1_build_image\n"+
+			"\n"+
+			"# Step 2: command #2\n"+
+			"step_2_command_2() {\ncat <<EOF\n  text\nEOF\n}\n"+
+			"step_2_command_2\n",
+			ComposeScript(composition),
+			"here-documents are not indented",
+		)
+	})
+
+	t.Run("Failing step is reported", func(t *testing.T) {
+		for _, shell := range models.GetShellDialects() {
+			t.Run(string(shell), func(t *testing.T) {
+				if _, err := exec.LookPath(string(shell)); err != nil {
+					t.Skipf("%s not available", shell)
+				}
+				composition := models.NewComposition([]*models.Command{
+					newCommand(1, "It's ok", "true", shell),
+					newCommand(2, "Fails", "false", shell),
+					newCommand(3, "Never run", "echo never", shell),
+				})
+				composition.ErrorHandling = models.ErrorHandlingReport
+				composition.Steps[1].AsFunction = true
+				output, err := exec.Command(string(shell), "-c", ComposeScript(composition)).CombinedOutput()
+				require.Error(t, err)
+				assert.Equal(t, "Step 2 (Fails) failed with status 1\n", string(output))
+			})
+		}
+	})
+}
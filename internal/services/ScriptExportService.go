@@ -0,0 +1,131 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+const (
+	// scriptFileMode makes the exported scripts executable
+	scriptFileMode fs.FileMode = 0o755
+	// binDirMode is used to create the bin directory if it does not exist
+	binDirMode fs.FileMode = 0o755
+	// scriptFileNameMaxLength is the maximum length of the file names derived from the titles
+	scriptFileNameMaxLength = 50
+)
+
+// ScriptExportService writes the commands as executable script files
+type ScriptExportService struct {
+	config *ExportConfig
+}
+
+func NewScriptExportService(config *ExportConfig) *ScriptExportService {
+	return &ScriptExportService{config: config}
+}
+
+// GenerateScriptFile returns the content of the script file of the command:
+// a shebang matching the command shell, a header with the title, the
+// description and the tags of the command, then the script
+func (*ScriptExportService) GenerateScriptFile(cmd *models.Command) string {
+	var content strings.Builder
+	script := strings.TrimRight(cmd.Script, "\n")
+	if strings.HasPrefix(script, "#!") {
+		// the shebang of the script is kept, the header is added below it
+		var shebang string
+		shebang, script, _ = strings.Cut(script, "\n")
+		content.WriteString(shebang + "\n")
+	} else {
+		fmt.Fprintf(&content, "#!/usr/bin/env %s\n", cmp.Or(cmd.Shell, models.DefaultShellDialect))
+	}
+	if cmd.Title != "" {
+		content.WriteString("# Title: " + cmd.Title + "\n")
+	}
+	if description := strings.TrimSpace(cmd.Description); description != "" {
+		content.WriteString("# Description:\n")
+		for _, line := range strings.Split(description, "\n") {
+			content.WriteString(strings.TrimRight("#   "+line, " ") + "\n")
+		}
+	}
+	if len(cmd.Tags) > 0 {
+		content.WriteString("# Tags: " + strings.Join(cmd.Tags, ", ") + "\n")
+	}
+	fmt.Fprintf(&content, "# Exported from shell-command-bookmarker command #%d\n", cmd.ID)
+	content.WriteString(script + "\n")
+	return content.String()
+}
+
+// GetScriptFileName derives the file name of the script from the command title
+func (*ScriptExportService) GetScriptFileName(cmd *models.Command) string {
+	name := strings.Trim(nonIdentifierRegexp.ReplaceAllString(strings.ToLower(cmd.Title), "-"), "-")
+	if len(name) > scriptFileNameMaxLength {
+		name = strings.TrimRight(name[:scriptFileNameMaxLength], "-")
+	}
+	if name == "" {
+		return fmt.Sprintf("command-%d", cmd.ID)
+	}
+	return name
+}
+
+// IsBinDirConfigured returns true if the commands can be exported to the bin directory
+func (s *ScriptExportService) IsBinDirConfigured() bool {
+	return s.config.BinDir != ""
+}
+
+// GetBinPath returns the path of the script of the command in the bin directory
+func (s *ScriptExportService) GetBinPath(cmd *models.Command) (string, error) {
+	if !s.IsBinDirConfigured() {
+		return "", &BinDirNotConfiguredError{}
+	}
+	binDir, err := expandHomeDir(s.config.BinDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(binDir, s.GetScriptFileName(cmd)), nil
+}
+
+// expandHomeDir replaces the leading ~ of the path by the home directory
+func expandHomeDir(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// Exists returns true if a file already exists at the given path
+func (*ScriptExportService) Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ExportScript writes the script file of the command at the given path and
+// makes it executable, an existing file is only replaced if overwrite is true
+func (s *ScriptExportService) ExportScript(cmd *models.Command, path string, overwrite bool) error {
+	if !overwrite && s.Exists(path) {
+		return &ScriptFileExistsError{Path: path}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), binDirMode); err != nil {
+		return &ExportScriptError{Path: path, Err: err}
+	}
+	err := os.WriteFile(path, []byte(s.GenerateScriptFile(cmd)), scriptFileMode) //nolint:gosec // the script has to be executable
+	if err != nil {
+		return &ExportScriptError{Path: path, Err: err}
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, scriptFileMode); err != nil {
+		return &ExportScriptError{Path: path, Err: err}
+	}
+	slog.Info("Command exported as script", "id", cmd.ID, "path", path)
+	return nil
+}
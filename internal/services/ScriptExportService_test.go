@@ -0,0 +1,84 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptExportService(t *testing.T) {
+	newCommand := func(script string) *models.Command {
+		cmd := models.NewCommand(script, 0, time.Now())
+		cmd.ID = resource.ID(12)
+		cmd.Title = "Backup the database!"
+		cmd.Description = "Dump the database\n\nthen compress it"
+		cmd.Tags = []string{"db", "backup"}
+		return cmd
+	}
+
+	t.Run("Header with the command shell", func(t *testing.T) {
+		service := NewScriptExportService(&ExportConfig{BinDir: ""})
+		cmd := newCommand("pg_dump db | gzip > db.gz\n")
+		cmd.Shell = models.ShellDialectZsh
+		assert.Equal(t, "#!/usr/bin/env zsh\n"+
+			"# Title: Backup the database!\n"+
+			"# Description:\n"+
+			"#   Dump the database\n"+
+			"#\n"+
+			"#   then compress it\n"+
+			"# Tags: db, backup\n"+
+			"# Exported from shell-command-bookmarker command #12\n"+
+			"pg_dump db | gzip > db.gz\n",
+			service.GenerateScriptFile(cmd),
+		)
+	})
+
+	t.Run("Shebang of the script is kept", func(t *testing.T) {
+		service := NewScriptExportService(&ExportConfig{BinDir: ""})
+		cmd := newCommand("#!/bin/sh\necho ok")
+		cmd.Title = ""
+		cmd.Description = ""
+		cmd.Tags = nil
+		assert.Equal(t, "#!/bin/sh\n"+
+			"# Exported from shell-command-bookmarker command #12\n"+
+			"echo ok\n",
+			service.GenerateScriptFile(cmd),
+		)
+		assert.Equal(t, "command-12", service.GetScriptFileName(cmd))
+	})
+
+	t.Run("Export to the bin directory", func(t *testing.T) {
+		binDir := filepath.Join(t.TempDir(), "bin")
+		service := NewScriptExportService(&ExportConfig{BinDir: binDir})
+		cmd := newCommand("echo backup")
+		path, err := service.GetBinPath(cmd)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(binDir, "backup-the-database"), path)
+
+		require.NoError(t, service.ExportScript(cmd, path, false))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, scriptFileMode, info.Mode().Perm())
+
+		cmd.Script = "echo changed"
+		var existsErr *ScriptFileExistsError
+		require.ErrorAs(t, service.ExportScript(cmd, path, false), &existsErr, "no overwrite without confirmation")
+		require.NoError(t, service.ExportScript(cmd, path, true))
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "echo changed\n")
+	})
+
+	t.Run("Bin directory not configured", func(t *testing.T) {
+		service := NewScriptExportService(&ExportConfig{BinDir: ""})
+		_, err := service.GetBinPath(newCommand("echo"))
+		var notConfiguredErr *BinDirNotConfiguredError
+		require.ErrorAs(t, err, &notConfiguredErr)
+	})
+}
@@ -1,7 +1,20 @@
 package services
 
 import (
+	"bytes"
 	_ "embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"mvdan.cc/sh/v3/syntax"
 )
 
 //go:embed templates/bash-integration.sh
@@ -10,6 +23,26 @@ var bashIntegrationTemplate string
 //go:embed templates/zsh-integration.zsh
 var zshIntegrationTemplate string
 
+const (
+	// libraryFileMode is the mode of the generated library files, sourced but not executed
+	libraryFileMode fs.FileMode = 0o644
+	// libraryDirMode is used to create the directory of a library file if it does not exist
+	libraryDirMode fs.FileMode = 0o755
+	// libraryHelpOption prints the help of a library function
+	libraryHelpOption = "--help"
+	// libraryUsageStatus is returned by a library function called with missing arguments
+	libraryUsageStatus = 2
+	// libraryArgumentPrefix prefixes the variables holding the arguments of a library function
+	libraryArgumentPrefix = "arg_"
+	// libraryArgumentMarkerFormat is the word replacing a placeholder while the script is parsed
+	libraryArgumentMarkerFormat = "__scb_argument_%d__"
+)
+
+// libraryArgumentMarkerRegexp matches the words of libraryArgumentMarkerFormat
+//
+//nolint:gochecknoglobals // compiled once
+var libraryArgumentMarkerRegexp = regexp.MustCompile(`__scb_argument_\d+__`)
+
 // ShellIntegrationService provides shell integration scripts
 type ShellIntegrationService struct{}
 
@@ -27,3 +60,303 @@ func (s *ShellIntegrationService) GenerateBashIntegration() string {
 func (s *ShellIntegrationService) GenerateZshIntegration() string {
 	return zshIntegrationTemplate
 }
+
+// GenerateLibrary returns a script, to be sourced from the rc file of the
+// given shell, defining an alias or a function for each editable command
+// having a short name. The placeholders of a command are the positional
+// arguments of its function, in the order of their first occurrence, the
+// placeholders having a default value are optional. The arguments are
+// assigned to variables whose expansions replace the placeholders. When several commands
+// have the same short name, the command with the lowest id is used.
+func (s *ShellIntegrationService) GenerateLibrary(commands []*models.Command, shell models.ShellDialect) string {
+	commands = slices.Clone(commands)
+	slices.SortFunc(commands, func(a, b *models.Command) int {
+		return int(a.ID - b.ID)
+	})
+	var library strings.Builder
+	fmt.Fprintf(&library, "#!/usr/bin/env %s\n", shell)
+	writeComment(&library, "Generated by shell-command-bookmarker, do not edit:\n"+
+		"this file is written again when the bookmarks change")
+	definedBy := map[string]resource.ID{}
+	for _, cmd := range commands {
+		if cmd.ShortName == "" || !cmd.IsEditable() {
+			continue
+		}
+		library.WriteString("\n")
+		if id, ok := definedBy[cmd.ShortName]; ok {
+			writeComment(&library, fmt.Sprintf(
+				"%s of command #%d skipped: already defined by command #%d", cmd.ShortName, cmd.ID, id,
+			))
+			continue
+		}
+		if !cmd.Shell.IsCompatibleWith(shell) {
+			writeComment(&library, fmt.Sprintf(
+				"%s of command #%d skipped: written for %s", cmd.ShortName, cmd.ID, cmd.Shell,
+			))
+			continue
+		}
+		definition, skipReason := getLibraryDefinition(cmd, shell)
+		if skipReason != "" {
+			writeComment(&library, fmt.Sprintf("%s of command #%d skipped: %s", cmd.ShortName, cmd.ID, skipReason))
+			continue
+		}
+		definedBy[cmd.ShortName] = cmd.ID
+		library.WriteString(definition)
+	}
+	return library.String()
+}
+
+// getLibraryDefinition returns an alias if the command is a single line
+// without placeholders nor description, a function otherwise, or the reason
+// why the command cannot be defined. The body of the function runs in a
+// subshell so that an exit or a shell option of the script does not affect
+// the interactive shell.
+func getLibraryDefinition(cmd *models.Command, shell models.ShellDialect) (definition string, skipReason string) {
+	var library strings.Builder
+	name := cmd.ShortName
+	if cmd.Title == "" {
+		writeComment(&library, fmt.Sprintf("%s: command #%d", name, cmd.ID))
+	} else {
+		writeComment(&library, fmt.Sprintf("%s: %s (command #%d)", name, cmd.Title, cmd.ID))
+	}
+	// an alias would be expanded in the function definition
+	fmt.Fprintf(&library, "unalias %s 2>/dev/null\n", name)
+	body := getStepBody(cmd.Script)
+	placeholders := cmd.GetPlaceholders()
+	if len(placeholders) == 0 && strings.TrimSpace(cmd.Description) == "" && !strings.Contains(body, "\n") {
+		fmt.Fprintf(&library, "alias %s=%s\n", name, quoteShellValue(body))
+		return library.String(), ""
+	}
+
+	usage := "Usage: " + name
+	requiredArgs := 0
+	variables := map[string]string{}
+	assignments := make([]string, 0, len(placeholders))
+	for i, placeholder := range placeholders {
+		variable := getLibraryArgumentVariable(placeholder, i, variables)
+		variables[placeholder] = variable
+		source, _ := cmd.GetPlaceholderSource(placeholder)
+		if source.Default == "" {
+			usage += fmt.Sprintf(" <%s>", placeholder)
+			assignments = append(assignments, fmt.Sprintf("%s=${%d}", variable, i+1))
+			requiredArgs = i + 1
+			continue
+		}
+		usage += fmt.Sprintf(" [%s=%s]", placeholder, source.Default)
+		assignments = append(assignments,
+			fmt.Sprintf("%s=${%d:-\"%s\"}", variable, i+1, escapeDoubleQuotedValue(source.Default)))
+	}
+	body, skipReason = fillLibraryArguments(body, variables, shell)
+	if skipReason != "" {
+		return "", skipReason
+	}
+	help := strings.TrimSpace(cmd.Title + "\n\n" + strings.TrimSpace(cmd.Description))
+	help = strings.TrimSpace(help + "\n\n" + usage)
+
+	fmt.Fprintf(&library, "%s() (\n", name)
+	writeLibraryArgumentChecks(&library, help, usage, requiredArgs)
+	for _, assignment := range assignments {
+		library.WriteString("\t" + assignment + "\n")
+	}
+	if canIndentScript(body, shell) {
+		body = "\t" + strings.ReplaceAll(body, "\n", "\n\t")
+	}
+	library.WriteString(body + "\n)\n")
+	return library.String(), ""
+}
+
+// writeLibraryArgumentChecks writes the start of a library function printing
+// its help, or its usage if arguments are missing
+func writeLibraryArgumentChecks(library *strings.Builder, help string, usage string, requiredArgs int) {
+	fmt.Fprintf(library, "\tif [ \"${1-}\" = %s ]; then\n", libraryHelpOption)
+	fmt.Fprintf(library, "\t\tprintf '%%s\\n' %s\n", quoteShellValue(help))
+	library.WriteString("\t\texit 0\n\tfi\n")
+	if requiredArgs > 0 {
+		fmt.Fprintf(library, "\tif [ \"$#\" -lt %d ]; then\n", requiredArgs)
+		fmt.Fprintf(library, "\t\tprintf '%%s\\n' %s >&2\n", quoteShellValue(usage))
+		fmt.Fprintf(library, "\t\texit %d\n\tfi\n", libraryUsageStatus)
+	}
+}
+
+// getLibraryArgumentVariable returns the name of the variable holding the
+// argument of the placeholder, prefixed to keep the variables of the script,
+// eg: a placeholder named PATH
+func getLibraryArgumentVariable(placeholder string, index int, variables map[string]string) string {
+	variable := libraryArgumentPrefix + strings.ReplaceAll(placeholder, "-", "_")
+	for _, used := range variables {
+		if used == variable {
+			// eg: my-name and my_name
+			return fmt.Sprintf("%s_%d", variable, index+1)
+		}
+	}
+	return variable
+}
+
+// argumentQuoting is the quoting of the text around a placeholder
+type argumentQuoting int
+
+const (
+	// argumentUnquoted: the expansion is double quoted to avoid word splitting
+	argumentUnquoted argumentQuoting = iota
+	// argumentBare: between double quotes, in an arithmetic expression or in a
+	// here-document, the expansion is not quoted
+	argumentBare
+	// argumentSingleQuoted: the single quotes are closed around the expansion
+	argumentSingleQuoted
+	// argumentDollarSingleQuoted: the $'...' quotes are closed around the expansion
+	argumentDollarSingleQuoted
+	// argumentNotExpandable: a here-document with a quoted delimiter
+	argumentNotExpandable
+)
+
+// quotingRange is the quoting of a part of the script
+type quotingRange struct {
+	start, end uint
+	quoting    argumentQuoting
+}
+
+// fillLibraryArguments replaces the placeholders of the script by the
+// expansion of the variables holding the arguments, quoted according to the
+// syntax tree of the script, or returns the reason why they cannot be replaced
+func fillLibraryArguments(script string, variables map[string]string, shell models.ShellDialect) (string, string) {
+	if len(variables) == 0 {
+		return script, ""
+	}
+	// the placeholders are replaced by words before parsing, eg: <pod> is a redirection
+	markers := map[string]string{}
+	markerVariables := map[string]string{}
+	for placeholder, variable := range variables {
+		marker := fmt.Sprintf(libraryArgumentMarkerFormat, len(markers))
+		markers[placeholder] = marker
+		markerVariables[marker] = variable
+	}
+	script = models.FillPlaceholders(script, markers)
+	variant, ok := getLangVariant(shell)
+	if !ok {
+		variant = syntax.LangBash
+	}
+	file, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(script), "")
+	if err != nil {
+		slog.Debug("Script with placeholders cannot be parsed", "error", err)
+		return "", "the script cannot be parsed to replace its placeholders"
+	}
+	ranges := getQuotingRanges(file)
+
+	var filled strings.Builder
+	last := 0
+	for _, location := range libraryArgumentMarkerRegexp.FindAllStringIndex(script, -1) {
+		quoting := getQuotingAt(ranges, uint(location[0]))
+		if quoting == argumentNotExpandable {
+			return "", "placeholder in a here-document with a quoted delimiter"
+		}
+		filled.WriteString(script[last:location[0]])
+		filled.WriteString(quoteLibraryArgument(markerVariables[script[location[0]:location[1]]], quoting))
+		last = location[1]
+	}
+	filled.WriteString(script[last:])
+	return filled.String(), ""
+}
+
+// quoteLibraryArgument returns the expansion of the variable, quoted to be
+// inserted in a text having the given quoting
+func quoteLibraryArgument(variable string, quoting argumentQuoting) string {
+	expansion := "${" + variable + "}"
+	switch quoting {
+	case argumentUnquoted:
+		return `"` + expansion + `"`
+	case argumentSingleQuoted:
+		return `'"` + expansion + `"'`
+	case argumentDollarSingleQuoted:
+		return `'"` + expansion + `"$'`
+	default:
+		return expansion
+	}
+}
+
+// getQuotingRanges returns the parts of the script changing the quoting of
+// their text, the nested parts come after their parent
+func getQuotingRanges(file *syntax.File) []quotingRange {
+	ranges := []quotingRange{}
+	add := func(node syntax.Node, quoting argumentQuoting) {
+		if node != nil && node.Pos().IsValid() && node.End().IsValid() {
+			ranges = append(ranges, quotingRange{start: node.Pos().Offset(), end: node.End().Offset(), quoting: quoting})
+		}
+	}
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.SglQuoted:
+			if node.Dollar {
+				add(node, argumentDollarSingleQuoted)
+			} else {
+				add(node, argumentSingleQuoted)
+			}
+		case *syntax.DblQuoted, *syntax.ArithmExp, *syntax.ArithmCmd:
+			add(node, argumentBare)
+		case *syntax.CmdSubst, *syntax.ProcSubst:
+			add(node, argumentUnquoted)
+		case *syntax.Redirect:
+			if node.Hdoc == nil {
+				break
+			}
+			if isQuotedHeredocDelimiter(node.Word) {
+				add(node.Hdoc, argumentNotExpandable)
+			} else {
+				add(node.Hdoc, argumentBare)
+			}
+		}
+		return true
+	})
+	return ranges
+}
+
+// isQuotedHeredocDelimiter returns true if the delimiter of the here-document
+// is quoted, its body is not expanded then
+func isQuotedHeredocDelimiter(delimiter *syntax.Word) bool {
+	for _, part := range delimiter.Parts {
+		if lit, ok := part.(*syntax.Lit); !ok || strings.Contains(lit.Value, `\`) {
+			return true
+		}
+	}
+	return false
+}
+
+// getQuotingAt returns the quoting of the innermost range containing the offset
+func getQuotingAt(ranges []quotingRange, offset uint) argumentQuoting {
+	quoting := argumentUnquoted
+	var start uint
+	for _, r := range ranges {
+		if r.start <= offset && offset < r.end && r.start >= start {
+			quoting = r.quoting
+			start = r.start
+		}
+	}
+	return quoting
+}
+
+// escapeDoubleQuotedValue escapes the characters having a special meaning
+// between double quotes
+func escapeDoubleQuotedValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(value)
+}
+
+// WriteLibrary writes the library at the given path, a leading ~ is
+// replaced by the home directory. The file is only written if its content
+// changed, changed is false otherwise.
+func (s *ShellIntegrationService) WriteLibrary(path string, library string) (changed bool, err error) {
+	path, err = expandHomeDir(path)
+	if err != nil {
+		return false, &WriteLibraryError{Path: path, Err: err}
+	}
+	if content, err := os.ReadFile(path); err == nil && bytes.Equal(content, []byte(library)) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), libraryDirMode); err != nil {
+		return false, &WriteLibraryError{Path: path, Err: err}
+	}
+	//nolint:gosec // the library is sourced by the shell of the user
+	if err := os.WriteFile(path, []byte(library), libraryFileMode); err != nil {
+		return false, &WriteLibraryError{Path: path, Err: err}
+	}
+	slog.Info("Shell library written", "path", path)
+	return true, nil
+}
@@ -1,9 +1,16 @@
 package services
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShellIntegrationService_GenerateBashIntegration(t *testing.T) {
@@ -31,3 +38,130 @@ func TestShellIntegrationService_GenerateZshIntegration(t *testing.T) {
 	assert.Contains(t, script, "zle -N shell_command_bookmarker_paste")
 	assert.Contains(t, script, "bindkey '^g' shell_command_bookmarker_paste")
 }
+
+func newLibraryTestCommand(id int, shortName string, script string) *models.Command {
+	cmd := models.NewCommand(script, 0, time.Now())
+	cmd.ID = resource.ID(id)
+	cmd.Status = models.CommandStatusSaved
+	cmd.ShortName = shortName
+	return cmd
+}
+
+func TestShellIntegrationService_GenerateLibrary(t *testing.T) {
+	service := NewShellIntegrationService()
+
+	t.Run("Alias", func(t *testing.T) {
+		library := service.GenerateLibrary([]*models.Command{
+			newLibraryTestCommand(1, "gs", "git status --short"),
+			newLibraryTestCommand(2, "", "git log"),
+		}, models.ShellDialectBash)
+		assert.Contains(t, library, "#!/usr/bin/env bash\n")
+		assert.Contains(t, library, "unalias gs 2>/dev/null\nalias gs='git status --short'\n")
+		assert.NotContains(t, library, "git log", "commands without short name are ignored")
+	})
+
+	t.Run("Function with positional arguments and help", func(t *testing.T) {
+		cmd := newLibraryTestCommand(3, "klogs", "kubectl logs -n {{namespace}} <pod> --tail {{lines}}")
+		cmd.Title = "Pod logs"
+		cmd.Description = "Show the logs of a pod"
+		cmd.PlaceholderSources = "lines:\n  default: \"100\"\n"
+		library := service.GenerateLibrary([]*models.Command{cmd}, models.ShellDialectBash)
+		assert.Contains(t, library, "# klogs: Pod logs (command #3)\n")
+		assert.Contains(t, library, "klogs() (\n")
+		assert.Contains(t, library,
+			"\t\tprintf '%s\\n' 'Pod logs\n\nShow the logs of a pod\n\nUsage: klogs <namespace> <pod> [lines=100]'\n")
+		assert.Contains(t, library, "\tif [ \"$#\" -lt 2 ]; then\n")
+		assert.Contains(t, library, "\targ_namespace=${1}\n\targ_pod=${2}\n\targ_lines=${3:-\"100\"}\n"+
+			"\tkubectl logs -n \"${arg_namespace}\" \"${arg_pod}\" --tail \"${arg_lines}\"\n)\n")
+	})
+
+	t.Run("Multi-line script", func(t *testing.T) {
+		library := service.GenerateLibrary([]*models.Command{
+			newLibraryTestCommand(4, "backup", "#!/bin/bash\npg_dump db\ncat <<EOF\n  done\nEOF\n"),
+		}, models.ShellDialectBash)
+		assert.Contains(t, library, "backup() (\n")
+		assert.NotContains(t, library, "-lt", "no argument is required")
+		assert.Contains(t, library, "pg_dump db\ncat <<EOF\n  done\nEOF\n)\n", "here-documents are not indented")
+	})
+
+	t.Run("Function does not affect the interactive shell", func(t *testing.T) {
+		for _, shell := range []models.ShellDialect{models.ShellDialectBash, models.ShellDialectSh} {
+			t.Run(string(shell), func(t *testing.T) {
+				if _, err := exec.LookPath(string(shell)); err != nil {
+					t.Skipf("%s not available", shell)
+				}
+				cmd := newLibraryTestCommand(5, "check", "set -o errexit\ncd /\nfalse\necho never")
+				cmd.Shell = shell
+				library := service.GenerateLibrary([]*models.Command{cmd}, shell)
+				output, err := exec.Command(string(shell), "-c",
+					library+"\ndir=$(pwd); check; echo \"status $?\"; [ \"$(pwd)\" = \"${dir}\" ] && echo same dir\n"+
+						"check --help >/dev/null; echo \"help $?\"",
+				).CombinedOutput()
+				require.NoError(t, err, string(output))
+				assert.Equal(t, "status 1\nsame dir\nhelp 0\n", string(output))
+			})
+		}
+	})
+
+	t.Run("Placeholders quoted according to the script", func(t *testing.T) {
+		script := `printf '%s|' {{a}} "b={{b}}" 'c={{c}}' $(( {{n}} + 1 )) "$(echo {{a}})"` + "\n" +
+			"cat <<EOF\nd={{b}}\nEOF"
+		for _, shell := range []models.ShellDialect{models.ShellDialectBash, models.ShellDialectSh} {
+			t.Run(string(shell), func(t *testing.T) {
+				if _, err := exec.LookPath(string(shell)); err != nil {
+					t.Skipf("%s not available", shell)
+				}
+				cmd := newLibraryTestCommand(6, "quoting", script)
+				cmd.Shell = shell
+				library := service.GenerateLibrary([]*models.Command{cmd}, shell)
+				output, err := exec.Command(string(shell), "-c",
+					library+"\nquoting 'x  y' '$HOME' \"it's\" 2",
+				).CombinedOutput()
+				require.NoError(t, err, string(output))
+				assert.Equal(t, "x  y|b=$HOME|c=it's|3|x  y|d=$HOME\n", string(output))
+			})
+		}
+	})
+
+	t.Run("Placeholder in a quoted here-document", func(t *testing.T) {
+		library := service.GenerateLibrary([]*models.Command{
+			newLibraryTestCommand(7, "sql", "psql <<'EOF'\nselect '{{table}}';\nEOF"),
+		}, models.ShellDialectBash)
+		assert.Contains(t, library,
+			"# sql of command #7 skipped: placeholder in a here-document with a quoted delimiter\n")
+		assert.NotContains(t, library, "sql()")
+	})
+
+	t.Run("Skipped commands", func(t *testing.T) {
+		deleted := newLibraryTestCommand(1, "old", "echo old")
+		deleted.Status = models.CommandStatusDeleted
+		zsh := newLibraryTestCommand(2, "zonly", "#!/bin/zsh\nprint -l $path")
+		library := service.GenerateLibrary([]*models.Command{
+			newLibraryTestCommand(4, "hello", "echo duplicate"),
+			deleted,
+			zsh,
+			newLibraryTestCommand(3, "hello", "echo hello"),
+		}, models.ShellDialectBash)
+		assert.NotContains(t, library, "old")
+		assert.Contains(t, library, "# zonly of command #2 skipped: written for zsh\n")
+		assert.Contains(t, library, "alias hello='echo hello'\n")
+		assert.Contains(t, library, "# hello of command #4 skipped: already defined by command #3\n")
+		assert.NotContains(t, library, "echo duplicate")
+	})
+}
+
+func TestShellIntegrationService_WriteLibrary(t *testing.T) {
+	service := NewShellIntegrationService()
+	path := filepath.Join(t.TempDir(), "shell", "library.bash")
+
+	changed, err := service.WriteLibrary(path, "alias gs='git status'\n")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = service.WriteLibrary(path, "alias gs='git status'\n")
+	require.NoError(t, err)
+	assert.False(t, changed, "unchanged library is not written again")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "alias gs='git status'\n", string(content))
+}
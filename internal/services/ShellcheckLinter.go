@@ -0,0 +1,45 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+// shellcheckLinter runs the shellcheck executable found by the LintService
+type shellcheckLinter struct {
+	service *LintService
+}
+
+func newShellcheckLinter(service *LintService) *shellcheckLinter {
+	return &shellcheckLinter{
+		service: service,
+	}
+}
+
+func (*shellcheckLinter) GetName() string {
+	return LinterShellcheck
+}
+
+func (l *shellcheckLinter) IsAvailable() bool {
+	return l.service.shellCheckPath != ""
+}
+
+func (*shellcheckLinter) IsDialectSupported(dialect models.ShellDialect) bool {
+	return isShellcheckDialect(dialect)
+}
+
+// GetSignature returns an empty signature if the shellcheck version is
+// unknown, as a new version could report different issues
+func (l *shellcheckLinter) GetSignature(dialect models.ShellDialect, rules models.LintRules) string {
+	if l.service.shellCheckVersion == "" {
+		return ""
+	}
+	return l.service.shellCheckVersion + " " + strings.Join(l.service.getLintArgs(dialect, rules), " ")
+}
+
+func (l *shellcheckLinter) Lint(
+	script string, dialect models.ShellDialect, rules models.LintRules,
+) ([]ShellCheckIssue, error) {
+	return l.service.lintScriptWithRules(script, dialect, rules)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// markdownHeadingRegexp matches an ATX heading, the optional closing # are not captured
+//
+//nolint:gochecknoglobals // compiled once
+var markdownHeadingRegexp = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+
+// markdownFenceRegexp matches the opening line of a fenced code block,
+// capturing its indentation, its fence and its language
+//
+//nolint:gochecknoglobals // compiled once
+var markdownFenceRegexp = regexp.MustCompile("^(\\s*)(`{3,}|~{3,})\\s*([^`\\s]*)")
+
+// markdownShellLanguage is the language of the code blocks written for any shell
+const markdownShellLanguage = "shell"
+
+// Snippet is a command extracted from a markdown file or a shell script
+type Snippet struct {
+	Title       string
+	Description string
+	Script      string
+	Shell       models.ShellDialect
+	// ShortName is the name of the alias or of the function defining the
+	// command, empty for the commands of runbooks and scripts
+	ShortName string
+	// Line is the line of the file where the script starts
+	Line int
+}
+
+type markdownBlockKind int
+
+const (
+	markdownBlockHeading markdownBlockKind = iota
+	markdownBlockParagraph
+	markdownBlockCode
+)
+
+type markdownBlock struct {
+	kind markdownBlockKind
+	// text is the heading, the lines of the paragraph or the content of the code block
+	text     string
+	language string
+	line     int
+}
+
+// IsSnippetFile returns true if the file is a markdown file or a shell script
+// according to its extension
+func IsSnippetFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".sh", ".bash", ".zsh", ".ksh":
+		return true
+	default:
+		return false
+	}
+}
+
+// ExtractFileSnippets extracts the commands of a markdown file, or of a shell
+// script if the file has another extension
+func ExtractFileSnippets(path string) ([]*Snippet, error) {
+	content, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	switch extension := strings.ToLower(filepath.Ext(path)); extension {
+	case ".md", ".markdown":
+		return ExtractMarkdownSnippets(string(content)), nil
+	default:
+		dialect, ok := models.ParseShellDialect(strings.TrimPrefix(extension, "."))
+		if !ok {
+			dialect = models.DefaultShellDialect
+		}
+		return ExtractScriptSnippets(string(content), dialect)
+	}
+}
+
+// ExtractMarkdownSnippets returns the fenced code blocks of the markdown
+// content written for a supported shell (bash, sh, shell, zsh...). The title
+// of a command is the nearest heading above its code block, its description
+// the paragraph just before the code block, or just after if there is none.
+func ExtractMarkdownSnippets(content string) []*Snippet {
+	blocks := parseMarkdownBlocks(content)
+	snippets := []*Snippet{}
+	title := ""
+	for i, block := range blocks {
+		if block.kind == markdownBlockHeading {
+			title = block.text
+			continue
+		}
+		if block.kind != markdownBlockCode || strings.TrimSpace(block.text) == "" {
+			continue
+		}
+		dialect, ok := models.ParseShellDialect(block.language)
+		if block.language == markdownShellLanguage {
+			dialect, ok = models.DefaultShellDialect, true
+		}
+		if !ok {
+			continue
+		}
+		description := ""
+		if i > 0 && blocks[i-1].kind == markdownBlockParagraph {
+			description = blocks[i-1].text
+		} else if i+1 < len(blocks) && blocks[i+1].kind == markdownBlockParagraph {
+			description = blocks[i+1].text
+		}
+		snippets = append(snippets, &Snippet{
+			Title:       title,
+			Description: description,
+			Script:      block.text,
+			Shell:       models.DetectShellDialectFromShebang(block.text, dialect),
+			ShortName:   "",
+			Line:        block.line,
+		})
+	}
+	return snippets
+}
+
+// parseMarkdownBlocks splits the markdown content in headings, paragraphs and
+// fenced code blocks, the other constructs are read as paragraphs
+func parseMarkdownBlocks(content string) []*markdownBlock {
+	lines := strings.Split(content, "\n")
+	blocks := []*markdownBlock{}
+	var paragraph *markdownBlock
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if fence := markdownFenceRegexp.FindStringSubmatch(line); fence != nil {
+			indentation, delimiter := fence[1], fence[2]
+			code := []string{}
+			for i++; i < len(lines); i++ {
+				codeLine := strings.TrimRight(lines[i], "\r")
+				trimmed := strings.TrimSpace(codeLine)
+				if strings.HasPrefix(trimmed, delimiter) && strings.Trim(trimmed, delimiter[:1]) == "" {
+					break
+				}
+				// the code blocks of lists are indented
+				code = append(code, strings.TrimPrefix(codeLine, indentation))
+			}
+			blocks = append(blocks, &markdownBlock{
+				kind:     markdownBlockCode,
+				text:     strings.Trim(strings.Join(code, "\n"), "\n"),
+				language: strings.ToLower(fence[3]),
+				line:     i - len(code) + 1,
+			})
+			paragraph = nil
+			continue
+		}
+		switch heading := markdownHeadingRegexp.FindStringSubmatch(line); {
+		case strings.TrimSpace(line) == "":
+			paragraph = nil
+		case heading != nil:
+			blocks = append(blocks, &markdownBlock{kind: markdownBlockHeading, text: heading[1], language: "", line: i + 1})
+			paragraph = nil
+		case paragraph == nil:
+			paragraph = &markdownBlock{kind: markdownBlockParagraph, text: strings.TrimSpace(line), language: "", line: i + 1}
+			blocks = append(blocks, paragraph)
+		default:
+			paragraph.text += "\n" + strings.TrimSpace(line)
+		}
+	}
+	return blocks
+}
+
+// ExtractScriptSnippets returns the top-level statements of the shell script,
+// the function definitions excepted. The comment lines just above a statement
+// are its title, the first line, and its description. The shell of the
+// commands is deduced from the shebang of the script, fallback otherwise.
+func ExtractScriptSnippets(content string, fallback models.ShellDialect) ([]*Snippet, error) {
+	dialect := models.DetectShellDialectFromShebang(content, fallback)
+	variant, ok := getLangVariant(dialect)
+	if !ok {
+		variant = syntax.LangBash
+	}
+	file, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(content), "")
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(content, "\n")
+	snippets := make([]*Snippet, 0, len(file.Stmts))
+	for _, stmt := range file.Stmts {
+		if _, ok := stmt.Cmd.(*syntax.FuncDecl); ok {
+			// running a function definition only defines the function
+			continue
+		}
+		line := int(stmt.Pos().Line())
+		comments := getLeadingComments(lines, line)
+		title := ""
+		if len(comments) > 0 {
+			title = comments[0]
+		}
+		snippets = append(snippets, &Snippet{
+			Title:       title,
+			Description: strings.Join(comments[min(1, len(comments)):], "\n"),
+			Script:      getStatementsSource(content, stmt),
+			Shell:       dialect,
+			ShortName:   "",
+			Line:        line,
+		})
+	}
+	return snippets, nil
+}
+
+// getLeadingComments returns the text of the comment lines just above the
+// given line, the shebang and the separator lines (eg: # -----) excepted
+func getLeadingComments(lines []string, line int) []string {
+	comments := []string{}
+	for i := line - 2; i >= 0; i-- {
+		comment := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(comment, "#") || strings.HasPrefix(comment, "#!") {
+			break
+		}
+		comment = strings.TrimSpace(strings.TrimLeft(comment, "#"))
+		if strings.Trim(comment, "-=*#_ ") != "" {
+			comments = append([]string{comment}, comments...)
+		}
+	}
+	return comments
+}
+
+// getStatementsSource returns the source of the statements, without the
+// semicolon ending the last statement
+func getStatementsSource(content string, stmts ...*syntax.Stmt) string {
+	last := stmts[len(stmts)-1]
+	end := last.End()
+	if last.Semicolon.IsValid() {
+		end = last.Semicolon
+	}
+	return strings.TrimSpace(content[stmts[0].Pos().Offset():end.Offset()])
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snippetTestRunbook = "# Runbook\n\n" +
+	"## Restart the web server ##\n\n" +
+	"Restart the service\nthen check its status:\n\n" +
+	"```bash\nsudo systemctl restart nginx\nsystemctl status nginx\n```\n\n" +
+	"```text\nActive: active (running)\n```\n\n" +
+	"## Clean\n\n" +
+	"- remove the old logs:\n\n" +
+	"  ~~~zsh\n  rm -f /var/log/app/*.gz(.m+7)\n  ~~~\n\n" +
+	"```\nunlabeled\n```\n\n" +
+	"```shell\n```\n"
+
+func TestExtractMarkdownSnippets(t *testing.T) {
+	snippets := ExtractMarkdownSnippets(snippetTestRunbook)
+	assert.Equal(t, []*Snippet{
+		{
+			Title:       "Restart the web server",
+			Description: "Restart the service\nthen check its status:",
+			Script:      "sudo systemctl restart nginx\nsystemctl status nginx",
+			Shell:       models.ShellDialectBash,
+			ShortName:   "",
+			Line:        9,
+		},
+		{
+			Title:       "Clean",
+			Description: "- remove the old logs:",
+			Script:      "rm -f /var/log/app/*.gz(.m+7)",
+			Shell:       models.ShellDialectZsh,
+			ShortName:   "",
+			Line:        22,
+		},
+	}, snippets, "only the blocks of a shell language are extracted")
+}
+
+func TestExtractScriptSnippets(t *testing.T) {
+	script := "#!/usr/bin/env sh\n" +
+		"# -----\n" +
+		"# Backup the database\n" +
+		"# keep a week of dumps\n" +
+		"# -----\n" +
+		"pg_dump db | gzip > \"db-$(date +%u).gz\"\n\n" +
+		"log() {\n  echo \"$1\"\n}\n" +
+		"cat <<EOF\ndone\nEOF\n" +
+		"find . -name '*.tmp' -exec rm {} \\;;\n"
+	snippets, err := ExtractScriptSnippets(script, models.ShellDialectBash)
+	require.NoError(t, err)
+	assert.Equal(t, []*Snippet{
+		{
+			Title:       "Backup the database",
+			Description: "keep a week of dumps",
+			Script:      "pg_dump db | gzip > \"db-$(date +%u).gz\"",
+			Shell:       models.ShellDialectSh,
+			ShortName:   "",
+			Line:        6,
+		},
+		{Title: "", Description: "", Script: "cat <<EOF\ndone\nEOF", Shell: models.ShellDialectSh, ShortName: "", Line: 11},
+		{Title: "", Description: "", Script: `find . -name '*.tmp' -exec rm {} \;`, Shell: models.ShellDialectSh, ShortName: "", Line: 14},
+	}, snippets, "the function definitions are skipped")
+
+	_, err = ExtractScriptSnippets("if true; then\n", models.ShellDialectBash)
+	assert.Error(t, err)
+}
+
+func TestExtractFileSnippets(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "clean.zsh")
+	require.NoError(t, os.WriteFile(scriptPath, []byte("rm -f *.log\n"), 0o600))
+	snippets, err := ExtractFileSnippets(scriptPath)
+	require.NoError(t, err)
+	require.Len(t, snippets, 1)
+	assert.Equal(t, models.ShellDialectZsh, snippets[0].Shell, "the shell is deduced from the extension")
+
+	assert.True(t, IsSnippetFile("docs/README.MD"))
+	assert.True(t, IsSnippetFile("bin/deploy.sh"))
+	assert.False(t, IsSnippetFile("main.go"))
+}
@@ -0,0 +1,554 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// syncCommandsDir is the directory of the repository holding a file per command
+	syncCommandsDir      = "commands"
+	syncFileExtension    = ".yaml"
+	syncRemoteName       = "origin"
+	defaultSyncBranch    = "main"
+	syncFileMode         = fs.FileMode(0o644)
+	syncDirMode          = fs.FileMode(0o755)
+	gitCommand           = "git"
+	syncCommitIdentity   = "shell-command-bookmarker"
+	syncCommitEmailHost  = "localhost"
+	syncCommitMessageFmt = "%d command(s) updated on %s"
+)
+
+// SyncResolution tells which version of a command modified on both sides is kept
+type SyncResolution string
+
+const (
+	// SyncResolutionAsk lets the conflict resolver choose the version of each command
+	SyncResolutionAsk SyncResolution = "ask"
+	// SyncResolutionLocal keeps the version of the database
+	SyncResolutionLocal SyncResolution = "local"
+	// SyncResolutionRemote keeps the version of the remote
+	SyncResolutionRemote SyncResolution = "remote"
+	// SyncResolutionKeepBoth keeps the local version and adds the remote one with a new uuid
+	SyncResolutionKeepBoth SyncResolution = "keep-both"
+)
+
+var (
+	// ErrSyncAborted indicates that the resolution of the conflicts was canceled
+	ErrSyncAborted = errors.New("sync aborted, the conflicts have not been resolved")
+	// ErrSyncFileUUIDMismatch indicates that a command file is not named by the uuid of its command
+	ErrSyncFileUUIDMismatch = errors.New("the file name does not match the uuid of the command")
+)
+
+// SyncConflict is a command modified differently in the database and on the
+// remote since the last sync
+type SyncConflict struct {
+	Local      *models.CatalogCommand
+	Remote     *models.CatalogCommand
+	Resolution SyncResolution
+}
+
+// SyncConflictResolver lets the user choose the resolution of each conflict
+type SyncConflictResolver interface {
+	// ResolveConflicts sets the resolution of the conflicts, ErrSyncAborted if canceled
+	ResolveConflicts(conflicts []*SyncConflict) error
+}
+
+// SyncReport summarizes a sync
+type SyncReport struct {
+	// Committed is the number of commands of the database written to the repository
+	Committed int
+	// Pulled is the number of commands added or modified by the remote
+	Pulled    int
+	Conflicts int
+	Pushed    bool
+}
+
+// SyncService synchronizes the commands with a git repository, each command
+// being written to its own file named by its uuid so that the changes of
+// different machines can be merged command by command
+type SyncService struct {
+	config   *SyncConfig
+	executor CommandExecutorInterface
+	dir      string
+}
+
+func NewSyncService(config *SyncConfig, executor CommandExecutorInterface) *SyncService {
+	return &SyncService{config: config, executor: executor, dir: ""}
+}
+
+// Sync writes the local commands to the repository and commits them, then
+// merges the commits of the remote, if configured, and pushes the result.
+// A command modified on both sides since the last sync is a conflict
+// resolved using the given resolution, or by the resolver if the resolution
+// is SyncResolutionAsk. The returned catalog holds all the commands of the
+// repository after the merge.
+func (s *SyncService) Sync(
+	local *models.Catalog, resolution SyncResolution, resolver SyncConflictResolver,
+) (*models.Catalog, *SyncReport, error) {
+	report := &SyncReport{Committed: 0, Pulled: 0, Conflicts: 0, Pushed: false}
+	if err := s.initRepository(); err != nil {
+		return nil, report, err
+	}
+	committed, err := s.commitLocalCommands(local)
+	if err != nil {
+		return nil, report, err
+	}
+	report.Committed = committed
+	ours, err := s.readCommandFiles()
+	if err != nil {
+		return nil, report, err
+	}
+
+	if s.config.Remote != "" {
+		if err := s.pull(ours, resolution, resolver, report); err != nil {
+			return nil, report, err
+		}
+		if _, err := s.git("push", syncRemoteName, "HEAD:refs/heads/"+s.getBranch()); err != nil {
+			return nil, report, err
+		}
+		report.Pushed = true
+	}
+
+	merged, err := s.readCommandFiles()
+	if err != nil {
+		return nil, report, err
+	}
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Commands:   make([]*models.CatalogCommand, 0, len(merged)),
+	}
+	for _, uuid := range slices.Sorted(maps.Keys(merged)) {
+		cmd, err := decodeSyncCommand(merged[uuid])
+		if err != nil {
+			return nil, report, &SyncFileError{Path: getSyncFilePath(uuid), Err: err}
+		}
+		catalog.Commands = append(catalog.Commands, cmd)
+	}
+	slog.Info("Commands synced", "dir", s.dir, "report", report)
+	return catalog, report, nil
+}
+
+// GetDir returns the directory of the repository, the directory of the
+// configuration is used if the sync directory is not configured
+func (s *SyncService) GetDir() (string, error) {
+	dir := s.config.Dir
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(configDir, "shell-command-bookmarker", "sync"), nil
+	}
+	return expandHomeDir(dir)
+}
+
+func (s *SyncService) getBranch() string {
+	return cmp.Or(s.config.Branch, defaultSyncBranch)
+}
+
+// initRepository creates the repository if it does not exist and sets the
+// url of its remote
+func (s *SyncService) initRepository() error {
+	dir, err := s.GetDir()
+	if err != nil {
+		return err
+	}
+	s.dir = dir
+	if err := os.MkdirAll(filepath.Join(dir, syncCommandsDir), syncDirMode); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if _, err := s.git("init"); err != nil {
+			return err
+		}
+		if _, err := s.git("symbolic-ref", "HEAD", "refs/heads/"+s.getBranch()); err != nil {
+			return err
+		}
+		slog.Info("Sync repository created", "dir", dir)
+	}
+	if s.config.Remote == "" {
+		return nil
+	}
+	url, err := s.git("remote", "get-url", syncRemoteName)
+	switch {
+	case err != nil:
+		_, err = s.git("remote", "add", syncRemoteName, s.config.Remote)
+	case strings.TrimSpace(url) != s.config.Remote:
+		_, err = s.git("remote", "set-url", syncRemoteName, s.config.Remote)
+	}
+	return err
+}
+
+// commitLocalCommands writes the file of each local command and commits the
+// modified files, the files of the commands missing locally are kept
+func (s *SyncService) commitLocalCommands(local *models.Catalog) (int, error) {
+	existing, err := s.readCommandFiles()
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for _, cmd := range local.Commands {
+		content, err := encodeSyncCommand(cmd)
+		if err != nil {
+			return 0, err
+		}
+		if bytes.Equal(existing[cmd.UUID], content) {
+			continue
+		}
+		if err := s.writeCommandFile(cmd.UUID, content); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	hostname, _ := os.Hostname()
+	return changed, s.commit(fmt.Sprintf(syncCommitMessageFmt, changed, cmp.Or(hostname, syncCommitEmailHost)))
+}
+
+// pull merges the commits of the remote branch, the conflicting commands are
+// resolved and the merged commands are committed
+func (s *SyncService) pull(
+	ours map[resource.UUID][]byte, resolution SyncResolution, resolver SyncConflictResolver, report *SyncReport,
+) error {
+	if _, err := s.git("fetch", syncRemoteName); err != nil {
+		return err
+	}
+	remoteRef := "refs/remotes/" + syncRemoteName + "/" + s.getBranch()
+	if _, err := s.git("rev-parse", "--verify", "--quiet", remoteRef); err != nil {
+		// the remote branch does not exist yet, it is created by the push
+		return nil //nolint:nilerr // not an error
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// nothing has been committed locally
+		return s.fastForward(remoteRef, ours, report)
+	}
+	if _, err := s.git("merge-base", "--is-ancestor", remoteRef, "HEAD"); err == nil {
+		return nil
+	}
+	if _, err := s.git("merge-base", "--is-ancestor", "HEAD", remoteRef); err == nil {
+		return s.fastForward(remoteRef, ours, report)
+	}
+
+	base := map[resource.UUID][]byte{}
+	if mergeBase, err := s.git("merge-base", "HEAD", remoteRef); err == nil {
+		if base, err = s.readTreeCommands(strings.TrimSpace(mergeBase)); err != nil {
+			return err
+		}
+	}
+	theirs, err := s.readTreeCommands(remoteRef)
+	if err != nil {
+		return err
+	}
+	merged, conflicts, err := mergeSyncCommands(base, ours, theirs)
+	if err != nil {
+		return err
+	}
+	report.Conflicts = len(conflicts)
+	if err := resolveSyncConflicts(conflicts, resolution, resolver); err != nil {
+		return err
+	}
+	if err := applySyncResolutions(merged, conflicts); err != nil {
+		return err
+	}
+
+	// the merge is recorded with the local files, replaced by the merged files
+	if _, err := s.git(append(s.getIdentityArgs(),
+		"merge", "--strategy", "ours", "--no-commit", "--allow-unrelated-histories", remoteRef)...,
+	); err != nil {
+		return err
+	}
+	err = s.replaceCommandFiles(merged)
+	if err == nil {
+		err = s.commit("Merge the commands of " + s.config.Remote)
+	}
+	if err != nil {
+		// a merge left in progress would be concluded by the next sync
+		if _, abortErr := s.git("merge", "--abort"); abortErr != nil {
+			slog.Error("Error aborting the merge", "dir", s.dir, "error", abortErr)
+		}
+		return err
+	}
+	report.Pulled = countChangedCommands(ours, merged)
+	return nil
+}
+
+func (s *SyncService) fastForward(remoteRef string, ours map[resource.UUID][]byte, report *SyncReport) error {
+	if _, err := s.git("merge", "--ff-only", remoteRef); err != nil {
+		return err
+	}
+	merged, err := s.readCommandFiles()
+	if err != nil {
+		return err
+	}
+	report.Pulled = countChangedCommands(ours, merged)
+	return nil
+}
+
+// mergeSyncCommands merges the files modified locally (ours) and on the
+// remote (theirs) since their common version (base). A command modified on
+// both sides differently is a conflict, it is not part of the merged files.
+func mergeSyncCommands(
+	base, ours, theirs map[resource.UUID][]byte,
+) (merged map[resource.UUID][]byte, conflicts []*SyncConflict, err error) {
+	merged = maps.Clone(ours)
+	conflicts = []*SyncConflict{}
+	for _, uuid := range slices.Sorted(maps.Keys(theirs)) {
+		remote := theirs[uuid]
+		local, ok := ours[uuid]
+		switch {
+		case !ok, bytes.Equal(local, base[uuid]):
+			merged[uuid] = remote
+		case bytes.Equal(local, remote), bytes.Equal(remote, base[uuid]):
+			// unchanged or only modified locally
+		default:
+			delete(merged, uuid)
+			conflict := &SyncConflict{Local: nil, Remote: nil, Resolution: SyncResolutionAsk}
+			if conflict.Local, err = decodeSyncCommand(local); err != nil {
+				return nil, nil, &SyncFileError{Path: getSyncFilePath(uuid), Err: err}
+			}
+			if conflict.Remote, err = decodeSyncCommand(remote); err != nil {
+				return nil, nil, &SyncFileError{Path: getSyncFilePath(uuid), Err: err}
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return merged, conflicts, nil
+}
+
+// resolveSyncConflicts applies the resolution to all the conflicts, or asks
+// the resolver if the resolution is SyncResolutionAsk
+func resolveSyncConflicts(conflicts []*SyncConflict, resolution SyncResolution, resolver SyncConflictResolver) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if resolution != SyncResolutionAsk {
+		for _, conflict := range conflicts {
+			conflict.Resolution = resolution
+		}
+		return nil
+	}
+	if resolver == nil {
+		return &SyncConflictsError{Count: len(conflicts)}
+	}
+	if err := resolver.ResolveConflicts(conflicts); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(conflicts, func(conflict *SyncConflict) bool {
+		return conflict.Resolution == SyncResolutionAsk
+	}) {
+		return ErrSyncAborted
+	}
+	return nil
+}
+
+// applySyncResolutions adds the version of the commands selected by the
+// resolution of the conflicts to the merged files
+func applySyncResolutions(merged map[resource.UUID][]byte, conflicts []*SyncConflict) error {
+	for _, conflict := range conflicts {
+		versions := []*models.CatalogCommand{}
+		switch conflict.Resolution {
+		case SyncResolutionLocal:
+			versions = append(versions, conflict.Local)
+		case SyncResolutionRemote:
+			versions = append(versions, conflict.Remote)
+		case SyncResolutionKeepBoth:
+			remote := *conflict.Remote
+			// the uuid identifies a single command
+			remote.UUID = resource.NewUUID()
+			versions = append(versions, conflict.Local, &remote)
+		case SyncResolutionAsk:
+			return ErrSyncAborted
+		}
+		for _, version := range versions {
+			content, err := encodeSyncCommand(version)
+			if err != nil {
+				return err
+			}
+			merged[version.UUID] = content
+		}
+	}
+	return nil
+}
+
+func countChangedCommands(before, after map[resource.UUID][]byte) int {
+	count := 0
+	for uuid, content := range after {
+		if !bytes.Equal(before[uuid], content) {
+			count++
+		}
+	}
+	return count
+}
+
+// encodeSyncCommand returns the content of the file of a command, the
+// content hash is computed by the database and the lint result depends on
+// the configuration of each machine, they are not written
+func encodeSyncCommand(cmd *models.CatalogCommand) ([]byte, error) {
+	synced := *cmd
+	synced.ContentHash = ""
+	synced.Lint = nil
+	synced.Tags = slices.Sorted(slices.Values(cmd.Tags))
+	synced.Created = synced.Created.UTC().Truncate(time.Second)
+	synced.Modified = synced.Modified.UTC().Truncate(time.Second)
+	var content bytes.Buffer
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(catalogIndent)
+	if err := encoder.Encode(&synced); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+func decodeSyncCommand(content []byte) (*models.CatalogCommand, error) {
+	var cmd models.CatalogCommand
+	if err := yaml.Unmarshal(content, &cmd); err != nil {
+		return nil, err
+	}
+	return &cmd, nil
+}
+
+func getSyncFilePath(uuid resource.UUID) string {
+	return filepath.Join(syncCommandsDir, string(uuid)+syncFileExtension)
+}
+
+// readCommandFiles returns the files of the working directory by uuid, the
+// files are encoded again so that they can be compared to the local commands
+func (s *SyncService) readCommandFiles() (map[resource.UUID][]byte, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, syncCommandsDir))
+	if err != nil {
+		return nil, err
+	}
+	files := map[resource.UUID][]byte{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != syncFileExtension {
+			continue
+		}
+		path := filepath.Join(syncCommandsDir, name)
+		content, err := os.ReadFile(filepath.Join(s.dir, path)) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		if err := addSyncFile(files, path, content); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readTreeCommands returns the files of the given commit by uuid
+func (s *SyncService) readTreeCommands(revision string) (map[resource.UUID][]byte, error) {
+	output, err := s.git("ls-tree", "-r", "--name-only", revision, "--", syncCommandsDir+"/")
+	if err != nil {
+		return nil, err
+	}
+	files := map[resource.UUID][]byte{}
+	for _, path := range strings.Fields(output) {
+		if filepath.Ext(path) != syncFileExtension {
+			continue
+		}
+		content, err := s.git("show", revision+":"+path)
+		if err != nil {
+			return nil, err
+		}
+		if err := addSyncFile(files, path, []byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func addSyncFile(files map[resource.UUID][]byte, path string, content []byte) error {
+	cmd, err := decodeSyncCommand(content)
+	if err == nil {
+		content, err = encodeSyncCommand(cmd)
+	}
+	if err != nil {
+		return &SyncFileError{Path: path, Err: err}
+	}
+	uuid := resource.UUID(strings.TrimSuffix(filepath.Base(path), syncFileExtension))
+	if cmd.UUID != uuid {
+		return &SyncFileError{Path: path, Err: fmt.Errorf("%w: %s", ErrSyncFileUUIDMismatch, cmd.UUID)}
+	}
+	files[uuid] = content
+	return nil
+}
+
+func (s *SyncService) writeCommandFile(uuid resource.UUID, content []byte) error {
+	return os.WriteFile(filepath.Join(s.dir, getSyncFilePath(uuid)), content, syncFileMode)
+}
+
+// replaceCommandFiles writes the given files and removes the other files
+func (s *SyncService) replaceCommandFiles(files map[resource.UUID][]byte) error {
+	existing, err := s.readCommandFiles()
+	if err != nil {
+		return err
+	}
+	for uuid := range existing {
+		if _, ok := files[uuid]; !ok {
+			if err := os.Remove(filepath.Join(s.dir, getSyncFilePath(uuid))); err != nil {
+				return err
+			}
+		}
+	}
+	for uuid, content := range files {
+		if !bytes.Equal(existing[uuid], content) {
+			if err := s.writeCommandFile(uuid, content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// commit commits all the changes of the commands directory
+func (s *SyncService) commit(message string) error {
+	if _, err := s.git("add", "--all", "--", syncCommandsDir); err != nil {
+		return err
+	}
+	_, err := s.git(append(s.getIdentityArgs(), "commit", "--quiet", "--allow-empty", "-m", message)...)
+	return err
+}
+
+// getIdentityArgs returns the options setting the identity of the commits
+// to the application if git has no configured identity
+func (s *SyncService) getIdentityArgs() []string {
+	args := []string{}
+	if name, err := s.git("config", "user.name"); err != nil || strings.TrimSpace(name) == "" {
+		args = append(args, "-c", "user.name="+syncCommitIdentity)
+	}
+	if email, err := s.git("config", "user.email"); err != nil || strings.TrimSpace(email) == "" {
+		args = append(args, "-c", "user.email="+syncCommitIdentity+"@"+syncCommitEmailHost)
+	}
+	return args
+}
+
+// git runs a git command in the repository and returns its output
+func (s *SyncService) git(args ...string) (string, error) {
+	output, errOutput, err := s.executor.ExecuteCommandWithStdin(gitCommand, append([]string{"-C", s.dir}, args...), "")
+	if err != nil {
+		return output, &GitCommandError{Args: args, Output: strings.TrimSpace(errOutput), Err: err}
+	}
+	return output, nil
+}
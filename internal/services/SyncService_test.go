@@ -0,0 +1,179 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSyncConflictResolver struct {
+	resolution SyncResolution
+	conflicts  int
+}
+
+func (m *MockSyncConflictResolver) ResolveConflicts(conflicts []*SyncConflict) error {
+	m.conflicts += len(conflicts)
+	for _, conflict := range conflicts {
+		conflict.Resolution = m.resolution
+	}
+	return nil
+}
+
+func newSyncTestCommand(title string, script string) *models.CatalogCommand {
+	cmd := newCatalogTestCommand(0, title, script)
+	return models.NewCatalogCommand(cmd)
+}
+
+func newSyncTestCatalog(commands ...*models.CatalogCommand) *models.Catalog {
+	return &models.Catalog{Version: models.CatalogVersion, ExportedAt: time.Now(), Commands: commands}
+}
+
+func encodeSyncTestCommand(t *testing.T, cmd *models.CatalogCommand) []byte {
+	t.Helper()
+	content, err := encodeSyncCommand(cmd)
+	require.NoError(t, err)
+	return content
+}
+
+func TestMergeSyncCommands(t *testing.T) {
+	unchanged := newSyncTestCommand("Unchanged", "ls")
+	base := newSyncTestCommand("Base", "make")
+	local := *base
+	local.Title = "Local"
+	remote := *base
+	remote.Title = "Remote"
+	added := newSyncTestCommand("Added", "uptime")
+
+	merged, conflicts, err := mergeSyncCommands(
+		map[resource.UUID][]byte{
+			unchanged.UUID: encodeSyncTestCommand(t, unchanged),
+			base.UUID:      encodeSyncTestCommand(t, base),
+		},
+		map[resource.UUID][]byte{
+			unchanged.UUID: encodeSyncTestCommand(t, unchanged),
+			base.UUID:      encodeSyncTestCommand(t, &local),
+		},
+		map[resource.UUID][]byte{
+			unchanged.UUID: encodeSyncTestCommand(t, unchanged),
+			base.UUID:      encodeSyncTestCommand(t, &remote),
+			added.UUID:     encodeSyncTestCommand(t, added),
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[resource.UUID][]byte{
+		unchanged.UUID: encodeSyncTestCommand(t, unchanged),
+		added.UUID:     encodeSyncTestCommand(t, added),
+	}, merged, "the conflicting commands are not merged")
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "Local", conflicts[0].Local.Title)
+	assert.Equal(t, "Remote", conflicts[0].Remote.Title)
+
+	t.Run("modified on one side only", func(t *testing.T) {
+		merged, conflicts, err := mergeSyncCommands(
+			map[resource.UUID][]byte{base.UUID: encodeSyncTestCommand(t, base)},
+			map[resource.UUID][]byte{base.UUID: encodeSyncTestCommand(t, base)},
+			map[resource.UUID][]byte{base.UUID: encodeSyncTestCommand(t, &remote)},
+		)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+		assert.Equal(t, encodeSyncTestCommand(t, &remote), merged[base.UUID])
+	})
+
+	t.Run("keep both", func(t *testing.T) {
+		require.NoError(t, applySyncResolutions(merged, []*SyncConflict{
+			{Local: &local, Remote: &remote, Resolution: SyncResolutionKeepBoth},
+		}))
+		assert.Len(t, merged, 4)
+		assert.Equal(t, encodeSyncTestCommand(t, &local), merged[base.UUID])
+	})
+}
+
+func TestEncodeSyncCommand(t *testing.T) {
+	cmd := newSyncTestCommand("Backup", "pg_dump db")
+	cmd.Tags = []string{"db", "backup"}
+	cmd.Lint = &models.CatalogLint{Status: models.LintStatusOK, Rules: nil, Issues: nil}
+	cmd.Modified = time.Date(2025, 1, 2, 4, 4, 5, 0, time.FixedZone("CET", 3600))
+	content := encodeSyncTestCommand(t, cmd)
+	assert.Equal(t, "uuid: "+string(cmd.UUID)+"\n"+
+		"title: Backup\n"+
+		"script: pg_dump db\n"+
+		"shell: bash\n"+
+		"status: SAVED\n"+
+		"tags:\n  - backup\n  - db\n"+
+		"created: 2025-01-02T03:04:05Z\n"+
+		"modified: 2025-01-02T03:04:05Z\n", string(content),
+		"the derived fields are not written, the tags are sorted and the dates in UTC")
+}
+
+func TestSyncService_Sync(t *testing.T) {
+	if _, err := exec.LookPath(gitCommand); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	_, errOutput, err := (&executors.DefaultCommandExecutor{}).ExecuteCommandWithStdin(
+		gitCommand, []string{"init", "--quiet", "--bare", remote}, "",
+	)
+	require.NoError(t, err, errOutput)
+	newService := func(name string) *SyncService {
+		return NewSyncService(
+			&SyncConfig{Dir: filepath.Join(dir, name), Remote: remote, Branch: ""},
+			&executors.DefaultCommandExecutor{},
+		)
+	}
+	laptop, desktop := newService("laptop"), newService("desktop")
+	deploy := newSyncTestCommand("Deploy", "make deploy")
+	backup := newSyncTestCommand("Backup", "pg_dump db")
+
+	catalog, report, err := laptop.Sync(newSyncTestCatalog(deploy, backup), SyncResolutionAsk, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &SyncReport{Committed: 2, Pulled: 0, Conflicts: 0, Pushed: true}, report)
+	assert.Len(t, catalog.Commands, 2)
+	assert.FileExists(t, filepath.Join(dir, "laptop", syncCommandsDir, string(deploy.UUID)+syncFileExtension))
+
+	catalog, report, err = desktop.Sync(newSyncTestCatalog(), SyncResolutionAsk, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &SyncReport{Committed: 0, Pulled: 2, Conflicts: 0, Pushed: true}, report)
+	require.Len(t, catalog.Commands, 2)
+
+	// both machines modify the deploy command, the desktop also deletes the backup
+	laptopDeploy, desktopDeploy, desktopBackup := *deploy, *deploy, *backup
+	laptopDeploy.Script = "make deploy ENV=prod"
+	desktopDeploy.Tags = []string{"ops"}
+	desktopBackup.Status = models.CommandStatusDeleted
+	_, _, err = laptop.Sync(newSyncTestCatalog(&laptopDeploy, backup), SyncResolutionAsk, nil)
+	require.NoError(t, err)
+
+	_, _, err = desktop.Sync(newSyncTestCatalog(&desktopDeploy, &desktopBackup), SyncResolutionAsk, nil)
+	var conflictsError *SyncConflictsError
+	require.ErrorAs(t, err, &conflictsError, "the conflicts cannot be resolved without resolver")
+	assert.Equal(t, 1, conflictsError.Count)
+
+	resolver := &MockSyncConflictResolver{resolution: SyncResolutionRemote, conflicts: 0}
+	catalog, report, err = desktop.Sync(newSyncTestCatalog(&desktopDeploy, &desktopBackup), SyncResolutionAsk, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolver.conflicts)
+	assert.Equal(t, &SyncReport{Committed: 0, Pulled: 1, Conflicts: 1, Pushed: true}, report)
+	commands := map[resource.UUID]*models.CatalogCommand{}
+	for _, cmd := range catalog.Commands {
+		commands[cmd.UUID] = cmd
+	}
+	assert.Equal(t, "make deploy ENV=prod", commands[deploy.UUID].Script, "the remote version is kept")
+	assert.Equal(t, models.CommandStatusDeleted, commands[backup.UUID].Status, "the local change is merged")
+
+	catalog, report, err = laptop.Sync(newSyncTestCatalog(&laptopDeploy, backup), SyncResolutionAsk, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &SyncReport{Committed: 0, Pulled: 1, Conflicts: 0, Pushed: true}, report)
+	assert.Len(t, catalog.Commands, 2)
+
+	_, err = os.Stat(filepath.Join(dir, "desktop", ".git", "MERGE_HEAD"))
+	assert.ErrorIs(t, err, os.ErrNotExist, "no merge is left in progress")
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// syntaxRule is the rule of the issues reported by the syntax linter
+const syntaxRule = "syntax"
+
+// syntaxLinter checks the syntax of the scripts using a Go shell parser,
+// so that syntax errors are reported even if shellcheck is not installed.
+// It is skipped if shellcheck can lint the script as shellcheck reports
+// the same errors.
+type syntaxLinter struct {
+	shellcheck Linter
+}
+
+func newSyntaxLinter(shellcheck Linter) *syntaxLinter {
+	return &syntaxLinter{
+		shellcheck: shellcheck,
+	}
+}
+
+// getLangVariant returns the parser variant of the dialect,
+// ok is false if the dialect is not supported by the parser (zsh)
+func getLangVariant(dialect models.ShellDialect) (variant syntax.LangVariant, ok bool) {
+	switch dialect {
+	case models.ShellDialectBash:
+		return syntax.LangBash, true
+	case models.ShellDialectSh, models.ShellDialectDash:
+		return syntax.LangPOSIX, true
+	case models.ShellDialectKsh:
+		return syntax.LangMirBSDKorn, true
+	case models.ShellDialectZsh:
+		return syntax.LangAuto, false
+	default:
+		return syntax.LangAuto, false
+	}
+}
+
+func (*syntaxLinter) GetName() string {
+	return LinterSyntax
+}
+
+func (*syntaxLinter) IsAvailable() bool {
+	return true
+}
+
+func (l *syntaxLinter) IsDialectSupported(dialect models.ShellDialect) bool {
+	if _, ok := getLangVariant(dialect); !ok {
+		return false
+	}
+	return l.shellcheck == nil || !l.shellcheck.IsAvailable() || !l.shellcheck.IsDialectSupported(dialect)
+}
+
+func (*syntaxLinter) GetSignature(dialect models.ShellDialect, _ models.LintRules) string {
+	variant, _ := getLangVariant(dialect)
+	return variant.String()
+}
+
+func (*syntaxLinter) Lint(script string, dialect models.ShellDialect, _ models.LintRules) ([]ShellCheckIssue, error) {
+	variant, ok := getLangVariant(dialect)
+	if !ok {
+		return nil, ErrShellDialectNotSupported
+	}
+	_, err := syntax.NewParser(syntax.Variant(variant)).Parse(strings.NewReader(script), "")
+	if err == nil {
+		return []ShellCheckIssue{}, nil
+	}
+
+	var parseErr syntax.ParseError
+	var langErr syntax.LangError
+	var pos syntax.Pos
+	var message string
+	switch {
+	case errors.As(err, &parseErr):
+		pos, message = parseErr.Pos, parseErr.Text
+	case errors.As(err, &langErr):
+		pos, message = langErr.Pos, strings.TrimPrefix(langErr.Error(), langErr.Pos.String()+": ")
+	default:
+		return nil, err
+	}
+	line, column := getIssuePosition(script, int(pos.Offset()))
+	return []ShellCheckIssue{
+		{
+			Fix:       nil,
+			File:      "-",
+			Level:     string(models.LintLevelError),
+			Message:   message,
+			Linter:    LinterSyntax,
+			Rule:      syntaxRule,
+			Line:      line,
+			EndLine:   line,
+			Column:    column,
+			EndColumn: column + 1,
+			Code:      0,
+		},
+	}, nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyntaxLinter(t *testing.T) {
+	linter := newSyntaxLinter(nil)
+	rules := models.GetDefaultLintRules()
+
+	t.Run("Valid script", func(t *testing.T) {
+		issues, err := linter.Lint("if true; then\n\techo ok\nfi", models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		issues, err := linter.Lint("echo ok\nif true; then\n\techo (", models.ShellDialectBash, rules)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "error", issues[0].Level)
+		assert.Equal(t, syntaxRule, issues[0].Rule)
+		assert.Equal(t, 3, issues[0].Line)
+		assert.Equal(t, 9, issues[0].Column, "columns are computed with tab stops")
+	})
+
+	t.Run("Bash feature in posix script", func(t *testing.T) {
+		issues, err := linter.Lint("arr=(a b)", models.ShellDialectSh, rules)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "arrays are a bash/mksh feature", issues[0].Message)
+	})
+
+	t.Run("Skipped if shellcheck can lint the dialect", func(t *testing.T) {
+		service := NewLintService(WithShellcheckRCPaths([]string{}))
+		service.shellCheckPath = "/fake/path/to/shellcheck"
+		linter := newSyntaxLinter(newShellcheckLinter(service))
+		assert.False(t, linter.IsDialectSupported(models.ShellDialectBash))
+		service.shellCheckPath = ""
+		assert.True(t, linter.IsDialectSupported(models.ShellDialectBash))
+		assert.False(t, linter.IsDialectSupported(models.ShellDialectZsh))
+	})
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// ErrTeamCatalogPathMissing indicates that a team catalog of the configuration has no path
+var ErrTeamCatalogPathMissing = errors.New("the path of the catalog is missing")
+
+// getTeamCatalogExtensions returns the extensions of the files read in a team
+// catalog directory, the markdown cheat sheets cannot be read back
+func getTeamCatalogExtensions() []string {
+	return []string{".json", ".yaml", ".yml", ".cheat", ".toml"}
+}
+
+// TeamCatalogService reads the saved commands of the read-only catalogs shared
+// by a team, eg: on a shared mount. A catalog is a database file or a directory
+// of catalog files. The commands are given negative ids, in the order they are
+// read, so that they cannot be mistaken for the commands of the database.
+type TeamCatalogService struct {
+	catalogService *CatalogService
+	configs        []TeamCatalogConfig
+	commands       []*models.Command
+	mu             sync.Mutex
+}
+
+func NewTeamCatalogService(configs []TeamCatalogConfig, catalogService *CatalogService) *TeamCatalogService {
+	return &TeamCatalogService{
+		catalogService: catalogService,
+		configs:        configs,
+		commands:       nil,
+		mu:             sync.Mutex{},
+	}
+}
+
+// HasCatalogs returns true if at least one team catalog is configured
+func (s *TeamCatalogService) HasCatalogs() bool {
+	return len(s.configs) > 0
+}
+
+// GetCommands reads the team catalogs again and returns their saved commands,
+// the Source of a command is the name of its catalog. The commands of the
+// readable catalogs are returned along with the errors of the other catalogs.
+func (s *TeamCatalogService) GetCommands() ([]*models.Command, error) {
+	commands := []*models.Command{}
+	errs := []error{}
+	for _, config := range s.configs {
+		catalogCommands, err := readTeamCatalog(config)
+		if err != nil {
+			slog.Error("Error reading team catalog", "path", config.Path, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		for _, cmd := range catalogCommands {
+			cmd.ID = resource.ID(-len(commands) - 1)
+			commands = append(commands, cmd)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = commands
+	return slices.Clone(commands), errors.Join(errs...)
+}
+
+// GetCommandByID returns the command having the id given by the last call of
+// GetCommands, nil if none
+func (s *TeamCatalogService) GetCommandByID(id resource.ID) *models.Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cmd := range s.commands {
+		if cmd.ID == id {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// CountCommands returns the number of commands read by the last call of
+// GetCommands, the catalogs are read if they have not been read yet
+func (s *TeamCatalogService) CountCommands() int {
+	s.mu.Lock()
+	loaded, count := s.commands != nil, len(s.commands)
+	s.mu.Unlock()
+	if loaded {
+		return count
+	}
+	// the errors are logged by GetCommands
+	commands, _ := s.GetCommands()
+	return len(commands)
+}
+
+// CopyCommands stores a copy of the team commands in the database, the copies
+// keep the uuid of the team commands so that a command already copied is
+// skipped
+func (s *TeamCatalogService) CopyCommands(commands []*models.Command) (*ImportReport, error) {
+	catalog := &models.Catalog{
+		Version:    models.CatalogVersion,
+		ExportedAt: time.Now(),
+		Commands:   make([]*models.CatalogCommand, 0, len(commands)),
+	}
+	for _, cmd := range commands {
+		catalog.Commands = append(catalog.Commands, models.NewCatalogCommand(cmd))
+	}
+	return s.catalogService.ImportCatalog(catalog, models.MergeStrategySkip)
+}
+
+// getTeamCatalogName returns the configured name of the catalog, or the base
+// name of its path without extension
+func getTeamCatalogName(config TeamCatalogConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	name := filepath.Base(config.Path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// readTeamCatalog returns the saved commands of a database or of a directory
+// of catalog files
+func readTeamCatalog(config TeamCatalogConfig) ([]*models.Command, error) {
+	name := getTeamCatalogName(config)
+	if config.Path == "" {
+		return nil, &TeamCatalogError{Err: ErrTeamCatalogPathMissing, Name: name, Path: ""}
+	}
+	path, err := expandHomeDir(config.Path)
+	if err != nil {
+		return nil, &TeamCatalogError{Err: err, Name: name, Path: config.Path}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &TeamCatalogError{Err: err, Name: name, Path: path}
+	}
+	var commands []*models.Command
+	if info.IsDir() {
+		commands, err = readTeamCatalogDir(name, path)
+	} else {
+		commands, err = readTeamCatalogDB(path)
+	}
+	if err != nil {
+		var catalogErr *TeamCatalogError
+		if !errors.As(err, &catalogErr) {
+			err = &TeamCatalogError{Err: err, Name: name, Path: path}
+		}
+		return nil, err
+	}
+	for _, cmd := range commands {
+		cmd.Source = name
+	}
+	return commands, nil
+}
+
+// readTeamCatalogDB returns the saved commands of a database opened read-only
+func readTeamCatalogDB(path string) ([]*models.Command, error) {
+	dbService := NewReadOnlyDBService(path)
+	if err := dbService.Open(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := dbService.Close(); err != nil {
+			slog.Error("Error closing team catalog database", "path", path, "error", err)
+		}
+	}()
+	return dbService.GetCommands(models.CommandStatusSaved)
+}
+
+// readTeamCatalogDir returns the saved commands of the catalog files of the
+// directory and of its sub directories, the command files of a sync
+// repository are read too. The hidden directories are skipped.
+func readTeamCatalogDir(name string, dir string) ([]*models.Command, error) {
+	commands := []*models.Command{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(getTeamCatalogExtensions(), strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+		fileCommands, err := readTeamCatalogFile(path)
+		if err != nil {
+			return &TeamCatalogError{Err: err, Name: name, Path: path}
+		}
+		commands = append(commands, fileCommands...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// readTeamCatalogFile returns the saved commands of a catalog file, or of a
+// file of the commands directory of a sync repository
+func readTeamCatalogFile(path string) ([]*models.Command, error) {
+	var catalogCommands []*models.CatalogCommand
+	if filepath.Base(filepath.Dir(path)) == syncCommandsDir && filepath.Ext(path) == syncFileExtension {
+		content, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		cmd, err := decodeSyncCommand(content)
+		if err != nil {
+			return nil, err
+		}
+		catalogCommands = []*models.CatalogCommand{cmd}
+	} else {
+		file, err := os.Open(path) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		catalog, err := ReadCatalog(file, DetectCatalogFormat(path))
+		if err != nil {
+			return nil, err
+		}
+		catalogCommands = catalog.Commands
+	}
+	commands := []*models.Command{}
+	for i, catalogCommand := range catalogCommands {
+		cmd, err := getImportedCommand(i, catalogCommand)
+		if err != nil {
+			return nil, err
+		}
+		if cmd.Status == models.CommandStatusSaved {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTeamCatalogTestFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+}
+
+func newTeamCatalogTestDir(t *testing.T) (string, *models.CatalogCommand) {
+	t.Helper()
+	dir := t.TempDir()
+	writeTeamCatalogTestFile(t, filepath.Join(dir, "ops.yaml"), []byte("version: 1\ncommands:\n"+
+		"  - title: Restart\n    script: systemctl restart nginx\n"+
+		"  - title: Old deploy\n    script: make old-deploy\n    status: DELETED\n"))
+	synced := newSyncTestCommand("Backup", "pg_dump db")
+	writeTeamCatalogTestFile(t, filepath.Join(dir, "sync", getSyncFilePath(synced.UUID)), encodeSyncTestCommand(t, synced))
+	writeTeamCatalogTestFile(t, filepath.Join(dir, ".git", "config.yaml"), []byte("not a catalog"))
+	writeTeamCatalogTestFile(t, filepath.Join(dir, "README.md"), []byte("# Team commands\n"))
+	return dir, synced
+}
+
+func TestTeamCatalogService_GetCommands(t *testing.T) {
+	dir, synced := newTeamCatalogTestDir(t)
+	service := NewTeamCatalogService([]TeamCatalogConfig{
+		{Name: "", Path: dir},
+		{Name: "missing", Path: filepath.Join(dir, "missing.db")},
+	}, NewCatalogService(&MockCatalogStore{commands: []*models.Command{}}))
+	assert.True(t, service.HasCatalogs())
+
+	commands, err := service.GetCommands()
+	var catalogErr *TeamCatalogError
+	require.ErrorAs(t, err, &catalogErr, "the unreadable catalogs are reported")
+	assert.Equal(t, "missing", catalogErr.Name)
+	require.Len(t, commands, 2, "the deleted commands, the hidden directories and the cheat sheets are skipped")
+	assert.Equal(t, "Restart", commands[0].Title)
+	assert.Equal(t, synced.UUID, commands[1].UUID, "the command files of a sync repository are read")
+	for i, cmd := range commands {
+		assert.Equal(t, resource.ID(-i-1), cmd.ID)
+		assert.Equal(t, filepath.Base(dir), cmd.Source, "the name defaults to the base name of the path")
+		assert.False(t, cmd.IsEditable())
+	}
+	assert.Equal(t, commands[1], service.GetCommandByID(-2))
+	assert.Nil(t, service.GetCommandByID(1))
+	assert.Equal(t, 2, service.CountCommands())
+}
+
+func TestTeamCatalogService_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTeamCatalogTestFile(t, filepath.Join(dir, "team.json"), []byte("{"))
+	service := NewTeamCatalogService(
+		[]TeamCatalogConfig{{Name: "team", Path: dir}},
+		NewCatalogService(&MockCatalogStore{commands: []*models.Command{}}),
+	)
+	assert.Zero(t, service.CountCommands(), "the catalogs are read on first count")
+	_, err := service.GetCommands()
+	var catalogErr *TeamCatalogError
+	require.ErrorAs(t, err, &catalogErr)
+	assert.Equal(t, filepath.Join(dir, "team.json"), catalogErr.Path, "the file in error is reported")
+}
+
+func TestTeamCatalogService_CopyCommands(t *testing.T) {
+	dir, _ := newTeamCatalogTestDir(t)
+	store := &MockCatalogStore{commands: []*models.Command{}}
+	service := NewTeamCatalogService([]TeamCatalogConfig{{Name: "team", Path: dir}}, NewCatalogService(store))
+	commands, err := service.GetCommands()
+	require.NoError(t, err)
+
+	report, err := service.CopyCommands(commands)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 2, Overwritten: 0, Skipped: 0}, report)
+	require.Len(t, store.commands, 2)
+	assert.Equal(t, resource.ID(1), store.commands[0].ID)
+	assert.Empty(t, store.commands[0].Source, "the copies are not team commands")
+	assert.True(t, store.commands[0].IsEditable())
+
+	report, err = service.CopyCommands(commands)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 0, Overwritten: 0, Skipped: 2}, report, "the commands are copied once")
+}
@@ -1,6 +1,12 @@
 package services
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
 
 type ShellcheckUnknownError struct {
 	Err error
@@ -19,6 +25,67 @@ func (e *ShellcheckParseError) Error() string {
 	return fmt.Sprintf("shellcheck parse error: %v | Output: %s", e.Err, e.Output)
 }
 
+type LintTaskError struct {
+	Err       error
+	CommandID resource.ID
+}
+
+func (e *LintTaskError) Error() string {
+	return fmt.Sprintf("lint of command #%d failed: %v", e.CommandID, e.Err)
+}
+
+func (e *LintTaskError) Unwrap() error {
+	return e.Err
+}
+
+type CommandNotFoundError struct {
+	ID resource.ID
+}
+
+func (e *CommandNotFoundError) Error() string {
+	return fmt.Sprintf("command #%d not found", e.ID)
+}
+
+// CommandStatusError is returned when a command line action cannot be
+// applied to a command because of its status, eg: restoring a saved command
+type CommandStatusError struct {
+	ID     resource.ID
+	Status models.CommandStatus
+	Action string
+}
+
+func (e *CommandStatusError) Error() string {
+	return fmt.Sprintf("command #%d cannot be %s, its status is %s", e.ID, e.Action, e.Status)
+}
+
+// InvalidCommandError is returned when a command provided on the command line
+// would be rejected by the database
+type InvalidCommandError struct {
+	Reason string
+}
+
+func (e *InvalidCommandError) Error() string {
+	return "invalid command: " + e.Reason
+}
+
+// CommandAlreadyExistsError is returned when a command having the same script
+// is already stored
+type CommandAlreadyExistsError struct {
+	ID resource.ID
+}
+
+func (e *CommandAlreadyExistsError) Error() string {
+	return fmt.Sprintf("command #%d has the same script", e.ID)
+}
+
+type UnknownCliCommandError struct {
+	Command string
+}
+
+func (e *UnknownCliCommandError) Error() string {
+	return "unknown command: " + e.Command
+}
+
 type ComposeInsufficientCommandsProvidedError struct {
 	Err error
 }
@@ -34,3 +101,231 @@ type InvalidTerminalError struct {
 func (e *InvalidTerminalError) Error() string {
 	return fmt.Errorf("invalid terminal error: %w", e.Err).Error()
 }
+
+type ShellcheckFixError struct {
+	Err  error
+	Code string
+}
+
+func (e *ShellcheckFixError) Error() string {
+	return fmt.Sprintf("cannot apply fix %s: %v", e.Code, e.Err)
+}
+
+func (e *ShellcheckFixError) Unwrap() error {
+	return e.Err
+}
+
+type ConfigLoadError struct {
+	Err  error
+	File string
+}
+
+func (e *ConfigLoadError) Error() string {
+	return fmt.Sprintf("unable to load configuration file %s: %v", e.File, e.Err)
+}
+
+func (e *ConfigLoadError) Unwrap() error {
+	return e.Err
+}
+
+type UnknownLinterError struct {
+	Linter string
+}
+
+func (e *UnknownLinterError) Error() string {
+	return fmt.Sprintf("unknown linter %q, expected one of: %s", e.Linter, strings.Join(GetLinterNames(), ", "))
+}
+
+type InvalidCustomLintRuleError struct {
+	Err  error
+	Name string
+}
+
+func (e *InvalidCustomLintRuleError) Error() string {
+	return fmt.Sprintf("invalid custom lint rule %q: %v", e.Name, e.Err)
+}
+
+func (e *InvalidCustomLintRuleError) Unwrap() error {
+	return e.Err
+}
+
+// PlaceholderCommandError is returned when the command suggesting the values of a placeholder fails
+type PlaceholderCommandError struct {
+	Err     error
+	Command string
+}
+
+func (e *PlaceholderCommandError) Error() string {
+	return fmt.Sprintf("placeholder suggestions command %q failed: %v", e.Command, e.Err)
+}
+
+func (e *PlaceholderCommandError) Unwrap() error {
+	return e.Err
+}
+
+type ExecutionNotFoundError struct {
+	ID resource.ID
+}
+
+func (e *ExecutionNotFoundError) Error() string {
+	return fmt.Sprintf("execution #%d not found", e.ID)
+}
+
+type ScriptFileExistsError struct {
+	Path string
+}
+
+func (e *ScriptFileExistsError) Error() string {
+	return fmt.Sprintf("file %s already exists", e.Path)
+}
+
+type BinDirNotConfiguredError struct{}
+
+func (*BinDirNotConfiguredError) Error() string {
+	return "the bin directory is not configured, set export.binDir in the configuration file"
+}
+
+type ExportScriptError struct {
+	Err  error
+	Path string
+}
+
+func (e *ExportScriptError) Error() string {
+	return fmt.Sprintf("failed to export script to %s: %v", e.Path, e.Err)
+}
+
+func (e *ExportScriptError) Unwrap() error {
+	return e.Err
+}
+
+type LibraryNotConfiguredError struct{}
+
+func (*LibraryNotConfiguredError) Error() string {
+	return "no shell library is configured, set library.bash or library.zsh in the configuration file"
+}
+
+type WriteLibraryError struct {
+	Err  error
+	Path string
+}
+
+func (e *WriteLibraryError) Error() string {
+	return fmt.Sprintf("failed to write shell library to %s: %v", e.Path, e.Err)
+}
+
+func (e *WriteLibraryError) Unwrap() error {
+	return e.Err
+}
+
+type UnsupportedCatalogVersionError struct {
+	Version int
+}
+
+func (e *UnsupportedCatalogVersionError) Error() string {
+	return fmt.Sprintf("unsupported catalog version %d, expected version %d", e.Version, models.CatalogVersion)
+}
+
+type CatalogParseError struct {
+	Err    error
+	Format models.CatalogFormat
+}
+
+func (e *CatalogParseError) Error() string {
+	return fmt.Sprintf("invalid %s catalog: %v", e.Format, e.Err)
+}
+
+func (e *CatalogParseError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidNaviLineError is returned when a line of a navi cheat file cannot be parsed
+type InvalidNaviLineError struct {
+	Content string
+	Line    int
+}
+
+func (e *InvalidNaviLineError) Error() string {
+	return fmt.Sprintf("invalid navi line %d: %s", e.Line, e.Content)
+}
+
+type CheatSheetTemplateError struct {
+	Err  error
+	Path string
+}
+
+func (e *CheatSheetTemplateError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("invalid cheat sheet template: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid cheat sheet template %s: %v", e.Path, e.Err)
+}
+
+func (e *CheatSheetTemplateError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidCatalogCommandError is returned when a command of an imported catalog
+// cannot be stored, index is the position of the command in the catalog
+type InvalidCatalogCommandError struct {
+	Reason string
+	Index  int
+}
+
+func (e *InvalidCatalogCommandError) Error() string {
+	return fmt.Sprintf("invalid command at index %d of the catalog: %s", e.Index, e.Reason)
+}
+
+// GitCommandError is returned when a git command run by the sync fails
+type GitCommandError struct {
+	Err    error
+	Output string
+	Args   []string
+}
+
+func (e *GitCommandError) Error() string {
+	return fmt.Sprintf("git %s failed: %v: %s", strings.Join(e.Args, " "), e.Err, e.Output)
+}
+
+func (e *GitCommandError) Unwrap() error {
+	return e.Err
+}
+
+// SyncFileError is returned when a command file of the sync repository cannot be read
+type SyncFileError struct {
+	Err  error
+	Path string
+}
+
+func (e *SyncFileError) Error() string {
+	return fmt.Sprintf("invalid sync file %s: %v", e.Path, e.Err)
+}
+
+func (e *SyncFileError) Unwrap() error {
+	return e.Err
+}
+
+// SyncConflictsError is returned when conflicts cannot be resolved without asking the user
+type SyncConflictsError struct {
+	Count int
+}
+
+func (e *SyncConflictsError) Error() string {
+	return fmt.Sprintf("%d command(s) modified locally and on the remote, "+
+		"use --strategy to choose the version to keep", e.Count)
+}
+
+// TeamCatalogError is returned when a team catalog cannot be read, path is
+// the database or the file of the catalog directory in error
+type TeamCatalogError struct {
+	Err  error
+	Name string
+	Path string
+}
+
+func (e *TeamCatalogError) Error() string {
+	return fmt.Sprintf("unable to read team catalog %s (%s): %v", e.Name, e.Path, e.Err)
+}
+
+func (e *TeamCatalogError) Unwrap() error {
+	return e.Err
+}
@@ -2,22 +2,73 @@ package executors
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"log/slog"
 	"os/exec"
 	"strings"
+	"syscall"
+	"time"
 )
 
+// timeoutWaitDelay is the time left to the processes started by a timed out
+// command to release its output
+const timeoutWaitDelay = 100 * time.Millisecond
+
 type DefaultCommandExecutor struct{}
 
 func (*DefaultCommandExecutor) ExecuteCommandWithStdin(cmd string, args []string, stdin string) (
 	output string, errOutput string, err error,
 ) {
-	command := exec.Command(cmd, args...)
+	return runCommand(exec.Command(cmd, args...), stdin)
+}
+
+// ExecuteCommandWithTimeout executes a command with stdin, the command is
+// killed if it is still running after the timeout
+func (*DefaultCommandExecutor) ExecuteCommandWithTimeout(
+	timeout time.Duration, cmd string, args []string, stdin string,
+) (output string, errOutput string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	command := exec.CommandContext(ctx, cmd, args...)
+	// the command runs in its own process group so that the timeout stops the
+	// processes it has started too, a pipeline for example
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	command.Cancel = func() error {
+		return killProcessGroup(command.Process.Pid, syscall.SIGKILL)
+	}
+	command.WaitDelay = timeoutWaitDelay
+	output, errOutput, err = runCommand(command, stdin)
+	if ctx.Err() != nil {
+		if command.Process != nil {
+			// the processes started in background are still running
+			if killErr := killProcessGroup(command.Process.Pid, syscall.SIGKILL); killErr != nil {
+				slog.Debug("Failed to kill the processes of the command", "error", killErr)
+			}
+		}
+		err = ctx.Err()
+	}
+	return output, errOutput, err
+}
+
+// killProcessGroup sends the signal to the process group led by the process
+func killProcessGroup(pid int, signal syscall.Signal) error {
+	err := syscall.Kill(-pid, signal)
+	if errors.Is(err, syscall.ESRCH) {
+		// the processes are already finished
+		return nil
+	}
+	return err
+}
+
+func runCommand(command *exec.Cmd, stdin string) (
+	output string, errOutput string, err error,
+) {
 	command.Stdin = strings.NewReader(stdin)
 
 	slog.Debug(
 		"Executing command",
-		"command", cmd+" "+strings.Join(args, " "),
+		"command", strings.Join(command.Args, " "),
 		"stdin", stdin,
 	)
 	var stdout, stderr bytes.Buffer
@@ -0,0 +1,38 @@
+package executors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCommandExecutor_ExecuteCommandWithTimeout(t *testing.T) {
+	executor := &DefaultCommandExecutor{}
+
+	t.Run("Returns the output", func(t *testing.T) {
+		output, _, err := executor.ExecuteCommandWithTimeout(time.Second, "sh", []string{"-c", "cat; echo done"}, "in\n")
+		require.NoError(t, err)
+		assert.Equal(t, "in\ndone\n", output)
+	})
+
+	t.Run("Stops the pipeline after the timeout", func(t *testing.T) {
+		start := time.Now()
+		_, _, err := executor.ExecuteCommandWithTimeout(
+			500*time.Millisecond, "sh", []string{"-c", "sleep 4 | cat"}, "",
+		)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("Stops the processes started in background", func(t *testing.T) {
+		start := time.Now()
+		_, _, err := executor.ExecuteCommandWithTimeout(
+			500*time.Millisecond, "sh", []string{"-c", "sleep 4 & wait"}, "",
+		)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+}
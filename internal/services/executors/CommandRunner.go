@@ -0,0 +1,284 @@
+package executors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+)
+
+const (
+	// maxRunOutputLines is the number of output lines kept by a run, the oldest lines are dropped
+	maxRunOutputLines = 10000
+	// runWaitDelay is the time left to the processes started by a canceled run to release its output
+	runWaitDelay = time.Second
+)
+
+// CommandRun is the execution of a script by a shell, its output is
+// captured while the script is running
+type CommandRun struct {
+	startTime time.Time
+	endTime   time.Time
+	err       error
+	cancel    context.CancelFunc
+	// updates receives a value when the output or the status changes
+	updates chan struct{}
+	// done is closed when the run is finished
+	done         chan struct{}
+	Shell        string
+	Script       string
+	output       []models.OutputLine
+	mu           sync.Mutex
+	ID           resource.ID
+	CommandID    resource.ID
+	exitCode     int
+	droppedLines int
+	status       task.Status
+	canceled     bool
+}
+
+// CommandRunner starts the command runs and keeps them until the end of the application
+type CommandRunner struct {
+	runs map[resource.ID]*CommandRun
+	mu   sync.Mutex
+}
+
+func NewCommandRunner() *CommandRunner {
+	return &CommandRunner{
+		runs: make(map[resource.ID]*CommandRun),
+		mu:   sync.Mutex{},
+	}
+}
+
+// Start runs the script using `shell -c script`, the run is returned
+// immediately while the script is running in background
+func (r *CommandRunner) Start(id resource.ID, commandID resource.ID, shell string, script string) *CommandRun {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	run := &CommandRun{
+		startTime:    time.Now(),
+		endTime:      time.Time{},
+		err:          nil,
+		cancel:       cancel,
+		updates:      make(chan struct{}, 1),
+		done:         make(chan struct{}),
+		Shell:        shell,
+		Script:       script,
+		output:       []models.OutputLine{},
+		mu:           sync.Mutex{},
+		ID:           id,
+		CommandID:    commandID,
+		exitCode:     -1,
+		droppedLines: 0,
+		status:       task.Running,
+		canceled:     false,
+	}
+	r.runs[run.ID] = run
+	r.mu.Unlock()
+
+	slog.Info("Running command", "run", run.ID, "command", commandID, "shell", shell)
+	go run.execute(ctx)
+	return run
+}
+
+// Get returns the run having the given id
+func (r *CommandRunner) Get(id resource.ID) (*CommandRun, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[id]
+	return run, ok
+}
+
+// CancelAll cancels the running commands and waits for their end
+func (r *CommandRunner) CancelAll() {
+	r.mu.Lock()
+	runs := make([]*CommandRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		runs = append(runs, run)
+	}
+	r.mu.Unlock()
+	for _, run := range runs {
+		run.Cancel()
+		run.Wait()
+	}
+}
+
+func (run *CommandRun) execute(ctx context.Context) {
+	defer run.cancel()
+	cmd := exec.CommandContext(ctx, run.Shell, "-c", run.Script)
+	// the script runs in its own process group so that a cancellation stops
+	// the processes it has started too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = runWaitDelay
+	stdout := &lineWriter{run: run, stream: models.OutputStreamStdout, buffer: bytes.Buffer{}}
+	stderr := &lineWriter{run: run, stream: models.OutputStreamStderr, buffer: bytes.Buffer{}}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+
+	run.mu.Lock()
+	if run.canceled && cmd.Process != nil {
+		// the processes ignoring the termination signal are still running
+		if killErr := killProcessGroup(cmd.Process.Pid, syscall.SIGKILL); killErr != nil {
+			slog.Debug("Failed to kill the processes of the run", "run", run.ID, "error", killErr)
+		}
+	}
+	run.endTime = time.Now()
+	if cmd.ProcessState != nil {
+		run.exitCode = cmd.ProcessState.ExitCode()
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case run.canceled:
+		run.status = task.Canceled
+	case err != nil && !errors.As(err, &exitErr):
+		run.status = task.Errored
+		run.err = err
+	case run.exitCode != 0:
+		run.status = task.Errored
+	default:
+		run.status = task.Exited
+	}
+	slog.Info("Command run finished",
+		"run", run.ID, "status", run.status, "exitCode", run.exitCode,
+		"elapsed", run.endTime.Sub(run.startTime), "error", err,
+	)
+	run.mu.Unlock()
+	close(run.done)
+}
+
+// appendLine adds an output line and notifies the update
+func (run *CommandRun) appendLine(stream models.OutputStream, text string) {
+	run.mu.Lock()
+	run.output = append(run.output, models.OutputLine{Stream: stream, Text: text})
+	if len(run.output) > maxRunOutputLines {
+		run.droppedLines += len(run.output) - maxRunOutputLines
+		run.output = slices.Clone(run.output[len(run.output)-maxRunOutputLines:])
+	}
+	run.mu.Unlock()
+	select {
+	case run.updates <- struct{}{}:
+	default:
+		// an update is already pending
+	}
+}
+
+// Cancel kills the running command, it has no effect if the run is finished
+func (run *CommandRun) Cancel() {
+	run.mu.Lock()
+	if !run.status.IsFinal() {
+		run.canceled = true
+	}
+	run.mu.Unlock()
+	run.cancel()
+}
+
+// WaitForUpdate blocks until the output or the status of the run changes,
+// it returns false if the run is finished
+func (run *CommandRun) WaitForUpdate() bool {
+	select {
+	case <-run.updates:
+		return true
+	case <-run.done:
+		return false
+	}
+}
+
+// Wait blocks until the run is finished
+func (run *CommandRun) Wait() {
+	<-run.done
+}
+
+func (run *CommandRun) GetStatus() task.Status {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.status
+}
+
+// GetExitCode returns the exit code of the command, -1 if the command
+// is running or has been killed
+func (run *CommandRun) GetExitCode() int {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.exitCode
+}
+
+// GetError returns the error preventing the command to run
+func (run *CommandRun) GetError() error {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.err
+}
+
+func (run *CommandRun) GetStartTime() time.Time {
+	return run.startTime
+}
+
+// GetEndTime returns the end of the run, zero while it is running
+func (run *CommandRun) GetEndTime() time.Time {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.endTime
+}
+
+// GetElapsed returns the duration of the run, up to now if it is running
+func (run *CommandRun) GetElapsed() time.Duration {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.endTime.IsZero() {
+		return time.Since(run.startTime)
+	}
+	return run.endTime.Sub(run.startTime)
+}
+
+// GetOutput returns a copy of the output lines and the number of
+// oldest lines dropped
+func (run *CommandRun) GetOutput() (lines []models.OutputLine, droppedLines int) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return slices.Clone(run.output), run.droppedLines
+}
+
+// lineWriter splits the written bytes into output lines of the run
+type lineWriter struct {
+	run    *CommandRun
+	stream models.OutputStream
+	buffer bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buffer.Write(p)
+	for {
+		line, err := w.buffer.ReadString('\n')
+		if err != nil {
+			// incomplete line, kept for the next write
+			w.buffer.Reset()
+			w.buffer.WriteString(line)
+			return len(p), nil
+		}
+		w.run.appendLine(w.stream, line[:len(line)-1])
+	}
+}
+
+// flush adds the last line if it does not end with a new line
+func (w *lineWriter) flush() {
+	if w.buffer.Len() > 0 {
+		w.run.appendLine(w.stream, w.buffer.String())
+		w.buffer.Reset()
+	}
+}
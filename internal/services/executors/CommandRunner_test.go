@@ -0,0 +1,83 @@
+package executors
+
+import (
+	"errors"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRunner(t *testing.T) {
+	t.Run("Captures the output and the exit code", func(t *testing.T) {
+		runner := NewCommandRunner()
+		run := runner.Start(1, 42, "sh", "echo out1; echo err1 >&2; printf out2; exit 3")
+		run.Wait()
+
+		assert.Equal(t, task.Errored, run.GetStatus())
+		assert.Equal(t, 3, run.GetExitCode())
+		require.NoError(t, run.GetError())
+		lines, dropped := run.GetOutput()
+		assert.Equal(t, 0, dropped)
+		assert.ElementsMatch(t, []models.OutputLine{
+			{Stream: models.OutputStreamStdout, Text: "out1"},
+			{Stream: models.OutputStreamStderr, Text: "err1"},
+			{Stream: models.OutputStreamStdout, Text: "out2"},
+		}, lines)
+
+		found, ok := runner.Get(run.ID)
+		assert.True(t, ok)
+		assert.Same(t, run, found)
+		assert.Equal(t, 42, int(found.CommandID))
+	})
+
+	t.Run("Successful run", func(t *testing.T) {
+		run := NewCommandRunner().Start(1, 1, "sh", "true")
+		run.Wait()
+		assert.Equal(t, task.Exited, run.GetStatus())
+		assert.Equal(t, 0, run.GetExitCode())
+		assert.False(t, run.WaitForUpdate())
+	})
+
+	t.Run("Unknown shell", func(t *testing.T) {
+		run := NewCommandRunner().Start(1, 1, "/unknown/shell", "true")
+		run.Wait()
+		assert.Equal(t, task.Errored, run.GetStatus())
+		require.Error(t, run.GetError())
+	})
+
+	t.Run("Streams and cancels", func(t *testing.T) {
+		runner := NewCommandRunner()
+		run := runner.Start(1, 1, "sh", "echo started; sleep 10")
+		assert.True(t, run.WaitForUpdate())
+		lines, _ := run.GetOutput()
+		assert.Equal(t, []models.OutputLine{{Stream: models.OutputStreamStdout, Text: "started"}}, lines)
+		assert.Equal(t, task.Running, run.GetStatus())
+
+		start := time.Now()
+		runner.CancelAll()
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, task.Canceled, run.GetStatus())
+	})
+
+	t.Run("Cancels the processes started by the script", func(t *testing.T) {
+		runner := NewCommandRunner()
+		run := runner.Start(1, 1, "sh", "trap '' TERM; sleep 10 & echo $!; wait")
+		assert.True(t, run.WaitForUpdate())
+		lines, _ := run.GetOutput()
+		require.Len(t, lines, 1)
+		pid, err := strconv.Atoi(lines[0].Text)
+		require.NoError(t, err)
+
+		runner.CancelAll()
+		assert.Equal(t, task.Canceled, run.GetStatus())
+		assert.Eventually(t, func() bool {
+			return errors.Is(syscall.Kill(pid, 0), syscall.ESRCH)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
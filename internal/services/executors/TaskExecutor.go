@@ -1 +1,157 @@
 package executors
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+)
+
+// taskItem is a unit of work waiting in the TaskExecutor queue
+type taskItem struct {
+	run  func() error
+	name string
+}
+
+// TaskExecutor runs tasks in background, at most maxTasks at the same time.
+// Tasks are run in the order they have been submitted.
+type TaskExecutor struct {
+	cond     *sync.Cond
+	counts   map[task.Status]int
+	queue    []taskItem
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	maxTasks int
+	started  bool
+	stopped  bool
+}
+
+// NewTaskExecutor creates a task executor running at most maxTasks tasks
+// concurrently, maxTasks lower than 1 is replaced by 1
+func NewTaskExecutor(maxTasks int) *TaskExecutor {
+	executor := &TaskExecutor{
+		cond:     nil,
+		counts:   make(map[task.Status]int),
+		queue:    []taskItem{},
+		wg:       sync.WaitGroup{},
+		mu:       sync.Mutex{},
+		maxTasks: max(1, maxTasks),
+		started:  false,
+		stopped:  false,
+	}
+	executor.cond = sync.NewCond(&executor.mu)
+	return executor
+}
+
+// Start launches the workers, calling it several times has no effect
+func (e *TaskExecutor) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.started || e.stopped {
+		return
+	}
+	e.started = true
+	for range e.maxTasks {
+		e.wg.Add(1)
+		go e.worker()
+	}
+	slog.Debug("Task executor started", "maxTasks", e.maxTasks)
+}
+
+// Submit queues a task, the returned error of run is only used to count
+// errored tasks, so run is expected to log its own errors.
+// Tasks submitted after Stop are counted as canceled.
+func (e *TaskExecutor) Submit(name string, run func() error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		slog.Warn("Task submitted after executor stop", "task", name)
+		e.counts[task.Canceled]++
+		return
+	}
+	e.queue = append(e.queue, taskItem{run: run, name: name})
+	e.counts[task.Queued]++
+	e.cond.Broadcast()
+}
+
+// GetCounts returns the number of tasks for each status
+func (e *TaskExecutor) GetCounts() map[task.Status]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	counts := make(map[task.Status]int, len(e.counts))
+	for status, count := range e.counts {
+		counts[status] = count
+	}
+	return counts
+}
+
+// Wait blocks until all the submitted tasks are finished
+func (e *TaskExecutor) Wait() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for e.counts[task.Queued] > 0 || e.counts[task.Running] > 0 {
+		e.cond.Wait()
+	}
+}
+
+// Stop cancels the queued tasks and waits for the running ones to finish
+func (e *TaskExecutor) Stop() {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return
+	}
+	e.stopped = true
+	e.counts[task.Canceled] += len(e.queue)
+	e.counts[task.Queued] = 0
+	e.queue = nil
+	e.cond.Broadcast()
+	e.mu.Unlock()
+
+	e.wg.Wait()
+	slog.Debug("Task executor stopped")
+}
+
+func (e *TaskExecutor) worker() {
+	defer e.wg.Done()
+	for {
+		item, ok := e.next()
+		if !ok {
+			return
+		}
+		err := item.run()
+		if err != nil {
+			slog.Error("Task failed", "task", item.name, "error", err)
+		}
+		e.finish(err)
+	}
+}
+
+// next waits for a queued task, ok is false if the executor has been stopped
+func (e *TaskExecutor) next() (item taskItem, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for len(e.queue) == 0 && !e.stopped {
+		e.cond.Wait()
+	}
+	if e.stopped {
+		return taskItem{run: nil, name: ""}, false
+	}
+	item = e.queue[0]
+	e.queue = e.queue[1:]
+	e.counts[task.Queued]--
+	e.counts[task.Running]++
+	return item, true
+}
+
+func (e *TaskExecutor) finish(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counts[task.Running]--
+	if err != nil {
+		e.counts[task.Errored]++
+	} else {
+		e.counts[task.Exited]++
+	}
+	e.cond.Broadcast()
+}
@@ -0,0 +1,75 @@
+package executors
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTask = errors.New("task error")
+
+func TestTaskExecutor(t *testing.T) {
+	t.Run("Runs all the tasks", func(t *testing.T) {
+		executor := NewTaskExecutor(3)
+		executor.Start()
+		defer executor.Stop()
+
+		var done atomic.Int32
+		for i := range 10 {
+			executor.Submit("task", func() error {
+				done.Add(1)
+				if i%5 == 0 {
+					return errTask
+				}
+				return nil
+			})
+		}
+		executor.Wait()
+
+		assert.Equal(t, int32(10), done.Load())
+		counts := executor.GetCounts()
+		assert.Equal(t, 8, counts[task.Exited])
+		assert.Equal(t, 2, counts[task.Errored])
+		assert.Equal(t, 0, counts[task.Queued])
+		assert.Equal(t, 0, counts[task.Running])
+	})
+
+	t.Run("Never exceeds max tasks", func(t *testing.T) {
+		executor := NewTaskExecutor(2)
+		executor.Start()
+		defer executor.Stop()
+
+		var running, maxRunning atomic.Int32
+		for range 20 {
+			executor.Submit("task", func() error {
+				current := running.Add(1)
+				for {
+					previous := maxRunning.Load()
+					if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				running.Add(-1)
+				return nil
+			})
+		}
+		executor.Wait()
+		assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("Queued tasks canceled on stop", func(t *testing.T) {
+		executor := NewTaskExecutor(0)
+		executor.Submit("task", func() error { return nil })
+		executor.Submit("task", func() error { return nil })
+		assert.Equal(t, 2, executor.GetCounts()[task.Queued])
+		executor.Stop()
+		executor.Submit("task", func() error { return nil })
+
+		counts := executor.GetCounts()
+		assert.Equal(t, 0, counts[task.Queued])
+		assert.Equal(t, 3, counts[task.Canceled])
+	})
+}
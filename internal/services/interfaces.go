@@ -1,8 +1,11 @@
 package services
 
 import (
+	"time"
+
 	"github.com/fchastanet/shell-command-bookmarker/internal/args"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 )
 
 type CommandExecutorInterface interface {
@@ -10,12 +13,80 @@ type CommandExecutorInterface interface {
 	ExecuteCommandWithStdin(cmd string, args []string, stdin string) (
 		output string, errOutput string, err error,
 	)
+	// ExecuteCommandWithTimeout executes a command with stdin, the command is killed after the timeout.
+	ExecuteCommandWithTimeout(timeout time.Duration, cmd string, args []string, stdin string) (
+		output string, errOutput string, err error,
+	)
 }
 
 type LookupExecutorInterface interface {
 	LookPath(path string) (string, error)
 }
 
+type LintCacheInterface interface {
+	// GetLintCache retrieves the lint result stored for the given cache key
+	GetLintCache(cacheKey string) (lintStatus models.LintStatus, lintIssues string, found bool, err error)
+	// SaveLintCache stores the lint result for the given cache key
+	SaveLintCache(cacheKey string, lintStatus models.LintStatus, lintIssues string) error
+}
+
+// Linter analyzes a script and reports issues in the shellcheck JSON format
+type Linter interface {
+	// GetName returns the name of the linter (see Linter* constants)
+	GetName() string
+	// IsAvailable returns false if the linter cannot be used, eg: its executable is missing
+	IsAvailable() bool
+	// IsDialectSupported returns true if the linter is able to lint the given shell dialect
+	IsDialectSupported(dialect models.ShellDialect) bool
+	// GetSignature identifies the version and the options of the linter in the lint cache key,
+	// an empty signature disables the cache
+	GetSignature(dialect models.ShellDialect, rules models.LintRules) string
+	// Lint returns the issues found in the script
+	Lint(script string, dialect models.ShellDialect, rules models.LintRules) ([]ShellCheckIssue, error)
+}
+
+type PlaceholderStoreInterface interface {
+	// GetPlaceholderValues returns the values recently used for the placeholder, the most recent first
+	GetPlaceholderValues(name string, limit int) ([]string, error)
+	// SavePlaceholderValue stores a value used for the placeholder, keeping the keep most recent values
+	SavePlaceholderValue(name string, value string, keep int) error
+}
+
+type ExecutionStoreInterface interface {
+	// SaveExecution stores a new execution and sets its id
+	SaveExecution(execution *models.Execution) error
+	// UpdateExecution stores the result of a finished execution
+	UpdateExecution(execution *models.Execution) error
+	// GetExecutionByID returns the execution having the given id, nil if not found
+	GetExecutionByID(id resource.ID) (*models.Execution, error)
+	// GetExecutions returns the limit most recent executions, the most recent first
+	GetExecutions(limit int) ([]*models.Execution, error)
+}
+
+type CatalogStoreInterface interface {
+	// GetCommands returns the commands having one of the given statuses, all the commands if none
+	GetCommands(statuses ...models.CommandStatus) ([]*models.Command, error)
+	// GetCommandByScript returns a command having the given script, nil if not found
+	GetCommandByScript(script string) (*models.Command, error)
+	// GetCommandByUUID returns the command having the given uuid, nil if not found
+	GetCommandByUUID(uuid resource.UUID) (*models.Command, error)
+	// SaveCommand stores a new command and sets its id
+	SaveCommand(command *models.Command) error
+	// ReplaceCommand updates all the fields of an existing command
+	ReplaceCommand(command *models.Command) error
+	// SetCommandTags replaces the tags of the command
+	SetCommandTags(commandID resource.ID, tags []string) error
+	// SetCommandFolder moves the command to the folder having the given path
+	SetCommandFolder(commandID resource.ID, path string) error
+	// RunInTransaction calls fn with a store whose changes are kept only if fn succeeds
+	RunInTransaction(fn func(store CatalogStoreInterface) error) error
+}
+
+type TaskExecutorInterface interface {
+	// Submit queues a task to be run in background
+	Submit(name string, run func() error)
+}
+
 // AppServiceInterface defines the expected behavior of an AppService
 type AppServiceInterface interface {
 	Main(cli *args.Cli, sqliteSchema string) error
@@ -25,6 +96,7 @@ type AppServiceInterface interface {
 	Cleanup()
 	GetHistoryService() *HistoryService
 	HandleShellIntegrationScriptGeneration(cli *args.Cli) bool
+	HandleCliCommand(cli *args.Cli, sqliteSchema string) (handled bool, err error)
 	Self() *AppService
 }
 
@@ -38,6 +110,6 @@ type HistoryServiceInterface interface {
 	GetAllCommandCategories() []CommandCategory
 	IngestHistory() error
 	UpdateCommand(command *models.Command) (*models.Command, error)
-	ComposeCommand(commands []*models.Command) (*models.Command, error)
+	ComposeCommand(composition *models.Composition) (*models.Command, error)
 	CreateCommandsString(commands []*models.Command) string
 }
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// CatalogVersion is the version of the catalog format written by the export
+const CatalogVersion = 1
+
+// CatalogFormat is the serialization format of an exported catalog
+type CatalogFormat string
+
+const (
+	CatalogFormatJSON CatalogFormat = "json"
+	CatalogFormatYAML CatalogFormat = "yaml"
+	// CatalogFormatMarkdown is a human-readable cheat sheet, it cannot be imported
+	CatalogFormatMarkdown CatalogFormat = "markdown"
+	// CatalogFormatNavi is the cheat file format of navi
+	CatalogFormatNavi CatalogFormat = "navi"
+	// CatalogFormatPet is the snippet.toml format of pet
+	CatalogFormatPet CatalogFormat = "pet"
+)
+
+// MergeStrategy tells how an imported command matching an existing command is merged
+type MergeStrategy string
+
+const (
+	// MergeStrategySkip keeps the existing command unchanged
+	MergeStrategySkip MergeStrategy = "skip"
+	// MergeStrategyOverwrite replaces the existing command with the imported one
+	MergeStrategyOverwrite MergeStrategy = "overwrite"
+	// MergeStrategyKeepBoth adds the imported command next to the existing one
+	MergeStrategyKeepBoth MergeStrategy = "keep-both"
+)
+
+// Catalog is the exported form of the commands, documented in the README,
+// the fields are in the order of the exported files
+type Catalog struct {
+	Version    int               `json:"version"    yaml:"version"`
+	ExportedAt time.Time         `json:"exportedAt" yaml:"exportedAt"`
+	Commands   []*CatalogCommand `json:"commands"   yaml:"commands"`
+}
+
+// CatalogCommand is the exported form of a command, without its local id,
+// the uuid allows to match the command on import
+type CatalogCommand struct {
+	UUID               resource.UUID `json:"uuid,omitempty"               yaml:"uuid,omitempty"`
+	ContentHash        string        `json:"contentHash,omitempty"        yaml:"contentHash,omitempty"`
+	Title              string        `json:"title"                        yaml:"title"`
+	Description        string        `json:"description,omitempty"        yaml:"description,omitempty"`
+	Script             string        `json:"script"                       yaml:"script"`
+	Shell              ShellDialect  `json:"shell"                        yaml:"shell"`
+	Status             CommandStatus `json:"status"                       yaml:"status"`
+	Folder             string        `json:"folder,omitempty"             yaml:"folder,omitempty"`
+	Tags               []string      `json:"tags,omitempty"               yaml:"tags,omitempty"`
+	PlaceholderSources string        `json:"placeholderSources,omitempty" yaml:"placeholderSources,omitempty"`
+	ShortName          string        `json:"shortName,omitempty"          yaml:"shortName,omitempty"`
+	Elapsed            int           `json:"elapsed,omitempty"            yaml:"elapsed,omitempty"`
+	Created            time.Time     `json:"created"                      yaml:"created"`
+	Modified           time.Time     `json:"modified"                     yaml:"modified"`
+	Lint               *CatalogLint  `json:"lint,omitempty"               yaml:"lint,omitempty"`
+}
+
+// CatalogLint is the lint result of an exported command
+type CatalogLint struct {
+	Status LintStatus       `json:"status"           yaml:"status"`
+	Rules  *LintRules       `json:"rules,omitempty"  yaml:"rules,omitempty"`
+	Issues []map[string]any `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+// CatalogFilter selects the commands of a catalog
+type CatalogFilter struct {
+	// Folder keeps the commands of the folder and of its sub folders, all if empty
+	Folder string
+	// Tags keeps the commands having at least one of the tags, all if empty
+	Tags []string
+}
+
+// Matches returns true if the command is selected by the filter
+func (f *CatalogFilter) Matches(cmd *CatalogCommand) bool {
+	return f.matches(cmd.Folder, cmd.Tags)
+}
+
+// MatchesCommand returns true if the command of the database is selected by the filter
+func (f *CatalogFilter) MatchesCommand(cmd *Command) bool {
+	return f.matches(cmd.Folder, cmd.Tags)
+}
+
+func (f *CatalogFilter) matches(commandFolder string, commandTags []string) bool {
+	if len(f.Tags) > 0 && !slices.ContainsFunc(commandTags, func(tag string) bool {
+		return slices.Contains(f.Tags, tag)
+	}) {
+		return false
+	}
+	folder := strings.Trim(f.Folder, "/")
+	return folder == "" || commandFolder == folder || strings.HasPrefix(commandFolder, folder+"/")
+}
+
+// Filter removes the commands of the catalog not selected by the filter
+func (c *Catalog) Filter(filter *CatalogFilter) {
+	c.Commands = slices.DeleteFunc(c.Commands, func(cmd *CatalogCommand) bool {
+		return !filter.Matches(cmd)
+	})
+}
+
+// GetCommandStatuses returns all the statuses of the commands
+func GetCommandStatuses() []CommandStatus {
+	return []CommandStatus{
+		CommandStatusImported,
+		CommandStatusSaved,
+		CommandStatusDeleted,
+		CommandStatusObsolete,
+	}
+}
+
+// GetLintStatuses returns all the lint statuses of the commands
+func GetLintStatuses() []LintStatus {
+	return []LintStatus{
+		LintStatusNotAvailable,
+		LintStatusOK,
+		LintStatusWarning,
+		LintStatusError,
+		LintStatusShellcheckFailed,
+	}
+}
+
+// NewCatalogCommand converts the command to its exported form
+func NewCatalogCommand(cmd *Command) *CatalogCommand {
+	catalogCommand := &CatalogCommand{
+		UUID:               cmd.UUID,
+		ContentHash:        cmd.ContentHash,
+		Title:              cmd.Title,
+		Description:        cmd.Description,
+		Script:             cmd.Script,
+		Shell:              cmd.Shell,
+		Status:             cmd.Status,
+		Folder:             cmd.Folder,
+		Tags:               slices.Clone(cmd.Tags),
+		PlaceholderSources: cmd.PlaceholderSources,
+		ShortName:          cmd.ShortName,
+		Elapsed:            cmd.Elapsed,
+		Created:            cmd.CreationDatetime,
+		Modified:           cmd.ModificationDatetime,
+		Lint:               nil,
+	}
+	if cmd.LintStatus != LintStatusNotAvailable {
+		catalogCommand.Lint = &CatalogLint{
+			Status: cmd.LintStatus,
+			Rules:  nil,
+			Issues: cmd.GetLintIssues(),
+		}
+		if rules, err := ParseLintRules(cmd.LintRules); err == nil && cmd.LintRules != "" {
+			catalogCommand.Lint.Rules = &rules
+		}
+	}
+	return catalogCommand
+}
+
+// ToCommand converts the exported command to a command without id, a new
+// uuid is generated if the exported command has none, the content hash is
+// computed again when the command is stored
+func (c *CatalogCommand) ToCommand() *Command {
+	cmd := NewCommand(c.Script, c.Elapsed, c.Created)
+	if c.UUID != "" {
+		cmd.UUID = c.UUID
+	}
+	cmd.ModificationDatetime = c.Modified
+	cmd.Title = c.Title
+	cmd.Description = c.Description
+	cmd.Status = c.Status
+	cmd.Folder = c.Folder
+	cmd.PlaceholderSources = c.PlaceholderSources
+	cmd.ShortName = c.ShortName
+	if c.Shell != "" {
+		cmd.Shell = c.Shell
+	}
+	if c.Tags != nil {
+		cmd.Tags = slices.Clone(c.Tags)
+	}
+	if c.Lint != nil {
+		cmd.LintStatus = c.Lint.Status
+		if c.Lint.Issues != nil {
+			issues, err := json.Marshal(c.Lint.Issues)
+			if err != nil {
+				slog.Error("Error formatting imported lint issues as JSON", "error", err)
+			} else {
+				cmd.LintIssues = string(issues)
+			}
+		}
+		if c.Lint.Rules != nil {
+			cmd.LintRules = c.Lint.Rules.ToJSON()
+		}
+	}
+	return cmd
+}
@@ -1,8 +1,13 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
@@ -26,10 +31,31 @@ type Command struct {
 	Status               CommandStatus
 	LintIssues           string
 	LintStatus           LintStatus
-	lintIssuesParsed     []map[string]any
-	ID                   resource.ID
-	Elapsed              int
-	FilterScore          int
+	// LintRules is the JSON encoded LintRules used to compute LintIssues
+	LintRules string
+	Shell     ShellDialect
+	// PlaceholderSources is the YAML declaration of the suggested values of the placeholders
+	PlaceholderSources string
+	// ShortName is the name of the alias or function generated for the command
+	// in the shell library, empty if the command is not part of the library
+	ShortName string
+	// Folder is the path of the folder of the command, eg: ops/db, empty if none
+	Folder string
+	// UUID identifies the command on every machine, it is kept when the command
+	// is edited and shared by its obsolete copies
+	UUID resource.UUID
+	// ContentHash is the hash of the content of the command, see GetContentHash
+	ContentHash string
+	// Tags is the list of tag titles of the command
+	Tags []string
+	// Source is the name of the read-only team catalog the command comes from,
+	// empty for the commands of the database
+	Source           string
+	lintIssuesParsed []map[string]any
+	lintIssuesSource string
+	ID               resource.ID
+	Elapsed          int
+	FilterScore      int
 }
 
 type LintStatus string
@@ -42,6 +68,85 @@ const (
 	LintStatusShellcheckFailed LintStatus = "SHELLCHECK_FAILED"
 )
 
+// ShellDialect is the shell language a command script is written for
+type ShellDialect string
+
+const (
+	ShellDialectBash ShellDialect = "bash"
+	ShellDialectZsh  ShellDialect = "zsh"
+	ShellDialectSh   ShellDialect = "sh"
+	ShellDialectDash ShellDialect = "dash"
+	ShellDialectKsh  ShellDialect = "ksh"
+
+	// DefaultShellDialect is used when the dialect cannot be detected
+	DefaultShellDialect = ShellDialectBash
+)
+
+// GetShellDialects returns all the supported shell dialects
+func GetShellDialects() []ShellDialect {
+	return []ShellDialect{
+		ShellDialectBash,
+		ShellDialectZsh,
+		ShellDialectSh,
+		ShellDialectDash,
+		ShellDialectKsh,
+	}
+}
+
+// ParseShellDialect converts a shell name or path (eg: /usr/bin/zsh) to a
+// supported dialect, ok is false if the shell is not supported.
+func ParseShellDialect(shell string) (dialect ShellDialect, ok bool) {
+	name := filepath.Base(strings.TrimSpace(shell))
+	for _, d := range GetShellDialects() {
+		if name == string(d) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// DetectShellDialectFromShebang returns the dialect declared by the shebang
+// of the script, or fallback if the script has no shebang or an unsupported one.
+// Both "#!/bin/zsh" and "#!/usr/bin/env zsh" forms are supported.
+func DetectShellDialectFromShebang(script string, fallback ShellDialect) ShellDialect {
+	firstLine, _, _ := strings.Cut(script, "\n")
+	if !strings.HasPrefix(firstLine, "#!") {
+		return fallback
+	}
+	fields := strings.Fields(strings.TrimPrefix(firstLine, "#!"))
+	if len(fields) == 0 {
+		return fallback
+	}
+	interpreter := fields[0]
+	if filepath.Base(interpreter) == "env" {
+		// skip env options like -S
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+	if dialect, ok := ParseShellDialect(interpreter); ok {
+		return dialect
+	}
+	return fallback
+}
+
+// IsCompatibleWith returns true if a script written in this dialect can be
+// run by the given shell. POSIX sh/dash scripts are accepted by every shell.
+func (d ShellDialect) IsCompatibleWith(shell ShellDialect) bool {
+	switch d {
+	case shell, ShellDialectSh, ShellDialectDash:
+		return true
+	case ShellDialectBash, ShellDialectZsh, ShellDialectKsh:
+		return false
+	default:
+		return false
+	}
+}
+
 func NewCommand(
 	script string,
 	elapsed int,
@@ -55,24 +160,38 @@ func NewCommand(
 		Elapsed:              elapsed,
 		LintIssues:           "[]",
 		lintIssuesParsed:     nil,
+		lintIssuesSource:     "",
 		LintStatus:           LintStatusNotAvailable,
+		LintRules:            "",
+		PlaceholderSources:   "",
+		ShortName:            "",
+		Folder:               "",
+		UUID:                 resource.NewUUID(),
+		ContentHash:          "",
+		Tags:                 []string{},
+		Source:               "",
 		Status:               CommandStatusImported,
+		Shell:                DetectShellDialectFromShebang(script, DefaultShellDialect),
 		CreationDatetime:     timestamp,
 		ModificationDatetime: time.Now(),
 		FilterScore:          0,
 	}
 }
 
+// IsEditable returns true if the command can be modified, the commands of the
+// team catalogs are read-only
 func (c *Command) IsEditable() bool {
-	return c.Status == CommandStatusImported ||
-		c.Status == CommandStatusSaved
+	return c.Source == "" && (c.Status == CommandStatusImported ||
+		c.Status == CommandStatusSaved)
 }
 
-// getLintIssues parses the JSON lint issues and returns them as structured data
+// GetLintIssues parses the JSON lint issues and returns them as structured data
+// the parsed issues are cached until LintIssues is changed (eg: after a new lint)
 func (c *Command) GetLintIssues() []map[string]any {
-	if c.lintIssuesParsed != nil {
+	if c.lintIssuesParsed != nil && c.lintIssuesSource == c.LintIssues {
 		return c.lintIssuesParsed
 	}
+	c.lintIssuesSource = c.LintIssues
 	if c.LintIssues == "" || c.LintIssues == "[]" {
 		c.lintIssuesParsed = []map[string]any{}
 		return c.lintIssuesParsed
@@ -82,6 +201,7 @@ func (c *Command) GetLintIssues() []map[string]any {
 	err := json.Unmarshal([]byte(c.LintIssues), &issues)
 	if err != nil {
 		slog.Error("Error parsing lint issues", "error", err)
+		c.lintIssuesParsed = nil
 		return []map[string]any{}
 	}
 	c.lintIssuesParsed = issues
@@ -89,6 +209,23 @@ func (c *Command) GetLintIssues() []map[string]any {
 	return issues
 }
 
+// GetContentHash returns the hash of the title, the description, the script,
+// the shell, the placeholder sources and the short name of the command,
+// allowing to detect if two copies of a command differ
+func (c *Command) GetContentHash() string {
+	hash := sha256.New()
+	for _, field := range []string{c.Title, c.Description, c.Script, string(c.Shell), c.PlaceholderSources} {
+		// the length prefix avoids collisions between fields
+		fmt.Fprintf(hash, "%d:%s\n", len(field), field)
+	}
+	// the short name was added later, the hash of the commands without
+	// short name is unchanged
+	if c.ShortName != "" {
+		fmt.Fprintf(hash, "%d:%s\n", len(c.ShortName), c.ShortName)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 func (c *Command) GetID() resource.ID {
 	return c.ID
 }
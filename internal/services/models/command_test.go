@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectShellDialectFromShebang(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		fallback ShellDialect
+		want     ShellDialect
+	}{
+		{"No shebang", "ls -al", ShellDialectZsh, ShellDialectZsh},
+		{"Bin path", "#!/bin/sh\necho 'hello'", ShellDialectBash, ShellDialectSh},
+		{"Env", "#!/usr/bin/env zsh\nprint -l *", ShellDialectBash, ShellDialectZsh},
+		{"Env with option", "#!/usr/bin/env -S ksh -e\necho 'hello'", ShellDialectBash, ShellDialectKsh},
+		{"Unsupported interpreter", "#!/usr/bin/env python3\nprint('hello')", ShellDialectBash, ShellDialectBash},
+		{"Empty shebang", "#!\necho 'hello'", ShellDialectDash, ShellDialectDash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectShellDialectFromShebang(tt.script, tt.fallback))
+		})
+	}
+}
+
+func TestShellDialect_IsCompatibleWith(t *testing.T) {
+	assert.True(t, ShellDialectBash.IsCompatibleWith(ShellDialectBash))
+	assert.True(t, ShellDialectSh.IsCompatibleWith(ShellDialectZsh))
+	assert.True(t, ShellDialectDash.IsCompatibleWith(ShellDialectBash))
+	assert.False(t, ShellDialectZsh.IsCompatibleWith(ShellDialectBash))
+	assert.False(t, ShellDialectBash.IsCompatibleWith(ShellDialectZsh))
+}
+
+func TestParseShellDialect(t *testing.T) {
+	dialect, ok := ParseShellDialect("/usr/bin/zsh")
+	assert.True(t, ok)
+	assert.Equal(t, ShellDialectZsh, dialect)
+
+	_, ok = ParseShellDialect("fish")
+	assert.False(t, ok)
+}
+
+func TestCommand_GetContentHash(t *testing.T) {
+	cmd := NewCommand("echo hello", 0, time.Now())
+	cmd.Title = "Greet"
+	hash := cmd.GetContentHash()
+	assert.Len(t, hash, 64)
+
+	copied := NewCommand("echo hello", 1, time.Now().Add(time.Hour))
+	copied.Title = "Greet"
+	copied.Status = CommandStatusObsolete
+	assert.Equal(t, hash, copied.GetContentHash(), "only the content is hashed")
+	assert.NotEqual(t, cmd.UUID, copied.UUID)
+
+	copied.Title = "Gree"
+	copied.Description = "t"
+	assert.NotEqual(t, hash, copied.GetContentHash(), "fields are not concatenated")
+
+	copied.Title = "Greet"
+	copied.Description = ""
+	copied.ShortName = "greet"
+	assert.NotEqual(t, hash, copied.GetContentHash(), "the short name is hashed")
+}
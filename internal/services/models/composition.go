@@ -0,0 +1,86 @@
+package models
+
+// ErrorHandling is the way a composed script reacts to a failing step
+type ErrorHandling string
+
+const (
+	// ErrorHandlingNone runs the next steps even if a step fails
+	ErrorHandlingNone ErrorHandling = "none"
+	// ErrorHandlingExit stops the script at the first failing command
+	ErrorHandlingExit ErrorHandling = "exit"
+	// ErrorHandlingReport stops the script at the first failing command
+	// and reports the failing step on stderr
+	ErrorHandlingReport ErrorHandling = "report"
+)
+
+// GetErrorHandlings returns the error handlings in the order they are proposed
+func GetErrorHandlings() []ErrorHandling {
+	return []ErrorHandling{ErrorHandlingExit, ErrorHandlingReport, ErrorHandlingNone}
+}
+
+// CompositionStep is a command included in a composed script
+type CompositionStep struct {
+	Command *Command
+	// Comment is written above the step, the command title is used if empty
+	Comment string
+	// AsFunction wraps the script of the step into a function
+	AsFunction bool
+}
+
+// Composition describes a script made of the scripts of several commands
+type Composition struct {
+	Title         string
+	ErrorHandling ErrorHandling
+	Steps         []*CompositionStep
+}
+
+// NewComposition creates a composition running the commands in the given
+// order, stopping at the first failing command
+func NewComposition(commands []*Command) *Composition {
+	steps := make([]*CompositionStep, 0, len(commands))
+	for _, cmd := range commands {
+		steps = append(steps, &CompositionStep{Command: cmd, Comment: "", AsFunction: false})
+	}
+	return &Composition{
+		Title:         "",
+		ErrorHandling: ErrorHandlingExit,
+		Steps:         steps,
+	}
+}
+
+// GetShell returns the dialect of the commands if they all share the same
+// one, the default dialect otherwise
+func (c *Composition) GetShell() ShellDialect {
+	if len(c.Steps) == 0 {
+		return DefaultShellDialect
+	}
+	shell := c.Steps[0].Command.Shell
+	for _, step := range c.Steps[1:] {
+		if step.Command.Shell != shell {
+			return DefaultShellDialect
+		}
+	}
+	if shell == "" {
+		return DefaultShellDialect
+	}
+	return shell
+}
+
+// SwapSteps swaps the steps at the given indexes, used to move a step up or
+// down, false is returned if one of the indexes is out of range
+func (c *Composition) SwapSteps(from, to int) bool {
+	if from < 0 || from >= len(c.Steps) || to < 0 || to >= len(c.Steps) {
+		return false
+	}
+	c.Steps[from], c.Steps[to] = c.Steps[to], c.Steps[from]
+	return true
+}
+
+// RemoveStep removes the step at the given index
+func (c *Composition) RemoveStep(index int) bool {
+	if index < 0 || index >= len(c.Steps) {
+		return false
+	}
+	c.Steps = append(c.Steps[:index], c.Steps[index+1:]...)
+	return true
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+)
+
+// OutputStream identifies the stream an output line has been written to
+type OutputStream string
+
+const (
+	OutputStreamStdout OutputStream = "stdout"
+	OutputStreamStderr OutputStream = "stderr"
+)
+
+// OutputLine is a line written by a command execution
+type OutputLine struct {
+	Stream OutputStream `json:"stream"`
+	Text   string       `json:"text"`
+}
+
+// Execution is a run of a command script, it is stored when the run
+// starts and updated when the run is finished
+type Execution struct {
+	StartDatetime time.Time
+	// EndDatetime is zero while the command is running
+	EndDatetime time.Time
+	// Environment summarizes the environment the script has been run in
+	Environment map[string]string
+	Shell       string
+	Script      string
+	Cwd         string
+	Status      task.Status
+	// Error is the error preventing the script to run
+	Error  string
+	Output []OutputLine
+	// DroppedLines is the number of oldest output lines not kept
+	DroppedLines int
+	ID           resource.ID
+	CommandID    resource.ID
+	// ExitCode is -1 while the command is running or if it has been killed
+	ExitCode int
+}
+
+func (e *Execution) GetID() resource.ID {
+	return e.ID
+}
+
+// GetElapsed returns the duration of the execution, up to now if it is running
+func (e *Execution) GetElapsed() time.Duration {
+	if e.EndDatetime.IsZero() {
+		return time.Since(e.StartDatetime)
+	}
+	return e.EndDatetime.Sub(e.StartDatetime)
+}
+
+// GetOutputText returns the output lines joined by new lines
+func (e *Execution) GetOutputText() string {
+	texts := make([]string, 0, len(e.Output))
+	for _, line := range e.Output {
+		texts = append(texts, line.Text)
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// LintLevel is the severity of a shellcheck issue
+type LintLevel string
+
+const (
+	LintLevelStyle   LintLevel = "style"
+	LintLevelInfo    LintLevel = "info"
+	LintLevelWarning LintLevel = "warning"
+	LintLevelError   LintLevel = "error"
+)
+
+// GetLintLevels returns the lint levels from the least to the most severe
+func GetLintLevels() []LintLevel {
+	return []LintLevel{LintLevelStyle, LintLevelInfo, LintLevelWarning, LintLevelError}
+}
+
+// rank returns the position of the level in GetLintLevels,
+// unknown levels are considered as warnings
+func (l LintLevel) rank() int {
+	index := slices.Index(GetLintLevels(), l)
+	if index < 0 {
+		return slices.Index(GetLintLevels(), LintLevelWarning)
+	}
+	return index
+}
+
+// IsAtLeast returns true if the level is as severe as the other level or more
+func (l LintLevel) IsAtLeast(other LintLevel) bool {
+	return l.rank() >= other.rank()
+}
+
+// IsValid returns true if the level is one of the shellcheck levels
+func (l LintLevel) IsValid() bool {
+	return slices.Contains(GetLintLevels(), l)
+}
+
+// LintRules is the set of shellcheck rules used to lint a command
+type LintRules struct {
+	// Severity is the minimum severity of the reported issues
+	Severity LintLevel `json:"severity,omitempty" yaml:"severity"`
+	// WarningLevel is the minimum level of an issue for the command to be in warning
+	WarningLevel LintLevel `json:"warningLevel,omitempty" yaml:"warningLevel"`
+	// ErrorLevel is the minimum level of an issue for the command to be in error
+	ErrorLevel LintLevel `json:"errorLevel,omitempty" yaml:"errorLevel"`
+	// Exclude is the list of excluded codes (eg: SC2086)
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude"`
+	// Enable is the list of optional checks enabled (eg: require-variable-braces)
+	Enable []string `json:"enable,omitempty" yaml:"enable"`
+	// Sources explains where the rules come from (eg: .shellcheckrc, config, tag:legacy)
+	Sources []string `json:"sources,omitempty" yaml:"-"`
+}
+
+// GetDefaultLintRules returns the rules used when nothing is configured
+func GetDefaultLintRules() LintRules {
+	return LintRules{
+		Severity:     LintLevelStyle,
+		WarningLevel: LintLevelWarning,
+		ErrorLevel:   LintLevelError,
+		Exclude:      []string{},
+		Enable:       []string{},
+		Sources:      []string{},
+	}
+}
+
+// NormalizeLintCode converts a code like 2086 or sc2086 to SC2086,
+// other codes (eg: custom rule names) are kept as is
+func NormalizeLintCode(code string) string {
+	code = strings.TrimSpace(code)
+	digits := strings.TrimPrefix(strings.ToUpper(code), "SC")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return code
+	}
+	return "SC" + digits
+}
+
+// IsShellcheckCode returns true if the normalized code is a shellcheck code
+func IsShellcheckCode(code string) bool {
+	digits, found := strings.CutPrefix(code, "SC")
+	return found && digits != "" && strings.Trim(digits, "0123456789") == ""
+}
+
+// Merge returns the rules overridden by the other rules, excluded codes
+// and enabled checks are added, levels are replaced if they are set
+func (r LintRules) Merge(other LintRules, source string) LintRules {
+	merged := LintRules{
+		Severity:     r.Severity,
+		WarningLevel: r.WarningLevel,
+		ErrorLevel:   r.ErrorLevel,
+		Exclude:      slices.Clone(r.Exclude),
+		Enable:       slices.Clone(r.Enable),
+		Sources:      slices.Clone(r.Sources),
+	}
+	if other.Severity != "" {
+		merged.Severity = other.Severity
+	}
+	if other.WarningLevel != "" {
+		merged.WarningLevel = other.WarningLevel
+	}
+	if other.ErrorLevel != "" {
+		merged.ErrorLevel = other.ErrorLevel
+	}
+	for _, code := range other.Exclude {
+		code = NormalizeLintCode(code)
+		if code != "" && !slices.Contains(merged.Exclude, code) {
+			merged.Exclude = append(merged.Exclude, code)
+		}
+	}
+	for _, check := range other.Enable {
+		check = strings.TrimSpace(check)
+		if check != "" && !slices.Contains(merged.Enable, check) {
+			merged.Enable = append(merged.Enable, check)
+		}
+	}
+	merged.Sources = append(merged.Sources, source)
+	return merged
+}
+
+// Validate checks that the levels are valid shellcheck levels
+func (r LintRules) Validate() error {
+	for _, level := range []LintLevel{r.Severity, r.WarningLevel, r.ErrorLevel} {
+		if level != "" && !level.IsValid() {
+			return &InvalidLintLevelError{Level: string(level)}
+		}
+	}
+	return nil
+}
+
+// GetStatus returns the lint status resulting from the given issue levels,
+// default levels are used if the thresholds are not set
+func (r LintRules) GetStatus(levels []LintLevel) LintStatus {
+	warningLevel := cmp.Or(r.WarningLevel, LintLevelWarning)
+	errorLevel := cmp.Or(r.ErrorLevel, LintLevelError)
+	status := LintStatusOK
+	for _, level := range levels {
+		if level.rank() >= errorLevel.rank() {
+			return LintStatusError
+		}
+		if level.rank() >= warningLevel.rank() {
+			status = LintStatusWarning
+		}
+	}
+	return status
+}
+
+// Describe returns a human readable summary of the rules
+func (r LintRules) Describe() string {
+	parts := []string{
+		fmt.Sprintf("severity >= %s", r.Severity),
+		fmt.Sprintf("warning >= %s", r.WarningLevel),
+		fmt.Sprintf("error >= %s", r.ErrorLevel),
+	}
+	if len(r.Exclude) > 0 {
+		parts = append(parts, "excluded: "+strings.Join(r.Exclude, ","))
+	}
+	if len(r.Enable) > 0 {
+		parts = append(parts, "enabled: "+strings.Join(r.Enable, ","))
+	}
+	if len(r.Sources) > 0 {
+		parts = append(parts, "from: "+strings.Join(r.Sources, ", "))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// ToJSON returns the rules in the format stored with the lint result
+func (r LintRules) ToJSON() string {
+	str, err := json.Marshal(r)
+	if err != nil {
+		slog.Error("Error formatting lint rules as JSON", "error", err)
+		return ""
+	}
+	return string(str)
+}
+
+// ParseLintRules parses the rules stored with the lint result
+func ParseLintRules(rules string) (LintRules, error) {
+	parsed := LintRules{
+		Severity:     "",
+		WarningLevel: "",
+		ErrorLevel:   "",
+		Exclude:      []string{},
+		Enable:       []string{},
+		Sources:      []string{},
+	}
+	if rules == "" {
+		return parsed, nil
+	}
+	err := json.Unmarshal([]byte(rules), &parsed)
+	return parsed, err
+}
+
+// InvalidLintLevelError is returned when a configured level is not a shellcheck level
+type InvalidLintLevelError struct {
+	Level string
+}
+
+func (e *InvalidLintLevelError) Error() string {
+	return fmt.Sprintf("invalid lint level %q, expected one of: style, info, warning, error", e.Level)
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLintCode(t *testing.T) {
+	assert.Equal(t, "SC2086", NormalizeLintCode("2086"))
+	assert.Equal(t, "SC2086", NormalizeLintCode(" sc2086 "))
+	assert.Equal(t, "SC2086", NormalizeLintCode("SC2086"))
+	assert.Equal(t, "", NormalizeLintCode(""))
+	assert.Equal(t, "no-sudo-rm-rf", NormalizeLintCode("no-sudo-rm-rf"))
+	assert.Equal(t, "sc", NormalizeLintCode("sc"))
+	assert.True(t, IsShellcheckCode("SC2086"))
+	assert.False(t, IsShellcheckCode("format"))
+}
+
+func TestLintRules_Merge(t *testing.T) {
+	base := GetDefaultLintRules()
+	merged := base.Merge(LintRules{
+		Severity:     LintLevelInfo,
+		WarningLevel: "",
+		ErrorLevel:   "",
+		Exclude:      []string{"2086", "SC2086", ""},
+		Enable:       []string{"require-variable-braces"},
+		Sources:      []string{},
+	}, "config")
+
+	assert.Equal(t, LintLevelInfo, merged.Severity)
+	assert.Equal(t, LintLevelWarning, merged.WarningLevel)
+	assert.Equal(t, LintLevelError, merged.ErrorLevel)
+	assert.Equal(t, []string{"SC2086"}, merged.Exclude)
+	assert.Equal(t, []string{"require-variable-braces"}, merged.Enable)
+	assert.Equal(t, []string{"config"}, merged.Sources)
+	assert.Empty(t, base.Sources, "base rules must not be modified")
+}
+
+func TestLintRules_GetStatus(t *testing.T) {
+	strict := GetDefaultLintRules()
+	strict.WarningLevel = LintLevelStyle
+	strict.ErrorLevel = LintLevelWarning
+
+	tests := []struct {
+		name   string
+		rules  LintRules
+		levels []LintLevel
+		want   LintStatus
+	}{
+		{"No issue", GetDefaultLintRules(), []LintLevel{}, LintStatusOK},
+		{"Info only", GetDefaultLintRules(), []LintLevel{LintLevelInfo, LintLevelStyle}, LintStatusOK},
+		{"Warning", GetDefaultLintRules(), []LintLevel{LintLevelInfo, LintLevelWarning}, LintStatusWarning},
+		{"Error", GetDefaultLintRules(), []LintLevel{LintLevelWarning, LintLevelError}, LintStatusError},
+		{"Strict style", strict, []LintLevel{LintLevelStyle}, LintStatusWarning},
+		{"Strict warning", strict, []LintLevel{LintLevelWarning}, LintStatusError},
+		{"Unset thresholds", LintRules{}, []LintLevel{LintLevelWarning}, LintStatusWarning}, //nolint:exhaustruct //test
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rules.GetStatus(tt.levels))
+		})
+	}
+}
+
+func TestLintRules_JSON(t *testing.T) {
+	rules := GetDefaultLintRules().Merge(LintRules{
+		Severity:     "",
+		WarningLevel: "",
+		ErrorLevel:   "",
+		Exclude:      []string{"SC2086"},
+		Enable:       []string{},
+		Sources:      []string{},
+	}, "tag:legacy")
+
+	parsed, err := ParseLintRules(rules.ToJSON())
+	assert.NoError(t, err)
+	assert.Equal(t, rules, parsed)
+	assert.Equal(t,
+		"severity >= style • warning >= warning • error >= error • excluded: SC2086 • from: tag:legacy",
+		parsed.Describe(),
+	)
+	assert.Error(t, LintRules{Severity: "fatal"}.Validate()) //nolint:exhaustruct //test
+}
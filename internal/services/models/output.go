@@ -0,0 +1,48 @@
+package models
+
+import (
+	"slices"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// OutputFormat is the format of the commands written by the command line and
+// of the command written to the output file when it is selected for the shell
+type OutputFormat string
+
+const (
+	// OutputFormatPlain is readable by a human, the script alone for a command
+	// selected for the shell
+	OutputFormatPlain OutputFormat = "plain"
+	// OutputFormatJSON writes the records of the commands as JSON
+	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatTSV writes a header line then one record by line, the
+	// fields being separated by tabs
+	OutputFormatTSV OutputFormat = "tsv"
+)
+
+// CommandRecord is the machine-readable form of a command
+type CommandRecord struct {
+	ID         resource.ID  `json:"id"`
+	Title      string       `json:"title"`
+	Script     string       `json:"script"`
+	Shell      ShellDialect `json:"shell"`
+	Tags       []string     `json:"tags"`
+	LintStatus LintStatus   `json:"lintStatus"`
+}
+
+// NewCommandRecord returns the record of the command
+func NewCommandRecord(cmd *Command) *CommandRecord {
+	tags := []string{}
+	if cmd.Tags != nil {
+		tags = slices.Clone(cmd.Tags)
+	}
+	return &CommandRecord{
+		ID:         cmd.ID,
+		Title:      cmd.Title,
+		Script:     cmd.Script,
+		Shell:      cmd.Shell,
+		Tags:       tags,
+		LintStatus: cmd.LintStatus,
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrAmbiguousPlaceholderSource is returned when a placeholder declares both choices and a command
+var ErrAmbiguousPlaceholderSource = errors.New("choices and command cannot be both set")
+
+// placeholderRegexp matches the {{name}} and <name> placeholders of a script
+//
+//nolint:gochecknoglobals // compiled once
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w-]*)\s*\}\}|<([A-Za-z_][\w-]*)>`)
+
+// getPlaceholderName returns the name captured by placeholderRegexp
+func getPlaceholderName(submatches []string) string {
+	if submatches[1] != "" {
+		return submatches[1]
+	}
+	return submatches[2]
+}
+
+// GetPlaceholders returns the names of the placeholders of the script,
+// in the order of their first occurrence, eg: kubectl logs -n {{namespace}} <pod>
+func GetPlaceholders(script string) []string {
+	names := []string{}
+	for _, submatches := range placeholderRegexp.FindAllStringSubmatch(script, -1) {
+		name := getPlaceholderName(submatches)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FillPlaceholders replaces the placeholders of the script by the given values,
+// the placeholders without value are kept as is
+func FillPlaceholders(script string, values map[string]string) string {
+	return placeholderRegexp.ReplaceAllStringFunc(script, func(placeholder string) string {
+		name := getPlaceholderName(placeholderRegexp.FindStringSubmatch(placeholder))
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// MaskPlaceholders replaces each placeholder of the script by a word of the
+// same length so that the script can be parsed as shell, eg: <pod> becomes
+// _pod_, the lines and the columns are kept. placeholders gives the
+// placeholder replaced by each word.
+func MaskPlaceholders(script string) (masked string, placeholders map[string]string) {
+	placeholders = map[string]string{}
+	masked = placeholderRegexp.ReplaceAllStringFunc(script, func(placeholder string) string {
+		word := strings.Map(func(r rune) rune {
+			if r == '-' || r == '_' || ('0' <= r && r <= '9') || ('A' <= r && r <= 'Z') || ('a' <= r && r <= 'z') {
+				return r
+			}
+			return '_'
+		}, placeholder)
+		placeholders[word] = placeholder
+		return word
+	})
+	return masked, placeholders
+}
+
+// UnmaskPlaceholders restores in the text the placeholders masked by MaskPlaceholders
+func UnmaskPlaceholders(text string, placeholders map[string]string) string {
+	if len(placeholders) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(placeholders))
+	for word, placeholder := range placeholders {
+		pairs = append(pairs, word, placeholder)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// GetPlaceholders returns the names of the placeholders of the command script
+func (c *Command) GetPlaceholders() []string {
+	return GetPlaceholders(c.Script)
+}
+
+// PlaceholderSource declares where the suggested values of a placeholder come from
+type PlaceholderSource struct {
+	// Default is the value proposed first
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	// Choices is a static list of values
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	// Command is a shell command whose output lines are the values,
+	// eg: git branch --format=%(refname:short)
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+// PlaceholderSources are the sources of the placeholders of a command by placeholder name
+type PlaceholderSources map[string]PlaceholderSource
+
+// ParsePlaceholderSources parses the YAML declaration of the placeholder sources, eg:
+//
+//	branch:
+//	  command: git branch --format=%(refname:short)
+//	env:
+//	  choices: [dev, staging, prod]
+//	  default: dev
+func ParsePlaceholderSources(sources string) (PlaceholderSources, error) {
+	parsed := PlaceholderSources{}
+	if sources == "" {
+		return parsed, nil
+	}
+	if err := yaml.Unmarshal([]byte(sources), &parsed); err != nil {
+		return parsed, &InvalidPlaceholderSourcesError{Name: "", Err: err}
+	}
+	for name, source := range parsed {
+		if len(source.Choices) > 0 && source.Command != "" {
+			return parsed, &InvalidPlaceholderSourcesError{Name: name, Err: ErrAmbiguousPlaceholderSource}
+		}
+	}
+	return parsed, nil
+}
+
+// GetPlaceholderSource returns the source declared for the placeholder of the command
+func (c *Command) GetPlaceholderSource(name string) (PlaceholderSource, bool) {
+	sources, err := ParsePlaceholderSources(c.PlaceholderSources)
+	if err != nil {
+		return PlaceholderSource{Default: "", Choices: nil, Command: ""}, false
+	}
+	source, ok := sources[name]
+	return source, ok
+}
+
+// InvalidPlaceholderSourcesError is returned when the placeholder sources cannot be parsed
+type InvalidPlaceholderSourcesError struct {
+	Err  error
+	Name string
+}
+
+func (e *InvalidPlaceholderSourcesError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("invalid placeholder sources: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid source of placeholder %q: %v", e.Name, e.Err)
+}
+
+func (e *InvalidPlaceholderSourcesError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"No placeholder", "ls -al", []string{}},
+		{"Both syntaxes", "kubectl logs -n {{namespace}} <pod>", []string{"namespace", "pod"}},
+		{"Duplicates and spaces", "echo {{ name }} <name> {{other-name}}", []string{"name", "other-name"}},
+		{"Redirections ignored", "cat <input.txt >output.txt; diff <(ls) <(ls -a)", []string{}},
+		{"Invalid names ignored", "echo {{1abc}} <a b>", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetPlaceholders(tt.script))
+		})
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	script := "kubectl logs -n {{namespace}} <pod> {{ namespace }} <container>"
+	assert.Equal(t,
+		"kubectl logs -n kube-system coredns kube-system <container>",
+		FillPlaceholders(script, map[string]string{"namespace": "kube-system", "pod": "coredns"}),
+	)
+}
+
+func TestMaskPlaceholders(t *testing.T) {
+	script := "kubectl logs -n {{ namespace }} <pod> > <pod>.log"
+	masked, placeholders := MaskPlaceholders(script)
+	assert.Equal(t, "kubectl logs -n ___namespace___ _pod_ > _pod_.log", masked)
+	assert.Len(t, masked, len(script))
+	assert.Equal(t, script, UnmaskPlaceholders(masked, placeholders))
+
+	masked, placeholders = MaskPlaceholders("ls -al")
+	assert.Equal(t, "ls -al", masked)
+	assert.Equal(t, "ls -al", UnmaskPlaceholders(masked, placeholders))
+}
+
+func TestParsePlaceholderSources(t *testing.T) {
+	sources, err := ParsePlaceholderSources("env:\n  choices: [dev, prod]\n  default: dev\nbranch:\n  command: git branch\n")
+	require.NoError(t, err)
+	assert.Equal(t, PlaceholderSources{
+		"env":    {Default: "dev", Choices: []string{"dev", "prod"}, Command: ""},
+		"branch": {Default: "", Choices: nil, Command: "git branch"},
+	}, sources)
+
+	_, err = ParsePlaceholderSources("env:\n  choices: [dev]\n  command: ls\n")
+	require.ErrorIs(t, err, ErrAmbiguousPlaceholderSource)
+
+	_, err = ParsePlaceholderSources("env: [")
+	var sourcesErr *InvalidPlaceholderSourcesError
+	require.ErrorAs(t, err, &sourcesErr)
+}
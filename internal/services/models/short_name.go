@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// ShortNameMaxLength is the maximum length of the short name of a command
+const ShortNameMaxLength = 30
+
+// shortNameRegexp matches the names usable both as alias and as function name
+//
+//nolint:gochecknoglobals // compiled once
+var shortNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// getShellReservedWords returns the words that cannot be used as function
+// name in bash or zsh
+func getShellReservedWords() []string {
+	return []string{
+		"case", "coproc", "do", "done", "elif", "else", "esac", "fi", "for", "foreach",
+		"function", "if", "in", "repeat", "select", "then", "time", "until", "while",
+	}
+}
+
+// ValidateShortName checks that the short name of a command can be used as
+// alias and function name, an empty short name is valid
+func ValidateShortName(name string) error {
+	switch {
+	case name == "":
+		return nil
+	case len(name) > ShortNameMaxLength:
+		return &InvalidShortNameError{
+			Name:   name,
+			Reason: fmt.Sprintf("it is longer than %d characters", ShortNameMaxLength),
+		}
+	case !shortNameRegexp.MatchString(name):
+		return &InvalidShortNameError{
+			Name:   name,
+			Reason: "only letters, digits, _ and - are allowed, and it cannot start with a digit or -",
+		}
+	case slices.Contains(getShellReservedWords(), name):
+		return &InvalidShortNameError{Name: name, Reason: "it is a shell reserved word"}
+	}
+	return nil
+}
+
+// InvalidShortNameError is returned when a short name cannot be used as
+// alias or function name
+type InvalidShortNameError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidShortNameError) Error() string {
+	return fmt.Sprintf("invalid short name %q: %s", e.Name, e.Reason)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateShortName(t *testing.T) {
+	for _, name := range []string{"", "gs", "k8s_logs", "git-undo", "_private"} {
+		assert.NoError(t, ValidateShortName(name), name)
+	}
+	for _, name := range []string{"2fa", "-x", "git status", "a;b", "if", strings.Repeat("a", ShortNameMaxLength+1)} {
+		var shortNameErr *InvalidShortNameError
+		assert.ErrorAs(t, ValidateShortName(name), &shortNameErr, name)
+	}
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 
@@ -17,9 +18,10 @@ const (
 
 // SQLiteAdapter represents a connection to a SQLite database
 type SQLiteAdapter struct {
-	db     *sql.DB
-	path   string
-	schema string
+	db       *sql.DB
+	path     string
+	schema   string
+	readOnly bool
 }
 
 type Driver interface {
@@ -39,14 +41,29 @@ type Adapter interface {
 // NewSQLiteAdapter creates a new SQLite adapter
 func NewSQLiteAdapter(dbPath, schema string) Adapter {
 	return &SQLiteAdapter{
-		db:     nil,
-		path:   dbPath,
-		schema: schema,
+		db:       nil,
+		path:     dbPath,
+		schema:   schema,
+		readOnly: false,
+	}
+}
+
+// NewReadOnlySQLiteAdapter creates an adapter opening an existing database
+// in read-only mode, eg: a database shared by a team
+func NewReadOnlySQLiteAdapter(dbPath string) Adapter {
+	return &SQLiteAdapter{
+		db:       nil,
+		path:     dbPath,
+		schema:   "",
+		readOnly: true,
 	}
 }
 
 // Open opens the database connection and initializes the schema if needed
 func (a *SQLiteAdapter) Open() error {
+	if a.readOnly {
+		return a.openReadOnly()
+	}
 	// Create the directory if it doesn't exist
 	dbDir := filepath.Dir(a.path)
 	if err := os.MkdirAll(dbDir, DirectoryPerm); err != nil {
@@ -60,7 +77,8 @@ func (a *SQLiteAdapter) Open() error {
 	isNew := !fileExists(a.path)
 
 	// Open the database connection with foreign keys and FTS5 enabled
-	db, err := sql.Open("sqlite3", a.path+"?_foreign_keys=on&_sqlite_fts5=1")
+	// busy timeout allows background tasks to write concurrently
+	db, err := sql.Open("sqlite3", a.path+"?_foreign_keys=on&_sqlite_fts5=1&_busy_timeout=5000")
 	if err != nil {
 		return &DatabaseNotFoundError{
 			DBFilePath: a.path,
@@ -93,6 +111,29 @@ func (a *SQLiteAdapter) Open() error {
 	return nil
 }
 
+// openReadOnly opens the existing database without creating or migrating it
+func (a *SQLiteAdapter) openReadOnly() error {
+	if !fileExists(a.path) {
+		return &DatabaseNotFoundError{
+			DBFilePath: a.path,
+		}
+	}
+	db, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: a.path}).EscapedPath()+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return &DatabaseNotFoundError{
+			DBFilePath: a.path,
+		}
+	}
+	a.db = db
+	if err := db.Ping(); err != nil {
+		return &DatabaseConnectionError{
+			DBFilePath: a.path,
+			InnerError: err,
+		}
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (a *SQLiteAdapter) Close() error {
 	if a.db != nil {
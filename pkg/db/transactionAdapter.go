@@ -0,0 +1,49 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrNestedTransaction is returned when a transaction is started inside a transaction
+var ErrNestedTransaction = errors.New("a transaction is already in progress")
+
+// TransactionAdapter runs the queries in a transaction started by another
+// adapter, the transaction is committed or rolled back by its owner
+type TransactionAdapter struct {
+	tx *sql.Tx
+}
+
+// NewTransactionAdapter creates an adapter running its queries in the transaction
+func NewTransactionAdapter(tx *sql.Tx) Adapter {
+	return &TransactionAdapter{tx: tx}
+}
+
+// Open has no effect, the connection is owned by the adapter of the transaction
+func (*TransactionAdapter) Open() error {
+	return nil
+}
+
+// Close has no effect, the connection is owned by the adapter of the transaction
+func (*TransactionAdapter) Close() error {
+	return nil
+}
+
+// GetDB returns the transaction
+func (a *TransactionAdapter) GetDB() Driver {
+	return &transactionDriver{Tx: a.tx}
+}
+
+// BeginTx fails as the transactions cannot be nested
+func (*TransactionAdapter) BeginTx() (*sql.Tx, error) {
+	return nil, ErrNestedTransaction
+}
+
+// transactionDriver adds Ping to the transaction to implement Driver
+type transactionDriver struct {
+	*sql.Tx
+}
+
+func (*transactionDriver) Ping() error {
+	return nil
+}
@@ -0,0 +1,113 @@
+// Package diff computes line based differences between two texts
+package diff
+
+import "strings"
+
+// Operation is the kind of change applied to a line
+type Operation int
+
+const (
+	// Equal means the line is present in both texts
+	Equal Operation = iota
+	// Delete means the line is only present in the first text
+	Delete
+	// Insert means the line is only present in the second text
+	Insert
+)
+
+// Line is a line of the diff with the operation applied to it
+type Line struct {
+	Text string
+	Op   Operation
+}
+
+// Prefix returns the unified diff prefix of the line operation
+func (o Operation) Prefix() string {
+	switch o {
+	case Delete:
+		return "- "
+	case Insert:
+		return "+ "
+	case Equal:
+		return "  "
+	default:
+		return "  "
+	}
+}
+
+// Lines returns the lines of before and after texts with the operation
+// needed to transform before into after, using the longest common subsequence.
+func Lines(before, after string) []Line {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Text: a[i], Op: Equal})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Text: a[i], Op: Delete})
+			i++
+		default:
+			lines = append(lines, Line{Text: b[j], Op: Insert})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Text: a[i], Op: Delete})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Text: b[j], Op: Insert})
+	}
+	return lines
+}
+
+// HasChanges returns true if at least one line has been inserted or deleted
+func HasChanges(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Unified renders the diff between before and after, each line being
+// prefixed by "- ", "+ " or "  ".
+func Unified(before, after string) string {
+	var sb strings.Builder
+	for i, line := range Lines(before, after) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(line.Op.Prefix())
+		sb.WriteString(line.Text)
+	}
+	return sb.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []Line
+	}{
+		{
+			name:   "Identical",
+			before: "a\nb",
+			after:  "a\nb",
+			want:   []Line{{Text: "a", Op: Equal}, {Text: "b", Op: Equal}},
+		},
+		{
+			name:   "Changed line",
+			before: "echo $var\nls",
+			after:  "echo \"$var\"\nls",
+			want: []Line{
+				{Text: "echo $var", Op: Delete},
+				{Text: "echo \"$var\"", Op: Insert},
+				{Text: "ls", Op: Equal},
+			},
+		},
+		{
+			name:   "Empty before",
+			before: "",
+			after:  "a",
+			want:   []Line{{Text: "a", Op: Insert}},
+		},
+		{
+			name:   "Removed line",
+			before: "a\nb\nc",
+			after:  "a\nc",
+			want:   []Line{{Text: "a", Op: Equal}, {Text: "b", Op: Delete}, {Text: "c", Op: Equal}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lines(tt.before, tt.after)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.before != tt.after, HasChanges(got))
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	assert.Equal(t, "  a\n- b\n+ c", Unified("a\nb", "a\nc"))
+}
@@ -0,0 +1,23 @@
+package resource
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// uuidSize is the number of random bytes of a UUID
+const uuidSize = 16
+
+// UUID globally identifies a Shell Command Bookmarker resource, unlike ID
+// which is only unique in the local database.
+type UUID string
+
+// NewUUID generates a random (version 4) UUID
+func NewUUID() UUID {
+	var bytes [uuidSize]byte
+	// rand.Read only fails if the random source of the system is unavailable
+	_, _ = rand.Read(bytes[:])
+	bytes[6] = (bytes[6] & 0x0f) | 0x40 // version 4
+	bytes[8] = (bytes[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return UUID(fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]))
+}
@@ -0,0 +1,15 @@
+package resource
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUUID(t *testing.T) {
+	uuidRegexp := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first := NewUUID()
+	assert.Regexp(t, uuidRegexp, string(first))
+	assert.NotEqual(t, first, NewUUID())
+}
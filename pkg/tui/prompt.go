@@ -7,6 +7,23 @@ import (
 	"github.com/charmbracelet/huh"
 )
 
+// Prompt is a widget displayed below the panes, receiving the messages
+// until it is completed.
+type Prompt interface {
+	Init() tea.Cmd
+	Update(msg tea.Msg) tea.Cmd
+	View() string
+	IsCompleted() bool
+	// CapturesText returns true if the prompt needs all the keys, even the
+	// ones bound to global actions like help.
+	CapturesText() bool
+}
+
+// PromptMsg enables a custom prompt widget.
+type PromptMsg struct {
+	Prompt Prompt
+}
+
 // YesNoPromptMsg enables the prompt widget.
 type YesNoPromptMsg struct {
 	form      *huh.Form
@@ -42,6 +59,10 @@ func (m YesNoPromptMsg) IsCompleted() bool {
 	return m.form.State != huh.StateNormal
 }
 
+func (m YesNoPromptMsg) CapturesText() bool {
+	return false
+}
+
 func (m YesNoPromptMsg) Init() tea.Cmd {
 	return m.form.Init()
 }